@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Manage archived (deleted) instances",
+	Long: `Manage instances kept in the archive after 'ocw delete'.
+
+Archived instances can be brought back with 'ocw restore <id>' until they
+are purged.`,
+}
+
+var archiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List archived instances",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		archived, err := mgr.ListArchive()
+		if err != nil {
+			return err
+		}
+
+		if len(archived) == 0 {
+			fmt.Println("No archived instances.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tBRANCH\tARCHIVED\tCOMMIT\tSTASH")
+		fmt.Fprintln(w, "--\t----\t------\t--------\t------\t-----")
+
+		for _, a := range archived {
+			commit := a.HeadSHA
+			if len(commit) > 8 {
+				commit = commit[:8]
+			}
+			stash := "no"
+			if a.StashRef != "" {
+				stash = "yes"
+			}
+			branch := a.Instance.Branch
+			if a.BranchDeleted {
+				branch += " (deleted)"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				a.Instance.ID,
+				a.Instance.Name,
+				branch,
+				formatTime(a.ArchivedAt),
+				commit,
+				stash,
+			)
+		}
+
+		w.Flush()
+		return nil
+	},
+}
+
+var archivePurgeCmd = &cobra.Command{
+	Use:   "purge [id...]",
+	Short: "Permanently remove archived instances",
+	Long: `Permanently remove archived instances and their backup refs.
+
+Without arguments, purges entries archived before --older-than, or every
+entry with --all. --older-than accepts a duration (e.g. 720h), a date
+(2006-01-02) or an RFC3339 timestamp.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		olderThanStr, _ := cmd.Flags().GetString("older-than")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if len(args) == 0 && !all && olderThanStr == "" {
+			return fmt.Errorf("nothing to purge\n\nTo fix:\n  1. Purge specific entries: ocw archive purge <id>...\n  2. Purge old entries: ocw archive purge --older-than 720h\n  3. Purge everything: ocw archive purge --all")
+		}
+		if len(args) > 0 && (all || olderThanStr != "") {
+			return fmt.Errorf("instance IDs cannot be combined with --all or --older-than")
+		}
+
+		var olderThan time.Time
+		if olderThanStr != "" {
+			t, err := parseTimeFlag(olderThanStr)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than value: %w", err)
+			}
+			olderThan = t
+		}
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		if !force {
+			fmt.Print("⚠ Purged instances cannot be restored. Continue? [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Purge cancelled.")
+				return nil
+			}
+		}
+
+		purged, err := mgr.PurgeArchive(args, olderThan)
+		if err != nil {
+			return fmt.Errorf("failed to purge archive: %w", err)
+		}
+
+		if len(purged) == 0 {
+			fmt.Println("No archived instances matched.")
+			return nil
+		}
+
+		for _, a := range purged {
+			fmt.Printf("✓ Purged %s (%s)\n", a.Instance.ID, a.Instance.Name)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	archivePurgeCmd.Flags().Bool("all", false, "Purge every archived instance")
+	archivePurgeCmd.Flags().String("older-than", "", "Purge entries archived before this time (duration, date or RFC3339)")
+	archivePurgeCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	archiveCmd.AddCommand(archiveListCmd)
+	archiveCmd.AddCommand(archivePurgeCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap <instance>",
+	Short: "Re-sync git-ignored files into an instance's worktree",
+	Long: `Bring the files listed in [workspace.bootstrap] from the main checkout
+into an instance's worktree again, e.g. after editing .env.
+
+New instances are bootstrapped when they are created. Each pattern is copied,
+symlinked or reflinked (cloned copy-on-write, falling back to a copy where the
+filesystem does not support it). Copies in the worktree are replaced; files
+tracked by git and files over the size limit are skipped.
+
+Example config:
+  [workspace.bootstrap]
+  max_size_kb = 10240
+  files = [
+    { pattern = ".env*" },
+    { pattern = "certs", mode = "reflink" },
+    { pattern = "node_modules", mode = "symlink" },
+  ]`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		// Find git repository root
+		repoRoot := cwd
+		for {
+			if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+				break
+			}
+			parent := filepath.Dir(repoRoot)
+			if parent == repoRoot {
+				return fmt.Errorf("not in a git repository")
+			}
+			repoRoot = parent
+		}
+
+		// Check if .ocw exists
+		ocwDir := filepath.Join(repoRoot, ".ocw")
+		if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+			return fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+		}
+
+		cfg, err := config.LoadConfig(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		mgr, err := workspace.NewManager(repoRoot, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace manager: %w", err)
+		}
+
+		instanceID, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		if len(cfg.Workspace.Bootstrap.Files) == 0 {
+			fmt.Println("No bootstrap files configured; add patterns to [workspace.bootstrap] files.")
+			return nil
+		}
+
+		report, err := mgr.Bootstrap(instanceID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Bootstrapped %s: %s\n", args[0], report.Summary())
+		if len(report.Entries) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, e := range report.Entries {
+			switch {
+			case e.Skipped != "":
+				fmt.Fprintf(w, "  skipped\t%s\t%s\n", e.Path, e.Skipped)
+			case e.Mode == config.BootstrapSymlink:
+				fmt.Fprintf(w, "  %s\t%s\t\n", e.Mode, e.Path)
+			default:
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", e.Mode, e.Path, formatSize(e.Size))
+			}
+		}
+		w.Flush()
+
+		return nil
+	},
+}
+
+// formatSize renders a byte count for display, e.g. "1.5 KB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGT"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+}
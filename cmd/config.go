@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/config"
 )
 
 var configCmd = &cobra.Command{
@@ -74,7 +80,284 @@ var configCmd = &cobra.Command{
 	},
 }
 
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration",
+	Long: `Show every effective config value after applying all layers, lowest precedence first:
+
+  default  built-in defaults
+  user     ~/.config/ocw/config.toml ($XDG_CONFIG_HOME/ocw/config.toml)
+  repo     .ocw/config.toml
+  local    .ocw/config.local.toml (git-ignored, personal overrides)
+  env      OCW_* environment variables, e.g. OCW_OPENCODE_MODEL
+  flag     --set key=value
+
+With --origin, the layer each value came from is shown as well.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showOrigin, _ := cmd.Flags().GetBool("origin")
+
+		// Outside a repository only the user, env and flag layers apply
+		repoRoot := findConfigRoot()
+
+		cfg, origins, err := config.LoadLayered(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, key := range config.Keys(cfg) {
+			value, err := config.Get(cfg, key)
+			if err != nil {
+				return err
+			}
+
+			if showOrigin {
+				fmt.Fprintf(w, "%s = %s\t# %s\n", key, value, origins[key])
+			} else {
+				fmt.Fprintf(w, "%s = %s\n", key, value)
+			}
+		}
+		w.Flush()
+
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for errors",
+	Long: `Load every config layer and report unknown keys, values of the wrong type and
+invalid values with the file and line they come from. Warnings, such as an
+opencode command that is not in PATH, are reported as well.
+
+Exits with an error if the configuration cannot be used.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Outside a repository only the user, env and flag layers apply
+		repoRoot := findConfigRoot()
+
+		var problems []config.Problem
+		cfg, origins, err := config.LoadLayered(repoRoot)
+		var validationErr *config.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			problems = validationErr.Problems
+		case err != nil:
+			return fmt.Errorf("failed to load config: %w", err)
+		default:
+			problems = config.Check(cfg, origins)
+		}
+
+		printProblems(os.Stdout, problems)
+
+		if config.HasErrors(problems) {
+			return fmt.Errorf("config is invalid")
+		}
+		if len(problems) > 0 {
+			fmt.Printf("✓ Config is valid (%d warning(s))\n", len(problems))
+		} else {
+			fmt.Println("✓ Config is valid")
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print an effective config value",
+	Long: `Print the effective value of a dotted config key after applying all layers.
+Strings are printed without quotes; lists and other values as TOML. A table
+such as tmux or workspace.templates.hotfix prints every value below it.
+
+Examples:
+  ocw config get opencode.model
+  ocw config get workspace.templates.hotfix`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+
+		cfg, _, err := config.LoadLayered(findConfigRoot())
+		if err != nil {
+			return configLoadError(err)
+		}
+
+		if value, err := config.Get(cfg, key); err == nil {
+			fmt.Println(unquoteValue(value))
+			return nil
+		}
+
+		found := false
+		for _, k := range config.Keys(cfg) {
+			if strings.HasPrefix(k, key+".") {
+				value, _ := config.Get(cfg, k)
+				fmt.Printf("%s = %s\n", k, value)
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a config value",
+	Long: `Set a dotted config key in the repo config (.ocw/config.toml), or in the user
+or local config with --user or --local. The value is parsed according to the
+type of the key; lists are given comma-separated. Comments and the order of
+existing keys in the file are preserved.
+
+Examples:
+  ocw config set merge.draft_pr true
+  ocw config set opencode.args -- --verbose,--fast
+  ocw config set workspace.templates.docs.base_branch main --local`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, raw := args[0], args[1]
+
+		path, err := configFilePath(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.SetValue(path, key, raw)
+		if err != nil {
+			return err
+		}
+
+		value, _ := config.Get(cfg, key)
+		fmt.Printf("✓ Set %s = %s in %s\n", key, value, path)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a config value",
+	Long: `Remove a dotted config key, or a whole table such as a template, from the repo
+config (.ocw/config.toml), or from the user or local config with --user or
+--local. The value of the next lower layer applies again.
+
+Examples:
+  ocw config unset opencode.model --local
+  ocw config unset workspace.templates.docs`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+
+		path, err := configFilePath(cmd)
+		if err != nil {
+			return err
+		}
+
+		removed, err := config.UnsetValue(path, key)
+		if err != nil {
+			return fmt.Errorf("failed to unset %s: %w", key, err)
+		}
+		if !removed {
+			return fmt.Errorf("%s is not set in %s", key, path)
+		}
+
+		fmt.Printf("✓ Removed %s from %s\n", key, path)
+		return nil
+	},
+}
+
+// findConfigRoot returns the root of the git repository containing the
+// current directory, or "" outside a repository
+func findConfigRoot() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	repoRoot := cwd
+	for {
+		if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+			return repoRoot
+		}
+		parent := filepath.Dir(repoRoot)
+		if parent == repoRoot {
+			return ""
+		}
+		repoRoot = parent
+	}
+}
+
+// configFilePath returns the config file selected by the --user and --local flags
+func configFilePath(cmd *cobra.Command) (string, error) {
+	user, _ := cmd.Flags().GetBool("user")
+	local, _ := cmd.Flags().GetBool("local")
+	if user && local {
+		return "", fmt.Errorf("--user and --local cannot be used together")
+	}
+	if user {
+		return config.UserConfigPath()
+	}
+
+	repoRoot := findConfigRoot()
+	if repoRoot == "" {
+		return "", fmt.Errorf("not in a git repository\n\nTo fix:\n  1. Use --user to change the user config\n  2. Or navigate to a repository initialized with ocw init")
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, ".ocw")); os.IsNotExist(err) {
+		return "", fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+	}
+
+	if local {
+		return config.LocalConfigPath(repoRoot), nil
+	}
+	return config.RepoConfigPath(repoRoot), nil
+}
+
+// unquoteValue prints TOML strings without their quotes
+func unquoteValue(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
+// printProblems prints config problems one per line, prefixed with their severity
+func printProblems(w io.Writer, problems []config.Problem) {
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s: %s\n", p.Severity, p)
+	}
+}
+
+// configWarnings prints the warnings of a loaded config to stderr
+func configWarnings(cfg *config.Config, origins config.Origins) {
+	problems := config.Check(cfg, origins)
+	if len(problems) == 0 {
+		return
+	}
+	printProblems(os.Stderr, problems)
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// configLoadError explains how to fix a config that failed to load
+func configLoadError(err error) error {
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		return fmt.Errorf("%w\n\nTo fix:\n  1. Correct the keys and values listed above\n  2. Check the result with: ocw config validate", err)
+	}
+	return fmt.Errorf("failed to load config: %w", err)
+}
+
 func init() {
 	configCmd.Flags().Bool("path", false, "Print config file path instead of opening")
+	configShowCmd.Flags().Bool("origin", false, "Show the layer each value comes from")
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	for _, c := range []*cobra.Command{configSetCmd, configUnsetCmd} {
+		c.Flags().Bool("user", false, "Change the user config (~/.config/ocw/config.toml)")
+		c.Flags().Bool("local", false, "Change the git-ignored local config (.ocw/config.local.toml)")
+	}
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
 	rootCmd.AddCommand(configCmd)
 }
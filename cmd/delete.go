@@ -15,8 +15,13 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete <id|name>",
 	Short: "Delete an instance",
-	Long:  "Delete an instance and clean up all associated resources (worktree, tmux window, state)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete an instance and clean up all associated resources (worktree, tmux window, state).
+
+The instance record is moved to the archive and its branch tip is kept under
+refs/ocw/archive/<id>, so it can be brought back with 'ocw restore <id>'.
+With --stash (the default unless workspace.stash_on_delete is false),
+uncommitted changes are stashed into the archive as well.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		instanceID := args[0]
 		force, _ := cmd.Flags().GetBool("force")
@@ -100,7 +105,16 @@ var deleteCmd = &cobra.Command{
 			}
 		}
 
-		err = mgr.DeleteInstance(instanceID, force, deleteBranch)
+		stash := cfg.Workspace.StashOnDelete
+		if cmd.Flags().Changed("stash") {
+			stash, _ = cmd.Flags().GetBool("stash")
+		}
+
+		err = mgr.DeleteInstance(instanceID, workspace.DeleteOpts{
+			Force:        force,
+			DeleteBranch: deleteBranch,
+			StashChanges: stash,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to delete instance: %w", err)
 		}
@@ -112,6 +126,7 @@ var deleteCmd = &cobra.Command{
 		if deleteBranch {
 			fmt.Printf("  Branch deletion: yes\n")
 		}
+		fmt.Printf("\nThe instance was archived. Bring it back with: ocw restore %s\n", instance.ID)
 
 		return nil
 	},
@@ -120,5 +135,6 @@ var deleteCmd = &cobra.Command{
 func init() {
 	deleteCmd.Flags().BoolP("force", "f", false, "Force deletion without confirmation")
 	deleteCmd.Flags().Bool("delete-branch", false, "Also delete the branch")
+	deleteCmd.Flags().Bool("stash", true, "Stash uncommitted changes into the archive (default from workspace.stash_on_delete)")
 	rootCmd.AddCommand(deleteCmd)
 }
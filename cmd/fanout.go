@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var fanoutCmd = &cobra.Command{
+	Use:   "fanout [branch]",
+	Short: "Run one task across several agents or models",
+	Long: `Give the same prompt to several sibling instances and keep the best result.
+
+Each --matrix adds a dimension of key=value,value; one sibling is created for
+every combination of values, --count times. The agent, model and provider keys
+pick the agent profile and its model; any other key is exported into the
+sibling's panes as an environment variable. The siblings run on the branches
+<branch>-1, <branch>-2, ... (fanout/<id>-1, ... without a branch) and are
+grouped under one fan-out ID.
+
+Compare the siblings with 'ocw fanout compare <id>' or in the TUI, then keep
+one with 'ocw fanout pick <instance>', which archives the others.
+
+Examples:
+  ocw fanout task/login --prompt-file task.md --matrix model=opus,sonnet,haiku
+  ocw fanout task/cache --prompt "Add a cache" --matrix agent=opencode,aider --matrix TEMPERATURE=0,0.7
+  ocw fanout --prompt "Fix the flaky test" --count 3`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseBranch, _ := cmd.Flags().GetString("base")
+		templateName, _ := cmd.Flags().GetString("template")
+		agentName, _ := cmd.Flags().GetString("agent")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		matrixFlags, _ := cmd.Flags().GetStringArray("matrix")
+		count, _ := cmd.Flags().GetInt("count")
+		task, _ := cmd.Flags().GetString("task")
+
+		var branchName string
+		if len(args) > 0 {
+			branchName = args[0]
+		}
+
+		var matrix []workspace.MatrixAxis
+		for _, flag := range matrixFlags {
+			axis, err := workspace.ParseMatrixAxis(flag)
+			if err != nil {
+				return err
+			}
+			matrix = append(matrix, axis)
+		}
+
+		prompt, _ := cmd.Flags().GetString("prompt")
+		if promptFile, _ := cmd.Flags().GetString("prompt-file"); promptFile != "" {
+			if prompt != "" {
+				return fmt.Errorf("use either --prompt or --prompt-file, not both")
+			}
+			var err error
+			prompt, err = readTaskFile(promptFile)
+			if err != nil {
+				return err
+			}
+		}
+		prompt = strings.TrimSpace(prompt)
+		if prompt == "" && templateName == "" {
+			return fmt.Errorf("a fan-out needs a prompt\n\nTo fix:\n  1. Pass one: ocw fanout --prompt \"...\" or --prompt-file task.md\n  2. Or use a template with a prompt: ocw fanout --template <name>")
+		}
+		if task == "" {
+			task = prompt
+		}
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+		if baseBranch == "" && templateName == "" {
+			baseBranch = mgr.Config().Workspace.BaseBranch
+		}
+
+		id, siblings, err := mgr.FanOut(workspace.FanOutOpts{
+			CreateOpts: workspace.CreateOpts{
+				Branch:     branchName,
+				BaseBranch: baseBranch,
+				Tags:       tags,
+				Task:       task,
+				Prompt:     prompt,
+				Template:   templateName,
+				Agent:      agentName,
+			},
+			Matrix: matrix,
+			Count:  count,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Fan-out %s created with %d siblings\n\n", id, len(siblings))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tBRANCH\tAGENT\tVARIANT\tSTATUS")
+		fmt.Fprintln(w, "--\t------\t-----\t-------\t------")
+		for _, inst := range siblings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", inst.ID, inst.Branch, inst.Agent, valueOrDash(inst.Variant), inst.Status)
+		}
+		w.Flush()
+
+		fmt.Printf("\nCompare them with: ocw fanout compare %s\n", id)
+		fmt.Println("Keep the best with: ocw fanout pick <instance>")
+		return nil
+	},
+}
+
+var fanoutCompareCmd = &cobra.Command{
+	Use:   "compare <fanout|instance>",
+	Short: "Compare the siblings of a fan-out",
+	Long: `Show each sibling's changes against the base branch, the files that
+conflict with the base branch and, with --test, whether fanout.test_command
+passes in its worktree. The fan-out may be given by its ID or by any of its
+siblings.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		test, _ := cmd.Flags().GetBool("test")
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveFanOut(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		if test {
+			fmt.Printf("Running %q in each sibling...\n\n", mgr.Config().FanOut.TestCommand)
+		}
+		comparisons, err := mgr.CompareFanOut(id, test)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Fan-out %s: %d siblings\n\n", id, len(comparisons))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tVARIANT\tSTATUS\tCHANGES\tCONFLICTS\tTESTS")
+		fmt.Fprintln(w, "--\t----\t-------\t------\t-------\t---------\t-----")
+		for _, c := range comparisons {
+			changes, conflicts := formatChanges(c), formatConflicts(c)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.Instance.ID,
+				c.Instance.Name,
+				valueOrDash(c.Instance.Variant),
+				c.Instance.Status,
+				changes,
+				conflicts,
+				formatTestResult(c.Test),
+			)
+		}
+		w.Flush()
+
+		for _, c := range comparisons {
+			if c.Err != nil {
+				fmt.Printf("\n%s could not be compared: %v\n", c.Instance.Name, c.Err)
+			}
+			if c.Test != nil && !c.Test.Passed && c.Test.Output != "" {
+				fmt.Printf("\nTest output of %s:\n%s\n", c.Instance.Name, c.Test.Output)
+			}
+		}
+		return nil
+	},
+}
+
+var fanoutPickCmd = &cobra.Command{
+	Use:   "pick <instance>",
+	Short: "Keep one sibling of a fan-out and archive the others",
+	Long: `Keep the given sibling and delete the other siblings of its fan-out
+together with their branches. They are archived first, so any of them can be
+brought back with 'ocw restore <id>'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		instanceID, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+		winner, err := mgr.GetInstance(instanceID)
+		if err != nil {
+			return err
+		}
+		if winner.FanOut == "" {
+			return fmt.Errorf("instance %s is not part of a fan-out", winner.Name)
+		}
+		siblings, err := mgr.FanOutSiblings(winner.FanOut)
+		if err != nil {
+			return err
+		}
+
+		if !force {
+			fmt.Printf("⚠ Keep '%s' (%s) and archive its %d other siblings?\n", winner.Name, valueOrDash(winner.Variant), len(siblings)-1)
+			fmt.Print("Their worktrees and branches are removed and their processes killed. [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Pick cancelled.")
+				return nil
+			}
+		}
+
+		archived, err := mgr.PickWinner(instanceID)
+		for _, inst := range archived {
+			fmt.Printf("✓ Archived %s (%s)\n", inst.Name, valueOrDash(inst.Variant))
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Kept %s on branch %s\n", winner.Name, winner.Branch)
+		if len(archived) > 0 {
+			fmt.Println("\nBring an archived sibling back with: ocw restore <id>")
+		}
+		return nil
+	},
+}
+
+// resolveFanOut returns the fan-out named by ref, a fan-out ID or one of its siblings
+func resolveFanOut(mgr *workspace.Manager, ref string) (string, error) {
+	if _, err := mgr.FanOutSiblings(ref); err == nil {
+		return ref, nil
+	}
+
+	instanceID, err := resolveInstanceID(mgr, ref)
+	if err != nil {
+		return "", fmt.Errorf("fan-out %q not found\n\nTo fix:\n  1. Use the fan-out ID printed by ocw fanout\n  2. Or name one of its siblings", ref)
+	}
+	inst, err := mgr.GetInstance(instanceID)
+	if err != nil {
+		return "", err
+	}
+	if inst.FanOut == "" {
+		return "", fmt.Errorf("instance %s is not part of a fan-out", inst.Name)
+	}
+	return inst.FanOut, nil
+}
+
+// formatChanges renders a sibling's diff against its base, e.g. "3 files +10 -2"
+func formatChanges(c workspace.FanOutComparison) string {
+	if c.Err != nil {
+		return "?"
+	}
+	if c.Diff.FilesChanged == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files +%d -%d", c.Diff.FilesChanged, c.Diff.Insertions, c.Diff.Deletions)
+}
+
+// formatConflicts renders the number of files conflicting with the base branch
+func formatConflicts(c workspace.FanOutComparison) string {
+	if c.Err != nil {
+		return "?"
+	}
+	if len(c.Conflicts) == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files", len(c.Conflicts))
+}
+
+// formatTestResult renders a test run, e.g. "passed (12s)"; "-" if not tested
+func formatTestResult(r *workspace.TestResult) string {
+	switch {
+	case r == nil:
+		return "-"
+	case r.Passed:
+		return fmt.Sprintf("passed (%s)", r.Duration.Round(time.Second))
+	case r.Error != "":
+		return r.Error
+	default:
+		return fmt.Sprintf("failed (%s)", r.Duration.Round(time.Second))
+	}
+}
+
+func init() {
+	fanoutCmd.Flags().String("prompt", "", "Prompt sent to every sibling once its agent is ready")
+	fanoutCmd.Flags().String("prompt-file", "", "Read the prompt from a file (- for stdin)")
+	fanoutCmd.Flags().StringArray("matrix", nil, "Matrix dimension as key=value,value (repeatable)")
+	fanoutCmd.Flags().Int("count", 1, "Siblings per combination of matrix values")
+	fanoutCmd.Flags().StringP("base", "b", "", "Base branch to branch from (default: from config)")
+	fanoutCmd.Flags().StringP("template", "t", "", "Template to apply to every sibling")
+	fanoutCmd.Flags().String("agent", "", "Agent profile for siblings the matrix does not pick one for")
+	fanoutCmd.Flags().StringSlice("tag", nil, "Tag to attach to every sibling (repeatable or comma-separated)")
+	fanoutCmd.Flags().String("task", "", "Task description (default: the prompt)")
+	fanoutCompareCmd.Flags().Bool("test", false, "Run fanout.test_command in each sibling's worktree")
+	fanoutPickCmd.Flags().BoolP("force", "f", false, "Pick without confirmation")
+
+	fanoutCmd.AddCommand(fanoutCompareCmd)
+	fanoutCmd.AddCommand(fanoutPickCmd)
+	rootCmd.AddCommand(fanoutCmd)
+}
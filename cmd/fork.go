@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var forkCmd = &cobra.Command{
+	Use:   "fork <instance> <new-branch>",
+	Short: "Fork an instance, including its uncommitted work",
+	Long: `Create a new instance from the current state of an existing one, e.g. to try
+two directions from a promising midpoint.
+
+The new branch starts at the instance's current HEAD, and its staged, unstaged
+and untracked changes are carried over into the new worktree; the instance
+itself is left untouched. The fork keeps the instance's tags, metadata, task,
+template, environment, agent profile and sub-terminal layout, and remembers
+the instance it was forked from.
+
+--prompt or --prompt-file gives the fork's agent its next instructions once it
+is ready to accept input.
+
+Examples:
+  ocw fork login feature/login-jwt
+  ocw fork a1b2c3 feature/login-sessions --prompt "Use server-side sessions instead"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+
+		prompt, _ := cmd.Flags().GetString("prompt")
+		if promptFile, _ := cmd.Flags().GetString("prompt-file"); promptFile != "" {
+			if prompt != "" {
+				return fmt.Errorf("use either --prompt or --prompt-file, not both")
+			}
+			var err error
+			prompt, err = readTaskFile(promptFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		fork, err := mgr.ForkInstance(id, workspace.ForkOpts{
+			Branch: args[1],
+			Name:   name,
+			Prompt: strings.TrimSpace(prompt),
+		})
+		if err != nil && fork == nil {
+			return err
+		}
+
+		fmt.Printf("✓ Instance forked\n")
+		fmt.Printf("  ID:       %s\n", fork.ID)
+		if fork.Name != fork.Branch {
+			fmt.Printf("  Name:     %s\n", fork.Name)
+		}
+		fmt.Printf("  Branch:   %s\n", fork.Branch)
+		fmt.Printf("  From:     %s\n", args[0])
+		fmt.Printf("  Worktree: %s\n", fork.WorktreePath)
+		fmt.Printf("  Status:   %s\n", fork.Status)
+		if summary := workspace.TaskSummary(fork.Prompt); summary != "" {
+			fmt.Printf("  Prompt:   %s\n", summary)
+		}
+
+		if err != nil {
+			// Fail the command so scripts notice, without repeating the usage
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	forkCmd.Flags().String("name", "", "Display name for the fork (default: the branch)")
+	forkCmd.Flags().String("prompt", "", "Prompt sent to the fork's agent once it is ready")
+	forkCmd.Flags().String("prompt-file", "", "Read the prompt from a file (- for stdin)")
+	rootCmd.AddCommand(forkCmd)
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [instance]",
+	Short: "Show the instance lifecycle event journal",
+	Long: `Show lifecycle events recorded in .ocw/events.jsonl.
+
+Events are recorded when instances are created, bootstrapped, sent their
+initial prompt, paused, resumed, renamed, deleted, change status, gain or lose
+dependencies, open a pull request, or are removed by startup reconciliation.
+
+The instance argument may be an ID, name or branch, and also matches
+instances that no longer exist.
+
+Time ranges accept a duration relative to now (e.g. 2h, 30m), a date
+(2006-01-02) or an RFC3339 timestamp.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types, _ := cmd.Flags().GetStringSlice("type")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		// Find git repository root
+		repoRoot := cwd
+		for {
+			if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+				break
+			}
+			parent := filepath.Dir(repoRoot)
+			if parent == repoRoot {
+				return fmt.Errorf("not in a git repository")
+			}
+			repoRoot = parent
+		}
+
+		// Check if .ocw exists
+		ocwDir := filepath.Join(repoRoot, ".ocw")
+		if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+			return fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+		}
+
+		cfg, err := config.LoadConfig(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		mgr, err := workspace.NewManager(repoRoot, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace manager: %w", err)
+		}
+
+		filter := state.EventFilter{}
+
+		if len(args) == 1 {
+			// Prefer the live instance ID, but fall back to the raw reference so
+			// history of deleted instances stays reachable
+			filter.Instance = args[0]
+			if id, err := resolveInstanceID(mgr, args[0]); err == nil {
+				filter.Instance = id
+			}
+		}
+
+		for _, t := range types {
+			evType, err := parseEventType(t)
+			if err != nil {
+				return err
+			}
+			filter.Types = append(filter.Types, evType)
+		}
+
+		if sinceStr != "" {
+			if filter.Since, err = parseTimeFlag(sinceStr); err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+		}
+		if untilStr != "" {
+			if filter.Until, err = parseTimeFlag(untilStr); err != nil {
+				return fmt.Errorf("invalid --until value: %w", err)
+			}
+		}
+
+		events, err := mgr.History(filter)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			data, err := json.MarshalIndent(events, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal events to JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No events found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tEVENT\tID\tNAME\tDETAILS")
+		fmt.Fprintln(w, "----\t-----\t--\t----\t-------")
+
+		for _, ev := range events {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				ev.Time.Local().Format("2006-01-02 15:04:05"),
+				ev.Type,
+				ev.InstanceID,
+				ev.Instance,
+				formatEventDetails(ev),
+			)
+		}
+
+		w.Flush()
+		return nil
+	},
+}
+
+// parseEventType validates an event type given on the command line
+func parseEventType(s string) (state.EventType, error) {
+	for _, t := range state.EventTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+
+	valid := make([]string, len(state.EventTypes))
+	for i, t := range state.EventTypes {
+		valid[i] = string(t)
+	}
+	return "", fmt.Errorf("unknown event type %q\n\nValid types: %s", s, strings.Join(valid, ", "))
+}
+
+// parseTimeFlag parses a duration relative to now, a date, or an RFC3339 timestamp
+func parseTimeFlag(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration, date (2006-01-02) or RFC3339 timestamp", s)
+}
+
+// formatEventDetails renders the transition, reason and extra details of an event on one line
+func formatEventDetails(ev state.Event) string {
+	var parts []string
+
+	if ev.From != "" || ev.To != "" {
+		parts = append(parts, fmt.Sprintf("%s → %s", valueOrDash(ev.From), valueOrDash(ev.To)))
+	}
+	if ev.Reason != "" {
+		parts = append(parts, ev.Reason)
+	}
+	for _, key := range sortedKeys(ev.Details) {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, ev.Details[key]))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	historyCmd.Flags().StringSlice("type", nil, "Only show events of these types (repeatable or comma-separated)")
+	historyCmd.Flags().String("since", "", "Only show events after this time (duration, date or RFC3339)")
+	historyCmd.Flags().String("until", "", "Only show events before this time (duration, date or RFC3339)")
+	historyCmd.Flags().Bool("json", false, "Print events as JSON")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -7,12 +7,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/workspace"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize OCW workspace",
-	Long:  "Initialize OCW workspace by creating .ocw directory with default config and state files",
+	Long:  "Initialize OCW workspace by creating .ocw directory with a skeleton config and empty state file",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get current working directory
 		cwd, err := os.Getwd()
@@ -43,6 +44,13 @@ var initCmd = &cobra.Command{
 			return fmt.Errorf("failed to initialize workspace: %w", err)
 		}
 
+		// Register the repo so it shows up in 'ocw list --all-repos' and the TUI repo switcher
+		if cfg, err := config.LoadConfig(repoRoot); err == nil {
+			if err := workspace.RegisterRepo(repoRoot, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to register repository: %v\n", err)
+			}
+		}
+
 		fmt.Printf("✓ OCW workspace initialized successfully\n")
 		fmt.Printf("  Location: %s\n", repoRoot)
 		fmt.Printf("  Config:   %s\n", filepath.Join(ocwDir, "config.toml"))
@@ -89,7 +89,7 @@ var killCmd = &cobra.Command{
 		// Delete all instances
 		for _, inst := range instances {
 			fmt.Printf("Deleting instance %s...\n", inst.Name)
-			if err := mgr.DeleteInstance(inst.ID, true, false); err != nil {
+			if err := mgr.DeleteInstance(inst.ID, workspace.DeleteOpts{Force: true, StashChanges: cfg.Workspace.StashOnDelete}); err != nil {
 				fmt.Printf("  ⚠️  Failed to delete instance %s: %v\n", inst.Name, err)
 			} else {
 				fmt.Printf("  ✓ Instance %s deleted\n", inst.Name)
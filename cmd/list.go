@@ -9,14 +9,25 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/registry"
+	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/workspace"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all instances",
-	Long:  "List all instances with their status, branch, and creation time",
+	Long:  "List all instances with their status, branch, and creation time.\nWith --all-repos, list instances of every repository registered with ocw.",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		selector, err := selectorFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		if allRepos, _ := cmd.Flags().GetBool("all-repos"); allRepos {
+			return listAllRepos(selector)
+		}
+
 		cwd, err := os.Getwd()
 		if err != nil {
 			return fmt.Errorf("failed to get current directory: %w", err)
@@ -56,6 +67,14 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("failed to list instances: %w", err)
 		}
 
+		if !selector.Empty() {
+			instances = selector.Filter(instances)
+			if len(instances) == 0 {
+				fmt.Printf("No instances match selector %q.\n", selector.String())
+				return nil
+			}
+		}
+
 		if len(instances) == 0 {
 			fmt.Println("No instances found.")
 			fmt.Println("\nCreate a new instance with: ocw new <branch>")
@@ -64,8 +83,8 @@ var listCmd = &cobra.Command{
 
 		// Create tabwriter for aligned output
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tNAME\tBRANCH\tSTATUS\tCREATED")
-		fmt.Fprintln(w, "--\t----\t------\t------\t-------")
+		fmt.Fprintln(w, "ID\tNAME\tBRANCH\tSTATUS\tPORTS\tCREATED\tLABELS")
+		fmt.Fprintln(w, "--\t----\t------\t------\t-----\t-------\t------")
 
 		for _, inst := range instances {
 			// Format created time
@@ -77,12 +96,14 @@ var listCmd = &cobra.Command{
 				displayID = displayID[:8]
 			}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				displayID,
 				inst.Name,
 				inst.Branch,
 				inst.Status,
+				valueOrDash(inst.PortList()),
 				createdStr,
+				valueOrDash(inst.Labels()),
 			)
 		}
 
@@ -91,6 +112,65 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// listAllRepos prints the instances of every registered repository
+func listAllRepos(selector state.Selector) error {
+	reg, err := registry.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open repository registry: %w", err)
+	}
+
+	repos, err := workspace.ListRepos(reg)
+	if err != nil {
+		return err
+	}
+
+	if len(repos) == 0 {
+		fmt.Println("No repositories registered.")
+		fmt.Println("\nRepositories are registered by 'ocw init' and when their session starts.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tID\tNAME\tBRANCH\tSTATUS\tPORTS\tCREATED\tLABELS")
+	fmt.Fprintln(w, "----\t--\t----\t------\t------\t-----\t-------\t------")
+
+	var total int
+	for _, repo := range repos {
+		repoLabel := repo.Repo.Name
+		if repo.SessionRunning {
+			repoLabel += " *"
+		}
+
+		if repo.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t(failed to load state: %v)\n", repoLabel, repo.Err)
+			continue
+		}
+
+		for _, inst := range selector.Filter(repo.Instances) {
+			displayID := inst.ID
+			if len(displayID) > 8 {
+				displayID = displayID[:8]
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				repoLabel,
+				displayID,
+				inst.Name,
+				inst.Branch,
+				inst.Status,
+				valueOrDash(inst.PortList()),
+				formatTime(inst.CreatedAt),
+				valueOrDash(inst.Labels()),
+			)
+			total++
+		}
+	}
+
+	w.Flush()
+	fmt.Printf("\n%d instance(s) across %d repositories (* = tmux session running)\n", total, len(repos))
+	return nil
+}
+
 // formatTime formats a time.Time into a human-readable string
 func formatTime(t time.Time) string {
 	now := time.Now()
@@ -123,5 +203,7 @@ func formatTime(t time.Time) string {
 }
 
 func init() {
+	addSelectorFlag(listCmd)
+	listCmd.Flags().Bool("all-repos", false, "List instances of every repository registered with ocw")
 	rootCmd.AddCommand(listCmd)
 }
@@ -119,11 +119,15 @@ var mergeCmd = &cobra.Command{
 
 		fmt.Printf("✓ Branch pushed\n\n")
 
-		prTitle := formatBranchNameForPR(instance.Branch)
+		prTitle := workspace.PRTitleFromTask(*instance)
+		if prTitle == "" {
+			prTitle = formatBranchNameForPR(instance.Branch)
+		}
+		prBody := workspace.PRBodyFromTask(*instance)
 		fmt.Printf("Creating PR with %s...\n", tool)
 		fmt.Printf("Title: %s\n", prTitle)
 
-		prURL, err := mgr.CreatePR(instance.ID, prTitle, "")
+		prURL, err := mgr.CreatePR(instance.ID, prTitle, prBody)
 		if err != nil {
 			return err
 		}
@@ -4,21 +4,93 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/workspace"
 )
 
 var newCmd = &cobra.Command{
-	Use:   "new <branch>",
+	Use:   "new [branch]",
 	Short: "Create a new instance",
-	Long:  "Create a new instance with a dedicated worktree and tmux window",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new instance with a dedicated worktree and tmux window.
+
+A template from [workspace.templates] or .ocw/templates/<name>.toml can set the
+base branch, a branch pattern such as "hotfix/{name}", the agent profile and its
+command, model and args, environment variables, files copied from the main
+checkout, a sub-terminal layout, tags and an initial prompt.
+
+--agent picks an agent profile from [agents] (opencode and shell are built in)
+instead of the template's or workspace.agent.
+
+--prompt or --prompt-file gives the agent its first instructions. The prompt is
+sent once the agent is ready to accept input (its ready_pattern shows, or its
+output has settled) and replaces the template's prompt.
+
+Templates can declare variables with [[vars]] and use them as {var} in the
+branch pattern, name pattern and prompt. {var|slug} turns a value into a
+branch-friendly slug; lower and upper are also available. The branch argument
+is the built-in {name} and may be omitted when the template's branch pattern
+does not use it.
+
+Examples:
+  ocw new feature/login
+  ocw new login-timeout --template hotfix
+  ocw new refactor/db --agent aider
+  ocw new fix/flaky-test --prompt "Make TestLogin pass reliably"
+  ocw new --template feature --var ticket=OCW-123 --var title="Fix login"`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		branchName := args[0]
 		baseBranch, _ := cmd.Flags().GetString("base")
 		templateName, _ := cmd.Flags().GetString("template")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		metaPairs, _ := cmd.Flags().GetStringArray("meta")
+		varPairs, _ := cmd.Flags().GetStringArray("var")
+		agentName, _ := cmd.Flags().GetString("agent")
+
+		var branchName string
+		if len(args) > 0 {
+			branchName = args[0]
+		} else if templateName == "" {
+			return fmt.Errorf("branch name is required\n\nTo fix:\n  1. Pass a branch: ocw new <branch>\n  2. Or use a template with a branch pattern: ocw new --template <name> --var key=value")
+		}
+
+		metadata, err := state.ParseMetadata(metaPairs)
+		if err != nil {
+			return err
+		}
+
+		vars, err := config.ParseVars(varPairs)
+		if err != nil {
+			return err
+		}
+		if len(vars) > 0 && templateName == "" {
+			return fmt.Errorf("--var requires --template")
+		}
+
+		task, _ := cmd.Flags().GetString("task")
+		if taskFile, _ := cmd.Flags().GetString("task-file"); taskFile != "" {
+			if task != "" {
+				return fmt.Errorf("use either --task or --task-file, not both")
+			}
+			task, err = readTaskFile(taskFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		prompt, _ := cmd.Flags().GetString("prompt")
+		if promptFile, _ := cmd.Flags().GetString("prompt-file"); promptFile != "" {
+			if prompt != "" {
+				return fmt.Errorf("use either --prompt or --prompt-file, not both")
+			}
+			prompt, err = readTaskFile(promptFile)
+			if err != nil {
+				return err
+			}
+		}
 
 		// Get current working directory
 		cwd, err := os.Getwd()
@@ -53,30 +125,25 @@ var newCmd = &cobra.Command{
 			return fmt.Errorf("failed to create workspace manager: %w", err)
 		}
 
-		// Apply template if specified
-		var initCommand string
-		if templateName != "" {
-			if template, ok := cfg.Workspace.Templates[templateName]; ok {
-				if baseBranch == "" {
-					baseBranch = template.BaseBranch
-				}
-				initCommand = template.InitCommand
-			} else {
-				return fmt.Errorf("template %q not found in config", templateName)
-			}
-		}
-
-		// Use default base branch if not provided
-		if baseBranch == "" {
+		// Use default base branch if neither the flag nor the template sets one
+		if baseBranch == "" && templateName == "" {
 			baseBranch = cfg.Workspace.BaseBranch
 		}
 
-		// Create instance
+		// Create instance; a template derives the name from its name pattern
 		opts := workspace.CreateOpts{
-			Name:        branchName,
-			Branch:      branchName,
-			BaseBranch:  baseBranch,
-			InitCommand: initCommand,
+			Branch:     branchName,
+			BaseBranch: baseBranch,
+			Tags:       tags,
+			Metadata:   metadata,
+			Task:       task,
+			Prompt:     strings.TrimSpace(prompt),
+			Template:   templateName,
+			Agent:      agentName,
+			Vars:       vars,
+		}
+		if templateName == "" {
+			opts.Name = branchName
 		}
 
 		instance, err := mgr.CreateInstance(opts)
@@ -87,10 +154,34 @@ var newCmd = &cobra.Command{
 		// Print success message
 		fmt.Printf("✓ Instance created successfully\n")
 		fmt.Printf("  ID:       %s\n", instance.ID)
+		if instance.Name != instance.Branch {
+			fmt.Printf("  Name:     %s\n", instance.Name)
+		}
 		fmt.Printf("  Branch:   %s\n", instance.Branch)
 		fmt.Printf("  Base:     %s\n", instance.BaseBranch)
 		fmt.Printf("  Worktree: %s\n", instance.WorktreePath)
+		fmt.Printf("  Agent:    %s\n", instance.Agent)
 		fmt.Printf("  Status:   %s\n", instance.Status)
+		if ports := instance.PortList(); ports != "" {
+			fmt.Printf("  Ports:    %s\n", ports)
+		}
+		if labels := instance.Labels(); labels != "" {
+			fmt.Printf("  Labels:   %s\n", labels)
+		}
+		if summary := workspace.TaskSummary(instance.Task); summary != "" {
+			fmt.Printf("  Task:     %s\n", summary)
+		}
+		if instance.Template != "" {
+			fmt.Printf("  Template: %s\n", instance.Template)
+		}
+		if summary := workspace.TaskSummary(instance.Prompt); summary != "" {
+			fmt.Printf("  Prompt:   %s\n", summary)
+		}
+		// What bootstrapping did is only recorded in the journal
+		bootstrapped, _ := mgr.History(state.EventFilter{Instance: instance.ID, Types: []state.EventType{state.EventBootstrapped}})
+		if len(bootstrapped) > 0 {
+			fmt.Printf("  Files:    %s\n", bootstrapped[len(bootstrapped)-1].Reason)
+		}
 
 		return nil
 	},
@@ -98,6 +189,14 @@ var newCmd = &cobra.Command{
 
 func init() {
 	newCmd.Flags().StringP("base", "b", "", "Base branch to branch from (default: from config)")
-	newCmd.Flags().StringP("template", "t", "", "Template to apply (overrides base branch and runs init command)")
+	newCmd.Flags().StringP("template", "t", "", "Template to apply (see ocw new --help)")
+	newCmd.Flags().StringArray("var", nil, "Template variable as name=value (repeatable)")
+	newCmd.Flags().String("agent", "", "Agent profile to run (default: from the template or workspace.agent)")
+	newCmd.Flags().StringSlice("tag", nil, "Tag to attach to the instance (repeatable or comma-separated)")
+	newCmd.Flags().StringArray("meta", nil, "Metadata to attach as key=value (repeatable)")
+	newCmd.Flags().String("task", "", "Task description given to the agent")
+	newCmd.Flags().String("task-file", "", "Read the task description from a file (- for stdin)")
+	newCmd.Flags().String("prompt", "", "Prompt sent to the agent once it is ready (default: the template's)")
+	newCmd.Flags().String("prompt-file", "", "Read the prompt from a file (- for stdin)")
 	rootCmd.AddCommand(newCmd)
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <instance> [new-name]",
+	Short: "Rename an instance, its branch and worktree",
+	Long: `Rename an instance and its tmux window.
+
+With --branch the git branch is renamed too and the worktree is moved to the
+directory derived from the new name. Instances based on the branch are based
+on the new name, and the head branch of an open GitHub pull request is renamed
+with it. An instance named after its branch follows the branch unless a new
+name is given. If a step fails, the steps before it are undone.
+
+Programs already running in the instance's panes, including the agent and
+sub-terminals, keep working in the moved directory, but their OCW_BRANCH and
+OCW_WORKTREE still name the old branch and path. Restart programs or hooks
+that rely on them; sub-terminals opened after the rename get the new values.
+
+Examples:
+  ocw rename login login-v2
+  ocw rename login --branch feature/login-jwt`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branch, _ := cmd.Flags().GetString("branch")
+
+		var name string
+		if len(args) > 1 {
+			name = args[1]
+		}
+		if name == "" && branch == "" {
+			return fmt.Errorf("nothing to rename\n\nTo fix:\n  1. Give a new name: ocw rename %s <new-name>\n  2. Or a new branch: ocw rename %s --branch <new-branch>", args[0], args[0])
+		}
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		instance, err := mgr.RenameInstance(id, workspace.RenameOpts{Name: name, Branch: branch})
+		if err != nil && instance == nil {
+			return err
+		}
+
+		fmt.Printf("✓ Instance renamed\n")
+		fmt.Printf("  ID:       %s\n", instance.ID)
+		fmt.Printf("  Name:     %s\n", instance.Name)
+		fmt.Printf("  Branch:   %s\n", instance.Branch)
+		fmt.Printf("  Worktree: %s\n", instance.WorktreePath)
+		if instance.PRUrl != "" {
+			fmt.Printf("  PR:       %s\n", instance.PRUrl)
+		}
+
+		if branch != "" && instance.Status.Live() {
+			fmt.Printf("\nPanes already running keep the old OCW_BRANCH and OCW_WORKTREE; restart programs that rely on them.\n")
+		}
+		if err != nil {
+			// Fail the command so scripts notice, without repeating the usage
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	renameCmd.Flags().String("branch", "", "Rename the git branch and move the worktree too")
+	rootCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a deleted instance from the archive",
+	Long: `Recreate an archived instance under its original ID.
+
+The branch is recreated from refs/ocw/archive/<id> if it was deleted, then
+the worktree, tmux window and sub-terminals are created again and any
+changes stashed at deletion time are reapplied.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		// Find git repository root
+		repoRoot := cwd
+		for {
+			if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+				break
+			}
+			parent := filepath.Dir(repoRoot)
+			if parent == repoRoot {
+				return fmt.Errorf("not in a git repository")
+			}
+			repoRoot = parent
+		}
+
+		// Check if .ocw exists
+		ocwDir := filepath.Join(repoRoot, ".ocw")
+		if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+			return fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+		}
+
+		cfg, err := config.LoadConfig(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		mgr, err := workspace.NewManager(repoRoot, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace manager: %w", err)
+		}
+
+		instance, err := mgr.RestoreInstance(id)
+		if err != nil && instance == nil {
+			return err
+		}
+
+		fmt.Printf("✓ Instance restored\n")
+		fmt.Printf("  ID:       %s\n", instance.ID)
+		fmt.Printf("  Name:     %s\n", instance.Name)
+		fmt.Printf("  Branch:   %s\n", instance.Branch)
+		fmt.Printf("  Worktree: %s\n", instance.WorktreePath)
+
+		if err != nil {
+			// Fail the command so scripts notice, without repeating the usage
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/tommyzliu/ocw/internal/config"
 	"github.com/tommyzliu/ocw/internal/deps"
+	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/tui"
 	"github.com/tommyzliu/ocw/internal/workspace"
 )
@@ -17,18 +18,69 @@ var rootCmd = &cobra.Command{
 	Use:   "ocw",
 	Short: "OCW - Open Code Workspace",
 	Long:  "OCW is a terminal-based workspace manager for open source development",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		overrides, _ := cmd.Flags().GetStringArray("set")
+		return config.SetOverrides(overrides)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := runDefault(); err != nil {
+		selector, err := selectorFlag(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := runDefault(selector); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
 
+// loadManager creates a workspace manager for the repository containing the current directory
+func loadManager() (*workspace.Manager, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Find git repository root
+	repoRoot := cwd
+	for {
+		if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(repoRoot)
+		if parent == repoRoot {
+			return nil, fmt.Errorf("not in a git repository")
+		}
+		repoRoot = parent
+	}
+
+	// Check if .ocw exists
+	ocwDir := filepath.Join(repoRoot, ".ocw")
+	if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+	}
+
+	cfg, err := config.LoadConfig(repoRoot)
+	if err != nil {
+		return nil, configLoadError(err)
+	}
+
+	mgr, err := workspace.NewManager(repoRoot, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace manager: %w", err)
+	}
+
+	return mgr, nil
+}
+
 // runDefault implements the default command behavior:
 // - If OCW tmux session exists, re-attach to it
 // - Otherwise, create new session and launch TUI
-func runDefault() error {
+//
+// The selector only applies when a new TUI is launched.
+func runDefault(selector state.Selector) error {
 	depResults := deps.CheckAll()
 	if deps.HasCriticalErrors(depResults) {
 		errMsg := deps.FormatResults(depResults)
@@ -62,7 +114,7 @@ func runDefault() error {
 	// Load configuration
 	cfg, err := config.LoadConfig(repoRoot)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return configLoadError(err)
 	}
 
 	// Create workspace manager
@@ -85,11 +137,11 @@ func runDefault() error {
 		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
-	return runTUI()
+	return runTUI(selector)
 }
 
-// runTUI launches the Bubbletea TUI application
-func runTUI() error {
+// runTUI launches the Bubbletea TUI application, showing the instances matching selector
+func runTUI(selector state.Selector) error {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -111,11 +163,12 @@ func runTUI() error {
 		repoRoot = parent
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(repoRoot)
+	// Load configuration; an invalid config is refused, warnings are shown before the TUI starts
+	cfg, origins, err := config.LoadLayered(repoRoot)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return configLoadError(err)
 	}
+	configWarnings(cfg, origins)
 
 	// Create workspace manager
 	mgr, err := workspace.NewManager(repoRoot, cfg)
@@ -123,6 +176,7 @@ func runTUI() error {
 		// If manager creation fails, create a minimal context for demo
 		// This allows the TUI to run even without a full workspace setup
 		ctx := tui.NewContext(cfg, nil)
+		ctx.Selector = selector
 		app := tui.NewApp(ctx)
 		p := tea.NewProgram(app, tea.WithAltScreen())
 		app.SetProgram(p)
@@ -132,6 +186,15 @@ func runTUI() error {
 		return nil
 	}
 
+	// A corrupt state file can be restored from a snapshot before reconciling
+	if valid, _ := mgr.ValidateState(); !valid {
+		if recovered, err := mgr.RecoverFromCrash(promptRestoreSnapshot); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: State recovery failed: %v\n\n", err)
+		} else if recovered {
+			fmt.Fprintf(os.Stderr, "✓ State recovered\n\n")
+		}
+	}
+
 	result, err := mgr.Reconcile()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Reconciliation failed: %v\n", err)
@@ -139,7 +202,7 @@ func runTUI() error {
 		if result.InstancesFixed > 0 || result.InstancesRemoved > 0 || len(result.OrphanedWorktrees) > 0 {
 			fmt.Fprintf(os.Stderr, "Startup Reconciliation:\n")
 			if result.InstancesFixed > 0 {
-				fmt.Fprintf(os.Stderr, "  - Marked %d instance(s) as error (crashed/stopped)\n", result.InstancesFixed)
+				fmt.Fprintf(os.Stderr, "  - Marked %d instance(s) as error or exited (crashed/stopped)\n", result.InstancesFixed)
 			}
 			if result.InstancesRemoved > 0 {
 				fmt.Fprintf(os.Stderr, "  - Removed %d instance(s) (missing worktrees)\n", result.InstancesRemoved)
@@ -156,6 +219,7 @@ func runTUI() error {
 
 	// Create TUI context
 	ctx := tui.NewContext(cfg, mgr)
+	ctx.Selector = selector
 
 	// Create and run app
 	app := tui.NewApp(ctx)
@@ -168,6 +232,11 @@ func runTUI() error {
 	return nil
 }
 
+func init() {
+	addSelectorFlag(rootCmd)
+	rootCmd.PersistentFlags().StringArray("set", nil, "Override a config value for this run, e.g. --set opencode.model=gpt-5 (repeatable)")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and restore state snapshots",
+	Long: `Inspect and restore snapshots of .ocw/state.json.
+
+Every save of the state file also writes a timestamped snapshot to
+.ocw/snapshots/. The newest snapshots are kept; older ones are pruned.`,
+}
+
+var stateListSnapshotsCmd = &cobra.Command{
+	Use:   "list-snapshots",
+	Short: "List state snapshots, newest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStateStore()
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := store.ListSnapshots()
+		if err != nil {
+			return fmt.Errorf("failed to list snapshots: %w", err)
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SNAPSHOT\tTIME\tREVISION\tINSTANCES\tVALID")
+		fmt.Fprintln(w, "--------\t----\t--------\t---------\t-----")
+
+		for _, snap := range snapshots {
+			valid := "yes"
+			instances := fmt.Sprintf("%d", snap.Instances)
+			if !snap.Valid() {
+				valid = "no"
+				instances = "-"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+				snap.Name,
+				snap.Time.Local().Format("2006-01-02 15:04:05"),
+				snap.Revision,
+				instances,
+				valid,
+			)
+		}
+
+		w.Flush()
+		return nil
+	},
+}
+
+var stateDiffCmd = &cobra.Command{
+	Use:   "diff <snapshot>",
+	Short: "Show changes between a snapshot and the current state",
+	Long: `Show how the current state differs from a snapshot.
+
+The snapshot may be given by name, unique name prefix, or revision number.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStateStore()
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := store.LoadSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+
+		current, err := store.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: current state is unreadable (%v); comparing against an empty state\n\n", err)
+			current = &state.State{Instances: []state.Instance{}}
+		}
+
+		changes := state.DiffStates(snapshot, current)
+		if len(changes) == 0 {
+			fmt.Printf("No differences (snapshot revision %d, current revision %d).\n", snapshot.Revision, current.Revision)
+			return nil
+		}
+
+		fmt.Printf("Changes from snapshot (revision %d) to current state (revision %d):\n\n", snapshot.Revision, current.Revision)
+
+		for _, change := range changes {
+			switch change.Kind {
+			case state.ChangeAdded:
+				fmt.Printf("+ %s (%s)\n", change.InstanceID, change.Name)
+			case state.ChangeRemoved:
+				fmt.Printf("- %s (%s)\n", change.InstanceID, change.Name)
+			default:
+				fmt.Printf("~ %s (%s)\n", change.InstanceID, change.Name)
+				for _, field := range change.Fields {
+					fmt.Printf("    %s: %s → %s\n", field.Field, valueOrDash(field.From), valueOrDash(field.To))
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+var stateRollbackCmd = &cobra.Command{
+	Use:   "rollback <snapshot>",
+	Short: "Restore the state file from a snapshot",
+	Long: `Replace .ocw/state.json with the contents of a snapshot.
+
+The rollback is itself snapshotted, so it can be undone by rolling back to
+the snapshot taken just before it. Worktrees and tmux windows are not
+touched; run ocw to reconcile the restored state with reality.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		store, err := openStateStore()
+		if err != nil {
+			return err
+		}
+
+		snap, err := store.ResolveSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		if !snap.Valid() {
+			return fmt.Errorf("snapshot %s is not valid: %w", snap.Name, snap.Err)
+		}
+
+		if !force {
+			fmt.Printf("⚠ Roll back state to snapshot '%s' (revision %d, %d instance(s))?\n", snap.Name, snap.Revision, snap.Instances)
+			fmt.Print("The current state will be replaced. [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Rollback cancelled.")
+				return nil
+			}
+		}
+
+		if _, err := store.Rollback(snap.Name); err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ State restored from snapshot %s\n", snap.Name)
+		return nil
+	},
+}
+
+// openStateStore returns the state store of the repository containing the current directory
+func openStateStore() (*state.Store, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Find git repository root
+	repoRoot := cwd
+	for {
+		if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(repoRoot)
+		if parent == repoRoot {
+			return nil, fmt.Errorf("not in a git repository")
+		}
+		repoRoot = parent
+	}
+
+	// Check if .ocw exists
+	ocwDir := filepath.Join(repoRoot, ".ocw")
+	if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+	}
+
+	return state.NewStore(repoRoot), nil
+}
+
+// promptRestoreSnapshot asks whether a corrupt state file should be replaced by snap
+func promptRestoreSnapshot(snap state.Snapshot) bool {
+	fmt.Fprintf(os.Stderr, "⚠ The state file .ocw/state.json is corrupt.\n")
+	fmt.Fprintf(os.Stderr, "Restore the newest valid snapshot '%s' (revision %d, %d instance(s), taken %s)? [y/N]: ",
+		snap.Name, snap.Revision, snap.Instances, snap.Time.Local().Format("2006-01-02 15:04:05"))
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+func init() {
+	stateRollbackCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	stateCmd.AddCommand(stateListSnapshotsCmd)
+	stateCmd.AddCommand(stateDiffCmd)
+	stateCmd.AddCommand(stateRollbackCmd)
+	rootCmd.AddCommand(stateCmd)
+}
@@ -14,8 +14,13 @@ import (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show workspace status",
-	Long:  "Display the complete workspace state as JSON",
+	Long:  "Display the complete workspace state as JSON. With --selector, only matching instances are included.",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		selector, err := selectorFlag(cmd)
+		if err != nil {
+			return err
+		}
+
 		cwd, err := os.Getwd()
 		if err != nil {
 			return fmt.Errorf("failed to get current directory: %w", err)
@@ -56,6 +61,18 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("failed to load state: %w", err)
 		}
 
+		if !selector.Empty() {
+			state.Instances = selector.Filter(state.Instances)
+
+			archive := state.Archive[:0]
+			for _, a := range state.Archive {
+				if selector.Matches(a.Instance) {
+					archive = append(archive, a)
+				}
+			}
+			state.Archive = archive
+		}
+
 		// Marshal to pretty JSON
 		data, err := json.MarshalIndent(state, "", "  ")
 		if err != nil {
@@ -70,5 +87,6 @@ var statusCmd = &cobra.Command{
 }
 
 func init() {
+	addSelectorFlag(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <instance> [tag...]",
+	Short: "Manage tags and metadata of an instance",
+	Long: `Add or remove tags and key/value metadata on an instance.
+
+Tags and metadata can be matched with --selector on ocw list, ocw status and
+the dashboard. Without any tags or flags, the current labels are printed.
+
+Examples:
+  ocw tag feature-x urgent experiment
+  ocw tag feature-x --meta team=infra --meta ticket=OCW-12
+  ocw tag feature-x --remove experiment --unset ticket`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removeTags, _ := cmd.Flags().GetStringSlice("remove")
+		metaPairs, _ := cmd.Flags().GetStringArray("meta")
+		unsetKeys, _ := cmd.Flags().GetStringSlice("unset")
+
+		metadata, err := state.ParseMetadata(metaPairs)
+		if err != nil {
+			return err
+		}
+
+		change := workspace.LabelChange{
+			AddTags:       args[1:],
+			RemoveTags:    removeTags,
+			SetMetadata:   metadata,
+			UnsetMetadata: unsetKeys,
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		// Find git repository root
+		repoRoot := cwd
+		for {
+			if _, err := os.Stat(filepath.Join(repoRoot, ".git")); err == nil {
+				break
+			}
+			parent := filepath.Dir(repoRoot)
+			if parent == repoRoot {
+				return fmt.Errorf("not in a git repository")
+			}
+			repoRoot = parent
+		}
+
+		// Check if .ocw exists
+		ocwDir := filepath.Join(repoRoot, ".ocw")
+		if _, err := os.Stat(ocwDir); os.IsNotExist(err) {
+			return fmt.Errorf(".ocw directory not found; run 'ocw init' first")
+		}
+
+		cfg, err := config.LoadConfig(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		mgr, err := workspace.NewManager(repoRoot, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace manager: %w", err)
+		}
+
+		instanceID, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		if change.Empty() {
+			inst, err := mgr.GetInstance(instanceID)
+			if err != nil {
+				return err
+			}
+			printLabels(inst)
+			return nil
+		}
+
+		inst, err := mgr.UpdateLabels(instanceID, change)
+		if err != nil {
+			return fmt.Errorf("failed to update labels: %w", err)
+		}
+
+		fmt.Printf("✓ Updated labels of %s\n", inst.Name)
+		printLabels(inst)
+		return nil
+	},
+}
+
+// printLabels prints the tags and metadata of an instance
+func printLabels(inst *state.Instance) {
+	if len(inst.Tags) == 0 && len(inst.Metadata) == 0 {
+		fmt.Printf("  No tags or metadata\n")
+		return
+	}
+
+	if len(inst.Tags) > 0 {
+		fmt.Printf("  Tags:\n")
+		for _, tag := range inst.Tags {
+			fmt.Printf("    %s\n", tag)
+		}
+	}
+
+	if len(inst.Metadata) > 0 {
+		fmt.Printf("  Metadata:\n")
+		for _, key := range sortedKeys(inst.Metadata) {
+			fmt.Printf("    %s=%s\n", key, inst.Metadata[key])
+		}
+	}
+}
+
+// selectorFlag parses the --selector flag of a command
+func selectorFlag(cmd *cobra.Command) (state.Selector, error) {
+	raw, _ := cmd.Flags().GetString("selector")
+	selector, err := state.ParseSelector(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --selector: %w", err)
+	}
+	return selector, nil
+}
+
+// addSelectorFlag registers the --selector flag shared by listing commands
+func addSelectorFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("selector", "l", "", "Only include instances matching tags and metadata, e.g. team=infra,ticket=OCW-12")
+}
+
+func init() {
+	tagCmd.Flags().StringSliceP("remove", "r", nil, "Tag to remove (repeatable or comma-separated)")
+	tagCmd.Flags().StringArray("meta", nil, "Metadata to set as key=value (repeatable)")
+	tagCmd.Flags().StringSlice("unset", nil, "Metadata key to remove (repeatable or comma-separated)")
+	rootCmd.AddCommand(tagCmd)
+}
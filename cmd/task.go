@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task <instance> [description]",
+	Short: "Show or replace the task of an instance",
+	Long: `Show the task description and notes of an instance, or replace the task.
+
+The task is shown in the dashboard preview and seeds the pull request title and
+body when merging.
+
+Examples:
+  ocw task feature-x
+  ocw task feature-x "Add rate limiting to the login endpoint"
+  ocw task feature-x --file TASK.md`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskFile, _ := cmd.Flags().GetString("file")
+
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		instanceID, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		var task string
+		switch {
+		case taskFile != "" && len(args) > 1:
+			return fmt.Errorf("give the task either as an argument or with --file, not both")
+		case taskFile != "":
+			task, err = readTaskFile(taskFile)
+			if err != nil {
+				return err
+			}
+		case len(args) > 1:
+			task = args[1]
+		default:
+			inst, err := mgr.GetInstance(instanceID)
+			if err != nil {
+				return err
+			}
+			printTask(inst)
+			return nil
+		}
+
+		inst, err := mgr.SetTask(instanceID, task)
+		if err != nil {
+			return fmt.Errorf("failed to set task: %w", err)
+		}
+
+		fmt.Printf("✓ Task of %s updated\n", inst.Name)
+		return nil
+	},
+}
+
+var noteCmd = &cobra.Command{
+	Use:   "note <instance> <text...>",
+	Short: "Append a note to an instance",
+	Long: `Append a timestamped note to the notes log of an instance.
+
+Notes are shown in the dashboard preview and listed in the pull request body
+when merging. Use 'ocw task <instance>' to read them back.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := loadManager()
+		if err != nil {
+			return err
+		}
+
+		instanceID, err := resolveInstanceID(mgr, args[0])
+		if err != nil {
+			return err
+		}
+
+		inst, err := mgr.AddNote(instanceID, strings.Join(args[1:], " "))
+		if err != nil {
+			return fmt.Errorf("failed to add note: %w", err)
+		}
+
+		fmt.Printf("✓ Note added to %s (%d notes)\n", inst.Name, len(inst.Notes))
+		return nil
+	},
+}
+
+// readTaskFile reads a task description or prompt from a file, or from stdin if path is "-"
+func readTaskFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	task := strings.TrimSpace(string(data))
+	if task == "" {
+		return "", fmt.Errorf("file %s is empty", path)
+	}
+	return task, nil
+}
+
+// printTask prints the task and notes of an instance
+func printTask(inst *state.Instance) {
+	if inst.Task == "" {
+		fmt.Printf("No task set for %s\n", inst.Name)
+	} else {
+		fmt.Printf("Task of %s:\n\n", inst.Name)
+		for _, line := range strings.Split(inst.Task, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	if len(inst.Notes) > 0 {
+		fmt.Printf("\nNotes:\n")
+		for _, note := range inst.Notes {
+			fmt.Printf("  %s  %s\n", note.Time.Format("2006-01-02 15:04"), note.Text)
+		}
+	}
+}
+
+func init() {
+	taskCmd.Flags().StringP("file", "f", "", "Read the task from a file (- for stdin)")
+	rootCmd.AddCommand(taskCmd)
+	rootCmd.AddCommand(noteCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/fsnotify/fsnotify"
@@ -20,6 +21,10 @@ type TaskDefinition struct {
 	Name   string `yaml:"name" json:"name"`
 	Branch string `yaml:"branch" json:"branch"`
 	Base   string `yaml:"base" json:"base"`
+	// Task is the description of the work, shown in the dashboard and used for the PR
+	Task string `yaml:"task" json:"task"`
+	// Prompt is sent to the agent once it is ready to accept input
+	Prompt string `yaml:"prompt" json:"prompt"`
 }
 
 // TaskFile represents the structure of the watch file
@@ -32,13 +37,19 @@ var watchCmd = &cobra.Command{
 	Short: "Watch a file for tasks and auto-create instances",
 	Long: `Watch a YAML or JSON file containing task definitions and automatically create instances.
 
-The file should contain a list of tasks with name, branch, and base fields:
+The file should contain a list of tasks with name, branch, base, an optional
+multi-line task description and an optional prompt, sent to the agent once it
+is ready to accept input:
 
 YAML format:
   tasks:
     - name: feature-1
       branch: feature/feature-1
       base: main
+      task: |
+        Add rate limiting to the login endpoint.
+        Use the existing middleware package.
+      prompt: Add rate limiting to the login endpoint using the middleware package.
     - name: bugfix-2
       branch: fix/bug-2
       base: production
@@ -46,7 +57,7 @@ YAML format:
 JSON format:
   {
     "tasks": [
-      {"name": "feature-1", "branch": "feature/feature-1", "base": "main"},
+      {"name": "feature-1", "branch": "feature/feature-1", "base": "main", "task": "Add rate limiting", "prompt": "Add rate limiting"},
       {"name": "bugfix-2", "branch": "fix/bug-2", "base": "production"}
     ]
   }
@@ -168,6 +179,8 @@ func processTasks(mgr *workspace.Manager, filePath string, cfg *config.Config) e
 			Name:       task.Name,
 			Branch:     task.Branch,
 			BaseBranch: task.Base,
+			Task:       task.Task,
+			Prompt:     strings.TrimSpace(task.Prompt),
 		}
 
 		if opts.BaseBranch == "" {
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AgentConfig is a coding-agent profile: how an agent CLI is launched in an
+// instance's primary pane, how prompts are submitted to it and how its pane
+// output and exit are read. A profile without a command leaves a plain shell.
+type AgentConfig struct {
+	Command      string   `toml:"command"`                 // launch command; {id}, {name}, {branch}, {model} and {provider} are replaced
+	Args         []string `toml:"args,omitempty"`          // appended to the command
+	Model        string   `toml:"model,omitempty"`         // passed with model_flag
+	ModelFlag    string   `toml:"model_flag,omitempty"`    // e.g. "--model"
+	Provider     string   `toml:"provider,omitempty"`      // passed with provider_flag
+	ProviderFlag string   `toml:"provider_flag,omitempty"` // e.g. "--provider"
+	Prompt       string   `toml:"prompt,omitempty"`        // how prompts are submitted: "type" (default) or "paste"
+	SubmitKey    string   `toml:"submit_key,omitempty"`    // tmux key sent after a prompt, default "Enter"
+	ReadyPattern string   `toml:"ready_pattern,omitempty"` // pane output once the agent accepts input
+	IdlePattern  string   `toml:"idle_pattern,omitempty"`  // pane output while the agent waits for input
+	OnExit       string   `toml:"on_exit,omitempty"`       // "exited" (default), "status" or "error"
+}
+
+// OpenCodeAgent is the built-in profile made from the [opencode] section.
+// Instances created before agent profiles existed ran it.
+const OpenCodeAgent = "opencode"
+
+// Prompt submission modes
+const (
+	// PromptType types the prompt as keystrokes
+	PromptType = "type"
+	// PromptPaste pastes the prompt as one bracketed paste, keeping newlines
+	// from submitting it early
+	PromptPaste = "paste"
+)
+
+// Exit semantics of an agent, i.e. the status of an instance whose agent exited
+const (
+	// ExitExited marks the instance exited whatever the exit status
+	ExitExited = "exited"
+	// ExitStatus marks it exited on exit status 0 and error otherwise
+	ExitStatus = "status"
+	// ExitError marks it error, for agents that are not meant to exit
+	ExitError = "error"
+)
+
+var (
+	promptModes = []string{PromptType, PromptPaste}
+	exitModes   = []string{ExitExited, ExitStatus, ExitError}
+	agentVars   = []string{"id", "name", "branch", "model", "provider"}
+)
+
+// PromptMode returns how prompts are submitted, defaulting to typing them
+func (a AgentConfig) PromptMode() string {
+	if a.Prompt == "" {
+		return PromptType
+	}
+	return a.Prompt
+}
+
+// Submit returns the key that submits a prompt
+func (a AgentConfig) Submit() string {
+	if a.SubmitKey == "" {
+		return "Enter"
+	}
+	return a.SubmitKey
+}
+
+// ExitMode returns the exit semantics, defaulting to ExitExited
+func (a AgentConfig) ExitMode() string {
+	if a.OnExit == "" {
+		return ExitExited
+	}
+	return a.OnExit
+}
+
+// agent returns the profile described by the [opencode] section
+func (o OpenCodeConfig) agent() AgentConfig {
+	return AgentConfig{
+		Command:      o.Command,
+		Args:         o.Args,
+		Model:        o.Model,
+		ModelFlag:    "--model",
+		Provider:     o.Provider,
+		ProviderFlag: "--provider",
+	}
+}
+
+// AgentNames returns the names of all agent profiles, sorted
+func (c *Config) AgentNames() []string {
+	names := []string{OpenCodeAgent}
+	for name := range c.Agents {
+		if name != OpenCodeAgent {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Agent returns the agent profile with the given name, or workspace.agent's
+// profile for an empty name. The opencode profile is made from the [opencode]
+// section unless [agents.opencode] replaces it.
+func (c *Config) Agent(name string) (AgentConfig, error) {
+	if name == "" {
+		name = c.Workspace.Agent
+	}
+	if agent, ok := c.Agents[name]; ok {
+		return agent, nil
+	}
+	if name == OpenCodeAgent {
+		return c.OpenCode.agent(), nil
+	}
+	return AgentConfig{}, fmt.Errorf("agent profile %q not found (available: %s)", name, strings.Join(c.AgentNames(), ", "))
+}
+
+// checkAgents reports invalid agent profiles and references to unknown ones
+func checkAgents(cfg *Config) []Problem {
+	var problems []Problem
+	fail := func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{Severity: SeverityError, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	names := cfg.AgentNames()
+	if !contains(names, cfg.Workspace.Agent) {
+		fail("workspace.agent", "%q is not an agent profile (available: %s)", cfg.Workspace.Agent, strings.Join(names, ", "))
+	} else if agent, err := cfg.Agent(""); err == nil && cfg.Workspace.Agent != OpenCodeAgent &&
+		strings.TrimSpace(agent.Command) != "" && !commandExists(agent.Command) {
+		problems = append(problems, Problem{
+			Severity: SeverityWarning,
+			Key:      "agents." + cfg.Workspace.Agent + ".command",
+			Message:  fmt.Sprintf("%q was not found in PATH", commandName(agent.Command)),
+		})
+	}
+
+	for _, name := range sortedTemplateNames(cfg.Workspace.Templates) {
+		if agent := cfg.Workspace.Templates[name].Agent; agent != "" && !contains(names, agent) {
+			fail("workspace.templates."+name+".agent", "%q is not an agent profile (available: %s)", agent, strings.Join(names, ", "))
+		}
+	}
+
+	for _, name := range names {
+		agent, ok := cfg.Agents[name]
+		if !ok {
+			continue
+		}
+		prefix := "agents." + name + "."
+		if agent.Prompt != "" && !contains(promptModes, agent.Prompt) {
+			fail(prefix+"prompt", "%q is not a prompt mode (use %s)", agent.Prompt, strings.Join(promptModes, " or "))
+		}
+		if agent.OnExit != "" && !contains(exitModes, agent.OnExit) {
+			fail(prefix+"on_exit", "%q is not an exit mode (use %s)", agent.OnExit, strings.Join(exitModes, ", "))
+		}
+		for _, field := range []struct{ key, pattern string }{
+			{"ready_pattern", agent.ReadyPattern},
+			{"idle_pattern", agent.IdlePattern},
+		} {
+			if field.pattern == "" {
+				continue
+			}
+			if _, err := regexp.Compile(field.pattern); err != nil {
+				fail(prefix+field.key, "invalid regular expression: %v", err)
+			}
+		}
+		for _, m := range placeholderPattern.FindAllStringSubmatch(agent.Command, -1) {
+			if m[0] != "{{" && !contains(agentVars, m[1]) {
+				fail(prefix+"command", "{%s} is not a placeholder (use %s)", m[1], strings.Join(agentVars, ", "))
+				break
+			}
+		}
+	}
+	return problems
+}
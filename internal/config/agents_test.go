@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents["aider"] = AgentConfig{Command: "aider"}
+
+	assert.Equal(t, []string{"aider", "opencode", "shell"}, cfg.AgentNames())
+
+	agent, err := cfg.Agent("")
+	require.NoError(t, err)
+	assert.Equal(t, "opencode", agent.Command)
+	assert.Equal(t, "claude-sonnet-4-5", agent.Model)
+	assert.Equal(t, "--model", agent.ModelFlag)
+
+	agent, err = cfg.Agent("aider")
+	require.NoError(t, err)
+	assert.Equal(t, "aider", agent.Command)
+	assert.Equal(t, PromptType, agent.PromptMode())
+	assert.Equal(t, "Enter", agent.Submit())
+	assert.Equal(t, ExitExited, agent.ExitMode())
+
+	// [agents.opencode] replaces the profile made from [opencode]
+	cfg.Agents[OpenCodeAgent] = AgentConfig{Command: "opencode --print-logs"}
+	agent, err = cfg.Agent(OpenCodeAgent)
+	require.NoError(t, err)
+	assert.Equal(t, "opencode --print-logs", agent.Command)
+	assert.Empty(t, agent.Model)
+
+	_, err = cfg.Agent("codex")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: aider, opencode, shell")
+}
+
+func TestCheckAgents(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"defaults", func(c *Config) {}, nil},
+		{"custom profile", func(c *Config) {
+			c.Agents["aider"] = AgentConfig{Command: "sh --model {model}", Prompt: PromptPaste, OnExit: ExitStatus, IdlePattern: `^> $`}
+			c.Workspace.Agent = "aider"
+		}, nil},
+		{"unknown default agent", func(c *Config) { c.Workspace.Agent = "codex" },
+			[]string{`workspace.agent: "codex" is not an agent profile (available: opencode, shell)`}},
+		{"unknown template agent", func(c *Config) { c.Workspace.Templates["feature"] = Template{Agent: "codex"} },
+			[]string{`workspace.templates.feature.agent: "codex" is not an agent profile (available: opencode, shell)`}},
+		{"bad modes", func(c *Config) { c.Agents["aider"] = AgentConfig{Command: "aider", Prompt: "stdin", OnExit: "restart"} },
+			[]string{
+				`agents.aider.prompt: "stdin" is not a prompt mode (use type or paste)`,
+				`agents.aider.on_exit: "restart" is not an exit mode (use exited, status, error)`,
+			}},
+		{"bad pattern", func(c *Config) { c.Agents["aider"] = AgentConfig{Command: "aider", ReadyPattern: "(>"} },
+			[]string{"agents.aider.ready_pattern: invalid regular expression: error parsing regexp: missing closing ): `(>`"}},
+		{"unknown placeholder", func(c *Config) { c.Agents["aider"] = AgentConfig{Command: "aider --branch {ticket}"} },
+			[]string{"agents.aider.command: {ticket} is not a placeholder (use id, name, branch, model, provider)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+
+			var got []string
+			for _, p := range checkAgents(cfg) {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadAgents(t *testing.T) {
+	_, repo := setupLayers(t)
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[workspace]
+agent = "aider"
+
+[agents.aider]
+command = "aider"
+model = "sonnet"
+model_flag = "--model"
+idle_pattern = '^> $'
+`), 0644))
+
+	cfg, err := LoadConfig(repo)
+	require.NoError(t, err)
+
+	agent, err := cfg.Agent("")
+	require.NoError(t, err)
+	assert.Equal(t, "sonnet", agent.Model)
+	assert.Equal(t, "^> $", agent.IdlePattern)
+
+	// The built-in shell profile is kept alongside
+	assert.Equal(t, []string{"aider", "opencode", "shell"}, cfg.AgentNames())
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// KeysConfig contains the TUI key bindings, one table per view. Each action
+// lists the keys that trigger it, named as bubbletea names them, e.g. "k",
+// "up", "ctrl+d", "pgdown" or "shift+tab". An empty list disables an action.
+// Global keys apply in every view; in views that take text input, only
+// global keys that do not type a character apply.
+type KeysConfig struct {
+	Global    GlobalKeys    `toml:"global"`
+	Dashboard DashboardKeys `toml:"dashboard"`
+	Diff      PagerKeys     `toml:"diff"`
+	Log       LogKeys       `toml:"log"`
+	Merge     MergeKeys     `toml:"merge"`
+	Create    FormKeys      `toml:"create"`
+	Help      PagerKeys     `toml:"help"`
+	FanOut    FanOutKeys    `toml:"fanout"`
+	Confirm   ConfirmKeys   `toml:"confirm"`
+}
+
+// GlobalKeys are active in every view
+type GlobalKeys struct {
+	Quit []string `toml:"quit"`
+	Help []string `toml:"help"`
+	Back []string `toml:"back"`
+}
+
+// DashboardKeys are the instance list's navigation and actions
+type DashboardKeys struct {
+	Up           []string `toml:"up"`
+	Down         []string `toml:"down"`
+	PageUp       []string `toml:"page_up"`
+	PageDown     []string `toml:"page_down"`
+	Top          []string `toml:"top"`
+	Bottom       []string `toml:"bottom"`
+	Focus        []string `toml:"focus"`
+	QuickFocus   []string `toml:"quick_focus"` // the nth key focuses the nth instance
+	New          []string `toml:"new"`
+	Delete       []string `toml:"delete"`
+	Diff         []string `toml:"diff"`
+	Merge        []string `toml:"merge"`
+	Logs         []string `toml:"logs"`
+	SendPrompt   []string `toml:"send_prompt"`
+	SubTerminals []string `toml:"sub_terminals"`
+	Refresh      []string `toml:"refresh"`
+	Filter       []string `toml:"filter"`
+	SwitchRepo   []string `toml:"switch_repo"`
+	FanOut       []string `toml:"fanout"` // compare the fan-out of the selected instance
+}
+
+// PagerKeys scroll the diff and help views
+type PagerKeys struct {
+	Up       []string `toml:"up"`
+	Down     []string `toml:"down"`
+	PageUp   []string `toml:"page_up"`
+	PageDown []string `toml:"page_down"`
+	Top      []string `toml:"top"`
+	Bottom   []string `toml:"bottom"`
+}
+
+// LogKeys scroll the log view and switch between pane and hook output
+type LogKeys struct {
+	Up          []string `toml:"up"`
+	Down        []string `toml:"down"`
+	PageUp      []string `toml:"page_up"`
+	PageDown    []string `toml:"page_down"`
+	Top         []string `toml:"top"`
+	Bottom      []string `toml:"bottom"`
+	ToggleHooks []string `toml:"toggle_hooks"`
+}
+
+// FormKeys move through the fields of the create form
+type FormKeys struct {
+	Next    []string `toml:"next"` // also submits on the last field
+	Prev    []string `toml:"prev"`
+	NewLine []string `toml:"new_line"`
+}
+
+// MergeKeys move through the pull request form and resolve conflicts
+type MergeKeys struct {
+	Next             []string `toml:"next"`
+	Prev             []string `toml:"prev"`
+	NewLine          []string `toml:"new_line"`
+	ResolveConflicts []string `toml:"resolve_conflicts"`
+}
+
+// FanOutKeys move through the siblings of a fan-out, test them and pick the winner
+type FanOutKeys struct {
+	Up   []string `toml:"up"`
+	Down []string `toml:"down"`
+	Test []string `toml:"test"`
+	Pick []string `toml:"pick"`
+}
+
+// ConfirmKeys answer the delete and pick confirmations
+type ConfirmKeys struct {
+	Yes []string `toml:"yes"`
+	No  []string `toml:"no"`
+}
+
+// defaultKeys returns the built-in key bindings
+func defaultKeys() KeysConfig {
+	return KeysConfig{
+		Global: GlobalKeys{
+			Quit: []string{"ctrl+c", "q"},
+			Help: []string{"?"},
+			Back: []string{"esc"},
+		},
+		Dashboard: DashboardKeys{
+			Up:           []string{"up", "k"},
+			Down:         []string{"down", "j"},
+			PageUp:       []string{"pgup"},
+			PageDown:     []string{"pgdown"},
+			Top:          []string{"home", "g"},
+			Bottom:       []string{"end", "G"},
+			Focus:        []string{"enter"},
+			QuickFocus:   []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"},
+			New:          []string{"n", "N"},
+			Delete:       []string{"d"},
+			Diff:         []string{"f"},
+			Merge:        []string{"m"},
+			Logs:         []string{"l"},
+			SendPrompt:   []string{"s"},
+			SubTerminals: []string{"t", "T"},
+			Refresh:      []string{"r"},
+			Filter:       []string{"/"},
+			SwitchRepo:   []string{"R"},
+			FanOut:       []string{"F"},
+		},
+		Diff: PagerKeys{
+			Up:       []string{"up", "k"},
+			Down:     []string{"down", "j"},
+			PageUp:   []string{"pgup"},
+			PageDown: []string{"pgdown"},
+			Top:      []string{"home"},
+			Bottom:   []string{"end"},
+		},
+		Log: LogKeys{
+			Up:          []string{"up", "k"},
+			Down:        []string{"down", "j"},
+			PageUp:      []string{"pgup"},
+			PageDown:    []string{"pgdown"},
+			Top:         []string{"home"},
+			Bottom:      []string{"end"},
+			ToggleHooks: []string{"h"},
+		},
+		Merge: MergeKeys{
+			Next:             []string{"enter", "tab"},
+			Prev:             []string{"shift+tab"},
+			NewLine:          []string{"alt+enter", "ctrl+j"},
+			ResolveConflicts: []string{"c"},
+		},
+		Create: FormKeys{
+			Next:    []string{"enter", "tab"},
+			Prev:    []string{"shift+tab"},
+			NewLine: []string{"alt+enter", "ctrl+j"},
+		},
+		Help: PagerKeys{
+			Up:       []string{"up", "k"},
+			Down:     []string{"down", "j"},
+			PageUp:   []string{"pgup"},
+			PageDown: []string{"pgdown"},
+			Top:      []string{"home", "g"},
+			Bottom:   []string{"end", "G"},
+		},
+		FanOut: FanOutKeys{
+			Up:   []string{"up", "k"},
+			Down: []string{"down", "j"},
+			Test: []string{"t"},
+			Pick: []string{"p"},
+		},
+		Confirm: ConfirmKeys{
+			Yes: []string{"y"},
+			No:  []string{"n", "N"},
+		},
+	}
+}
+
+// textInputViews take text input, so printable global keys don't apply in them
+var textInputViews = map[string]bool{"create": true, "merge": true}
+
+// Typing reports whether a key types a character rather than being a named
+// key such as "esc" or "ctrl+c"
+func Typing(key string) bool {
+	return len([]rune(key)) == 1
+}
+
+// checkKeys reports empty keys, unbound required actions and keys bound to
+// more than one action of a view, counting the global keys active in it
+func checkKeys(keys KeysConfig) []Problem {
+	var problems []Problem
+	fail := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityError, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(keys.Global.Quit) == 0 {
+		fail("keys.global.quit", "must not be empty")
+	}
+	if len(keys.Global.Back) == 0 {
+		fail("keys.global.back", "must not be empty")
+	}
+
+	global := keyActions(keys.Global, "keys.global")
+	v := reflect.ValueOf(keys)
+	for i := 0; i < v.NumField(); i++ {
+		section := tomlName(v.Type().Field(i))
+		var actions []keyAction
+		if section == "global" {
+			actions = global
+		} else {
+			for _, action := range global {
+				if !textInputViews[section] || !Typing(action.key) {
+					actions = append(actions, action)
+				}
+			}
+			actions = append(actions, keyActions(v.Field(i).Interface(), "keys."+section)...)
+		}
+
+		bound := map[string]string{}
+		for _, action := range actions {
+			if strings.TrimSpace(action.key) == "" {
+				if section == "global" || !strings.HasPrefix(action.name, "keys.global.") {
+					fail(action.name, "must not contain empty keys")
+				}
+				continue
+			}
+			other, taken := bound[action.key]
+			switch {
+			case !taken:
+				bound[action.key] = action.name
+			case other != action.name && (section == "global" || !strings.HasPrefix(action.name, "keys.global.")):
+				fail(action.name, "%q is already bound to %s", action.key, other)
+			}
+		}
+	}
+	return problems
+}
+
+// keyAction is one key of an action, e.g. "k" of keys.dashboard.up
+type keyAction struct {
+	name string
+	key  string
+}
+
+// keyActions lists the keys of every action in a section, in field order
+func keyActions(section any, prefix string) []keyAction {
+	var actions []keyAction
+	v := reflect.ValueOf(section)
+	for i := 0; i < v.NumField(); i++ {
+		name := joinKey(prefix, tomlName(v.Type().Field(i)))
+		for _, key := range v.Field(i).Interface().([]string) {
+			actions = append(actions, keyAction{name: name, key: key})
+		}
+	}
+	return actions
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*KeysConfig)
+		want   []string
+	}{
+		{"defaults", func(k *KeysConfig) {}, nil},
+		{"conflict within a view", func(k *KeysConfig) { k.Dashboard.Delete = []string{"n"} },
+			[]string{`keys.dashboard.delete: "n" is already bound to keys.dashboard.new`}},
+		{"conflict with a global key", func(k *KeysConfig) { k.Diff.Top = []string{"q"} },
+			[]string{`keys.diff.top: "q" is already bound to keys.global.quit`}},
+		{"conflict between global keys", func(k *KeysConfig) { k.Global.Help = []string{"esc"} },
+			[]string{`keys.global.back: "esc" is already bound to keys.global.help`}},
+		{"printable global keys type in forms", func(k *KeysConfig) { k.Create.NewLine = []string{"q"} }, nil},
+		{"named global keys apply in forms", func(k *KeysConfig) { k.Merge.Next = []string{"esc"} },
+			[]string{`keys.merge.next: "esc" is already bound to keys.global.back`}},
+		{"same key in different views", func(k *KeysConfig) { k.Log.ToggleHooks = []string{"d"} }, nil},
+		{"disabled action", func(k *KeysConfig) { k.Dashboard.SwitchRepo = []string{} }, nil},
+		{"empty quit", func(k *KeysConfig) { k.Global.Quit = nil },
+			[]string{"keys.global.quit: must not be empty"}},
+		{"empty key", func(k *KeysConfig) { k.Confirm.Yes = []string{" "} },
+			[]string{"keys.confirm.yes: must not contain empty keys"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := defaultKeys()
+			tt.modify(&keys)
+
+			var got []string
+			for _, p := range checkKeys(keys) {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	_, repo := setupLayers(t)
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[keys.dashboard]
+up = ["up", "e"]
+down = ["down", "n"]
+new = ["c"]
+`), 0644))
+
+	cfg, err := LoadConfig(repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"up", "e"}, cfg.Keys.Dashboard.Up)
+	assert.Equal(t, []string{"c"}, cfg.Keys.Dashboard.New)
+	// Actions that are not rebound keep their defaults
+	assert.Equal(t, []string{"d"}, cfg.Keys.Dashboard.Delete)
+}
+
+func TestLoadKeysConflict(t *testing.T) {
+	_, repo := setupLayers(t)
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[keys.dashboard]
+down = ["down", "n"]
+`), 0644))
+
+	_, _, err := LoadLayered(repo)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	// The default binding of new now conflicts with the rebound down
+	assert.Contains(t, err.Error(), `keys.dashboard.new: "n" is already bound to keys.dashboard.down`)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// BootstrapConfig lists git-ignored files, such as .env files, local
+// certificates and IDE settings, brought from the main checkout into every
+// new worktree. Files tracked by git are never touched.
+type BootstrapConfig struct {
+	MaxSizeKB int             `toml:"max_size_kb"` // largest file copied, 0 for no limit
+	Files     []BootstrapFile `toml:"files"`
+}
+
+// BootstrapFile is a path or glob to bootstrap and how to bring it over
+type BootstrapFile struct {
+	Pattern   string `toml:"pattern"`               // relative to the repository root, e.g. ".env*"
+	Mode      string `toml:"mode,omitempty"`        // "copy" (default), "symlink" or "reflink"
+	MaxSizeKB int    `toml:"max_size_kb,omitempty"` // replaces the default limit for this pattern
+}
+
+// Bootstrap modes
+const (
+	// BootstrapCopy copies files, each worktree getting its own
+	BootstrapCopy = "copy"
+	// BootstrapSymlink links to the main checkout, sharing one copy
+	BootstrapSymlink = "symlink"
+	// BootstrapReflink clones files copy-on-write where the filesystem
+	// supports it and copies them otherwise
+	BootstrapReflink = "reflink"
+)
+
+// BootstrapModes lists the valid bootstrap modes
+var BootstrapModes = []string{BootstrapCopy, BootstrapSymlink, BootstrapReflink}
+
+// ModeOrDefault returns how the files are brought over, defaulting to copying
+func (f BootstrapFile) ModeOrDefault() string {
+	if f.Mode == "" {
+		return BootstrapCopy
+	}
+	return f.Mode
+}
+
+// SizeLimit returns the largest file copied for the pattern in bytes, or 0
+// for no limit
+func (f BootstrapFile) SizeLimit(defaultKB int) int64 {
+	kb := defaultKB
+	if f.MaxSizeKB > 0 {
+		kb = f.MaxSizeKB
+	}
+	return int64(kb) * 1024
+}
+
+// checkBootstrap reports invalid bootstrap patterns, modes and limits
+func checkBootstrap(b BootstrapConfig) []Problem {
+	var problems []Problem
+	fail := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityError, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if b.MaxSizeKB < 0 {
+		fail("workspace.bootstrap.max_size_kb", "%d is out of range (must not be negative)", b.MaxSizeKB)
+	}
+	for _, file := range b.Files {
+		pattern := file.Pattern
+		switch {
+		case pattern == "" || filepath.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../"):
+			fail("workspace.bootstrap.files", "%q must be a path relative to the repository root", pattern)
+		case !validGlob(pattern):
+			fail("workspace.bootstrap.files", "%q is not a valid glob pattern", pattern)
+		}
+		if file.Mode != "" && !contains(BootstrapModes, file.Mode) {
+			fail("workspace.bootstrap.files", "%q has mode %q (use %s)", pattern, file.Mode, strings.Join(BootstrapModes, ", "))
+		}
+		if file.MaxSizeKB < 0 {
+			fail("workspace.bootstrap.files", "%q has max_size_kb %d (must not be negative)", pattern, file.MaxSizeKB)
+		}
+	}
+	return problems
+}
+
+// validGlob reports whether pattern is well-formed for filepath.Glob
+func validGlob(pattern string) bool {
+	_, err := filepath.Match(pattern, "")
+	return err == nil
+}
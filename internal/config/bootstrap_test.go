@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapFileDefaults(t *testing.T) {
+	file := BootstrapFile{Pattern: ".env"}
+	assert.Equal(t, BootstrapCopy, file.ModeOrDefault())
+	assert.Equal(t, int64(2048), file.SizeLimit(2))
+	assert.Equal(t, int64(0), file.SizeLimit(0), "0 means no limit")
+
+	file = BootstrapFile{Pattern: "certs", Mode: BootstrapReflink, MaxSizeKB: 5}
+	assert.Equal(t, BootstrapReflink, file.ModeOrDefault())
+	assert.Equal(t, int64(5120), file.SizeLimit(2))
+}
+
+func TestCheckBootstrap(t *testing.T) {
+	tests := []struct {
+		name      string
+		bootstrap BootstrapConfig
+		want      []string
+	}{
+		{"default", DefaultConfig().Workspace.Bootstrap, nil},
+		{"valid", BootstrapConfig{MaxSizeKB: 64, Files: []BootstrapFile{
+			{Pattern: ".env*"},
+			{Pattern: "certs/*.pem", Mode: BootstrapReflink, MaxSizeKB: 1024},
+			{Pattern: "node_modules", Mode: BootstrapSymlink},
+		}}, nil},
+		{"negative limit", BootstrapConfig{MaxSizeKB: -1},
+			[]string{"workspace.bootstrap.max_size_kb: -1 is out of range (must not be negative)"}},
+		{"absolute", BootstrapConfig{Files: []BootstrapFile{{Pattern: "/etc/hosts"}}},
+			[]string{`workspace.bootstrap.files: "/etc/hosts" must be a path relative to the repository root`}},
+		{"outside", BootstrapConfig{Files: []BootstrapFile{{Pattern: "../secrets"}}},
+			[]string{`workspace.bootstrap.files: "../secrets" must be a path relative to the repository root`}},
+		{"bad glob", BootstrapConfig{Files: []BootstrapFile{{Pattern: "cache/[a-"}}},
+			[]string{`workspace.bootstrap.files: "cache/[a-" is not a valid glob pattern`}},
+		{"unknown mode", BootstrapConfig{Files: []BootstrapFile{{Pattern: ".env", Mode: "hardlink"}}},
+			[]string{`workspace.bootstrap.files: ".env" has mode "hardlink" (use copy, symlink, reflink)`}},
+		{"negative file limit", BootstrapConfig{Files: []BootstrapFile{{Pattern: ".env", MaxSizeKB: -5}}},
+			[]string{`workspace.bootstrap.files: ".env" has max_size_kb -5 (must not be negative)`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range checkBootstrap(tt.bootstrap) {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadBootstrap(t *testing.T) {
+	_, repo := setupLayers(t)
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[workspace.bootstrap]
+max_size_kb = 512
+files = [
+  { pattern = ".env*" },
+  { pattern = "node_modules", mode = "symlink" },
+]
+`), 0644))
+
+	cfg, err := LoadConfig(repo)
+	require.NoError(t, err)
+
+	assert.Equal(t, 512, cfg.Workspace.Bootstrap.MaxSizeKB)
+	assert.Equal(t, []BootstrapFile{
+		{Pattern: ".env*"},
+		{Pattern: "node_modules", Mode: BootstrapSymlink},
+	}, cfg.Workspace.Bootstrap.Files)
+}
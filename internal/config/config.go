@@ -10,18 +10,43 @@ import (
 
 // Config represents the complete OCW configuration
 type Config struct {
-	Workspace WorkspaceConfig `toml:"workspace"`
-	OpenCode  OpenCodeConfig  `toml:"opencode"`
-	Editor    EditorConfig    `toml:"editor"`
-	Merge     MergeConfig     `toml:"merge"`
-	Tmux      TmuxConfig      `toml:"tmux"`
-	UI        UIConfig        `toml:"ui"`
+	Workspace WorkspaceConfig        `toml:"workspace"`
+	OpenCode  OpenCodeConfig         `toml:"opencode"`
+	Agents    map[string]AgentConfig `toml:"agents"`
+	Editor    EditorConfig           `toml:"editor"`
+	Merge     MergeConfig            `toml:"merge"`
+	Tmux      TmuxConfig             `toml:"tmux"`
+	UI        UIConfig               `toml:"ui"`
+	Hooks     HooksConfig            `toml:"hooks"`
+	Ports     PortsConfig            `toml:"ports"`
+	FanOut    FanOutConfig           `toml:"fanout"`
+	Keys      KeysConfig             `toml:"keys"`
 }
 
-// Template defines a predefined starting point for new instances
+// Template defines a predefined starting point for new instances. Every field
+// is optional; unset fields fall back to the workspace settings and the agent
+// profile.
 type Template struct {
-	BaseBranch  string `toml:"base_branch"`
-	InitCommand string `toml:"init_command"`
+	BaseBranch    string            `toml:"base_branch"`
+	InitCommand   string            `toml:"init_command"`
+	BranchPattern string            `toml:"branch_pattern,omitempty"` // e.g. "feature/{ticket}-{title|slug}"
+	NamePattern   string            `toml:"name_pattern,omitempty"`   // display name, e.g. "{ticket}"
+	Vars          []Var             `toml:"vars,omitempty"`           // variables filling the patterns and prompt
+	Agent         string            `toml:"agent,omitempty"`          // agent profile instead of workspace.agent
+	Command       string            `toml:"command,omitempty"`        // command instead of the agent profile's
+	Model         string            `toml:"model,omitempty"`
+	Args          []string          `toml:"args,omitempty"`
+	Env           map[string]string `toml:"env,omitempty"`    // exported into the instance's panes
+	Layout        []Pane            `toml:"layout,omitempty"` // sub-terminals opened after the agent starts
+	Files         []string          `toml:"files,omitempty"`  // paths or globs copied from the main checkout
+	Prompt        string            `toml:"prompt,omitempty"` // sent to the agent once it is started
+	Tags          []string          `toml:"tags,omitempty"`
+}
+
+// Pane is a sub-terminal of a template layout
+type Pane struct {
+	Label   string `toml:"label"`
+	Command string `toml:"command"`
 }
 
 // WorkspaceConfig contains worktree-related settings
@@ -29,10 +54,14 @@ type WorkspaceConfig struct {
 	WorktreeDir            string              `toml:"worktree_dir"`
 	BaseBranch             string              `toml:"base_branch"`
 	SubTerminalInitCommand string              `toml:"sub_terminal_init_command"`
+	Agent                  string              `toml:"agent"` // default agent profile
+	StashOnDelete          bool                `toml:"stash_on_delete"`
+	Bootstrap              BootstrapConfig     `toml:"bootstrap"` // git-ignored files brought into new worktrees
 	Templates              map[string]Template `toml:"templates"`
 }
 
-// OpenCodeConfig contains OpenCode CLI settings
+// OpenCodeConfig contains OpenCode CLI settings. They make up the built-in
+// opencode agent profile.
 type OpenCodeConfig struct {
 	Command  string   `toml:"command"`
 	Args     []string `toml:"args"`
@@ -63,13 +92,86 @@ type TmuxConfig struct {
 	PrimaryPaneRatio int    `toml:"primary_pane_ratio"`
 }
 
-// UIConfig contains UI display settings
+// UIConfig contains UI display settings. Colors are turned off when the
+// NO_COLOR environment variable is set.
 type UIConfig struct {
-	ShowElapsedTime      bool `toml:"show_elapsed_time"`
-	ShowLastOutput       bool `toml:"show_last_output"`
-	ShowSubTerminalCount bool `toml:"show_sub_terminal_count"`
-	ShowConflictWarnings bool `toml:"show_conflict_warnings"`
-	MaxInstances         int  `toml:"max_instances"`
+	ShowElapsedTime      bool        `toml:"show_elapsed_time"`
+	ShowLastOutput       bool        `toml:"show_last_output"`
+	ShowSubTerminalCount bool        `toml:"show_sub_terminal_count"`
+	ShowConflictWarnings bool        `toml:"show_conflict_warnings"`
+	MaxInstances         int         `toml:"max_instances"`
+	ASCII                bool        `toml:"ascii"` // plain ASCII icons and borders instead of Unicode symbols
+	Theme                ThemeConfig `toml:"theme"`
+}
+
+// HooksConfig contains commands run at points of an instance's life. Each
+// command runs with sh -c in the instance's worktree (the repository root
+// before the worktree exists or after it is removed). A failing pre_* hook
+// aborts the operation; post_* failures are only logged.
+type HooksConfig struct {
+	TimeoutSeconds int      `toml:"timeout_seconds"`
+	PreCreate      []string `toml:"pre_create"`
+	PostCreate     []string `toml:"post_create"`
+	PreDelete      []string `toml:"pre_delete"`
+	PostDelete     []string `toml:"post_delete"`
+	PrePause       []string `toml:"pre_pause"`
+	PostPause      []string `toml:"post_pause"`
+	PrePush        []string `toml:"pre_push"`
+	PostPush       []string `toml:"post_push"`
+	PreMerge       []string `toml:"pre_merge"` // around opening the pull request
+	PostMerge      []string `toml:"post_merge"`
+}
+
+// PortsConfig controls the ports handed to instances, e.g. for dev servers.
+// Each new instance gets per_instance ports between start and end that no
+// other instance holds and nothing listens on.
+type PortsConfig struct {
+	Start       int `toml:"start"`
+	End         int `toml:"end"`
+	PerInstance int `toml:"per_instance"` // 0 disables port allocation
+}
+
+// FanOutConfig controls how the siblings of a fan-out are compared. The test
+// command runs with sh -c in each sibling's worktree; exit status 0 passes.
+type FanOutConfig struct {
+	TestCommand        string `toml:"test_command"` // e.g. "go test ./..."; empty skips testing
+	TestTimeoutSeconds int    `toml:"test_timeout_seconds"`
+}
+
+// HookNames lists the hooks in lifecycle order
+var HookNames = []string{
+	"pre_create", "post_create",
+	"pre_delete", "post_delete",
+	"pre_pause", "post_pause",
+	"pre_push", "post_push",
+	"pre_merge", "post_merge",
+}
+
+// Commands returns the commands configured for the named hook
+func (h HooksConfig) Commands(hook string) []string {
+	switch hook {
+	case "pre_create":
+		return h.PreCreate
+	case "post_create":
+		return h.PostCreate
+	case "pre_delete":
+		return h.PreDelete
+	case "post_delete":
+		return h.PostDelete
+	case "pre_pause":
+		return h.PrePause
+	case "post_pause":
+		return h.PostPause
+	case "pre_push":
+		return h.PrePush
+	case "post_push":
+		return h.PostPush
+	case "pre_merge":
+		return h.PreMerge
+	case "post_merge":
+		return h.PostMerge
+	}
+	return nil
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -79,6 +181,11 @@ func DefaultConfig() *Config {
 			WorktreeDir:            ".worktrees",
 			BaseBranch:             "master",
 			SubTerminalInitCommand: "",
+			Agent:                  OpenCodeAgent,
+			StashOnDelete:          true,
+			Bootstrap: BootstrapConfig{
+				MaxSizeKB: 10240,
+			},
 			Templates: map[string]Template{
 				"feature": {
 					BaseBranch:  "main",
@@ -96,6 +203,9 @@ func DefaultConfig() *Config {
 			Model:    "claude-sonnet-4-5",
 			Provider: "Sisyphus",
 		},
+		Agents: map[string]AgentConfig{
+			"shell": {OnExit: ExitStatus},
+		},
 		Editor: EditorConfig{
 			Command:         "",
 			TerminalEditors: []string{"nvim", "vim", "nano", "emacs"},
@@ -119,44 +229,161 @@ func DefaultConfig() *Config {
 			ShowSubTerminalCount: true,
 			ShowConflictWarnings: true,
 			MaxInstances:         10,
+			Theme:                ThemeConfig{Name: "dark"},
+		},
+		Hooks: HooksConfig{
+			TimeoutSeconds: 300,
+		},
+		Ports: PortsConfig{
+			Start:       3100,
+			End:         3999,
+			PerInstance: 1,
 		},
+		FanOut: FanOutConfig{
+			TestTimeoutSeconds: 600,
+		},
+		Keys: defaultKeys(),
 	}
 }
 
-// LoadConfig reads the config.toml file from the specified directory.
-// Missing fields are filled with defaults from DefaultConfig().
+// LoadConfig returns the effective configuration for the repository in dir.
+// Layers are applied in order, later ones winning: built-in defaults, the user
+// config (~/.config/ocw/config.toml), the repo config (.ocw/config.toml), the
+// git-ignored local config (.ocw/config.local.toml), OCW_* environment
+// variables and --set overrides. See LoadLayered for the origin of each value.
 func LoadConfig(dir string) (*Config, error) {
-	configPath := filepath.Join(dir, ".ocw", "config.toml")
+	cfg, _, err := LoadLayered(dir)
+	return cfg, err
+}
 
-	// Start with defaults
-	cfg := DefaultConfig()
+// SaveConfig writes the config to config.toml in the specified directory. A
+// new file is written in full; an existing one is edited in place by SaveFile.
+func SaveConfig(dir string, cfg *Config) error {
+	path := RepoConfigPath(dir)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return writeNewFile(path, cfg)
+	}
+	return SaveFile(path, cfg)
+}
 
-	// Check if config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Return defaults if file doesn't exist
+// LoadFile decodes a single config file over the built-in defaults, without
+// the other layers. A missing file yields the defaults.
+func LoadFile(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return cfg, nil
 	}
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveFile writes cfg to the config file at path by editing it in place: only
+// values that differ from what the file yields today are written, so comments
+// and key order survive. A missing file is treated as empty. Keys listed in
+// pin are written even if unchanged, e.g. to override a lower layer with a
+// value that equals the default.
+func SaveFile(path string, cfg *Config, pin ...string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	current := DefaultConfig()
+	if _, err := toml.Decode(string(data), current); err != nil {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	doc := parseDocument(string(data))
+	wanted := map[string]bool{}
+	for _, key := range Keys(cfg) {
+		wanted[key] = true
+		value, _ := Get(cfg, key)
+		existing, err := Get(current, key)
+		if err == nil && existing == value && !contains(pin, key) {
+			continue
+		}
+		// Fields of a new map entry that are left empty need not be written
+		if v, _ := lookup(cfg, key); err != nil && v.IsZero() && !contains(pin, key) {
+			continue
+		}
+		doc.set(key, value)
+	}
+
+	// Map entries that are gone from cfg, e.g. a deleted template, are removed
+	for _, key := range Keys(current) {
+		if wanted[key] {
+			continue
+		}
+		table, _ := splitKey(key)
+		doc.remove(table)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// SetValue parses raw according to the type of the dotted key and writes it to
+// the config file at path. The value is rejected if it fails validation.
+func SetValue(path, key, raw string) (*Config, error) {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Set(cfg, key, raw); err != nil {
+		return nil, err
+	}
 
-	// Read and decode TOML
-	if _, err := toml.DecodeFile(configPath, cfg); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	for _, p := range Check(cfg, nil) {
+		if p.Key == key && p.Severity == SeverityError {
+			return nil, fmt.Errorf("invalid value for %s: %s", key, p.Message)
+		}
 	}
 
+	if err := SaveFile(path, cfg, key); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
 	return cfg, nil
 }
 
-// SaveConfig writes the config to config.toml in the specified directory
-func SaveConfig(dir string, cfg *Config) error {
-	configPath := filepath.Join(dir, ".ocw", "config.toml")
+// UnsetValue removes a key or table from the config file at path, so that
+// lower layers apply again. It reports whether the key was set in the file.
+func UnsetValue(path, key string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	doc := parseDocument(string(data))
+	if !doc.remove(key) {
+		return false, nil
+	}
+
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return false, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return true, nil
+}
 
-	// Ensure .ocw directory exists
-	ocwDir := filepath.Join(dir, ".ocw")
-	if err := os.MkdirAll(ocwDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .ocw directory: %w", err)
+// writeNewFile encodes the complete config into a new file
+func writeNewFile(path string, cfg *Config) error {
+	// Ensure the parent directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	// Create config file
-	f, err := os.Create(configPath)
+	f, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
@@ -83,6 +83,7 @@ func TestConfigTOMLRoundtrip(t *testing.T) {
 }
 
 func TestLoadConfigNonexistent(t *testing.T) {
+	isolateUserConfig(t)
 	tmpDir := t.TempDir()
 
 	// Load config from directory without config file
@@ -96,6 +97,7 @@ func TestLoadConfigNonexistent(t *testing.T) {
 }
 
 func TestSaveAndLoadConfig(t *testing.T) {
+	isolateUserConfig(t)
 	tmpDir := t.TempDir()
 
 	// Create a custom config
@@ -133,6 +135,7 @@ func TestSaveAndLoadConfig(t *testing.T) {
 }
 
 func TestLoadConfigPartialOverride(t *testing.T) {
+	isolateUserConfig(t)
 	tmpDir := t.TempDir()
 
 	// Create .ocw directory
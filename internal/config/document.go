@@ -0,0 +1,292 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// document is a TOML config file kept as lines, so single values can be
+// changed without losing comments, blank lines or key order. It understands
+// the subset of TOML used for ocw config files: [table] headers, key = value
+// lines and multi-line strings and arrays.
+type document struct {
+	lines   []string
+	headers map[string]int  // table name -> index of its header line
+	entries map[string]span // dotted key -> lines of its key = value entry
+}
+
+// span is an inclusive range of line indexes
+type span struct {
+	start, end int
+}
+
+func parseDocument(data string) *document {
+	d := &document{lines: strings.Split(strings.TrimSuffix(data, "\n"), "\n")}
+	if data == "" {
+		d.lines = nil
+	}
+	d.index()
+	return d
+}
+
+// index records the position of every table header and key
+func (d *document) index() {
+	d.headers = map[string]int{}
+	d.entries = map[string]span{}
+
+	table := ""
+	for i := 0; i < len(d.lines); i++ {
+		text := strings.TrimSpace(d.lines[i])
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			header, _, _ := strings.Cut(strings.TrimLeft(text, "["), "]")
+			table = normalizeKey(header)
+			if _, ok := d.headers[table]; !ok {
+				d.headers[table] = i
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			continue
+		}
+		key := joinKey(table, normalizeKey(name))
+		end := i + valueLines(d.lines[i+1:], strings.TrimSpace(value))
+		if _, ok := d.entries[key]; !ok {
+			d.entries[key] = span{start: i, end: end}
+		}
+		i = end
+	}
+}
+
+// valueLines returns how many of the following lines belong to a value that
+// starts a multi-line string or array
+func valueLines(rest []string, value string) int {
+	for _, delim := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(value, delim) && strings.Count(value, delim) == 1 {
+			for n, line := range rest {
+				if strings.Contains(line, delim) {
+					return n + 1
+				}
+			}
+			return len(rest)
+		}
+	}
+
+	if strings.HasPrefix(value, "[") {
+		depth := bracketDepth(value)
+		for n := 0; depth > 0 && n < len(rest); n++ {
+			depth += bracketDepth(rest[n])
+			if depth <= 0 {
+				return n + 1
+			}
+		}
+	}
+
+	return 0
+}
+
+// bracketDepth returns the change in array nesting on a line, ignoring
+// brackets inside strings and comments
+func bracketDepth(line string) int {
+	depth := 0
+	code, _ := splitComment(line)
+	inString := byte(0)
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inString != 0:
+			if c == '\\' && inString == '"' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// splitComment splits a line into its code and a trailing # comment
+func splitComment(line string) (code, comment string) {
+	inString := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString != 0:
+			if c == '\\' && inString == '"' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '#':
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
+
+// has reports whether key is set in the document, as a value or a table
+func (d *document) has(key string) bool {
+	if _, ok := d.entries[key]; ok {
+		return true
+	}
+	_, ok := d.headers[key]
+	return ok
+}
+
+// set replaces the value of key, or adds it to its table. The table is
+// appended to the document if it does not exist yet.
+func (d *document) set(key, value string) {
+	if s, ok := d.entries[key]; ok {
+		line := d.lines[s.start]
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		name, _, _ := strings.Cut(strings.TrimSpace(line), "=")
+		replacement := indent + strings.TrimSpace(name) + " = " + value
+
+		// A comment after a single-line value is kept
+		if s.start == s.end {
+			if _, comment := splitComment(line); comment != "" {
+				replacement += " " + comment
+			}
+		}
+
+		d.replace(s.start, s.end, replacement)
+		return
+	}
+
+	table, name := splitKey(key)
+	entry := name + " = " + value
+	if header, ok := d.headers[table]; ok {
+		d.replace(d.sectionEnd(header)+1, d.sectionEnd(header), entry)
+		return
+	}
+
+	var added []string
+	if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+		added = append(added, "")
+	}
+	if table != "" {
+		added = append(added, "["+table+"]")
+	}
+	added = append(added, entry)
+	d.lines = append(d.lines, added...)
+	d.index()
+}
+
+// remove deletes key and reports whether it was set. A table is removed
+// together with its values and sub-tables.
+func (d *document) remove(key string) bool {
+	if s, ok := d.entries[key]; ok {
+		d.replace(s.start, s.end)
+		return true
+	}
+
+	if _, ok := d.headers[key]; !ok {
+		return false
+	}
+
+	// Remove the table and every sub-table, from the last one back
+	var starts []int
+	for table, line := range d.headers {
+		if table == key || strings.HasPrefix(table, key+".") {
+			starts = append(starts, line)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(starts)))
+	for _, start := range starts {
+		end := d.sectionEnd(start)
+		// Take the blank lines separating the table from the next one as well
+		for end+1 < len(d.lines) && strings.TrimSpace(d.lines[end+1]) == "" {
+			end++
+		}
+		d.lines = append(d.lines[:start], d.lines[end+1:]...)
+	}
+	d.index()
+	return true
+}
+
+// sectionEnd returns the last non-blank line of the table whose header is at index header
+func (d *document) sectionEnd(header int) int {
+	end := header
+	for i := header + 1; i < len(d.lines); i++ {
+		text := strings.TrimSpace(d.lines[i])
+		if strings.HasPrefix(text, "[") && !d.insideValue(i) {
+			break
+		}
+		if text != "" {
+			end = i
+		}
+	}
+	return end
+}
+
+// insideValue reports whether line i continues a multi-line value
+func (d *document) insideValue(i int) bool {
+	for _, s := range d.entries {
+		if i > s.start && i <= s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// replace replaces lines start..end (inclusive) with lines. An end before
+// start inserts the lines at start.
+func (d *document) replace(start, end int, lines ...string) {
+	updated := make([]string, 0, len(d.lines)+len(lines))
+	updated = append(updated, d.lines[:start]...)
+	updated = append(updated, lines...)
+	updated = append(updated, d.lines[end+1:]...)
+	d.lines = updated
+	d.index()
+}
+
+func (d *document) String() string {
+	text := strings.TrimRight(strings.Join(d.lines, "\n"), "\n")
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
+
+// keyLines maps the dotted keys and table headers of a TOML file to their line numbers
+func keyLines(data string) map[string]int {
+	d := parseDocument(data)
+	lines := map[string]int{}
+	for table, i := range d.headers {
+		lines[table] = i + 1
+	}
+	for key, s := range d.entries {
+		lines[key] = s.start + 1
+	}
+	return lines
+}
+
+// splitKey splits a dotted key into its table and name
+func splitKey(key string) (table, name string) {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// normalizeKey turns a possibly quoted, dotted TOML key into its dotted form
+func normalizeKey(raw string) string {
+	parts := strings.Split(raw, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commentedConfig = `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "claude-sonnet-4-5" # keep in sync with CI
+args = [
+  "--verbose", # noisy
+]
+
+[tmux]
+primary_pane_ratio = 70
+
+[workspace.templates.hotfix]
+base_branch = "production"
+`
+
+func TestDocumentSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name:  "replaces a value and keeps its comment",
+			key:   "opencode.model",
+			value: `"gpt-5"`,
+			want: `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "gpt-5" # keep in sync with CI
+args = [
+  "--verbose", # noisy
+]
+
+[tmux]
+primary_pane_ratio = 70
+
+[workspace.templates.hotfix]
+base_branch = "production"
+`,
+		},
+		{
+			name:  "replaces a multi-line array",
+			key:   "opencode.args",
+			value: `["--fast"]`,
+			want: `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "claude-sonnet-4-5" # keep in sync with CI
+args = ["--fast"]
+
+[tmux]
+primary_pane_ratio = 70
+
+[workspace.templates.hotfix]
+base_branch = "production"
+`,
+		},
+		{
+			name:  "adds a key to the end of its table",
+			key:   "tmux.default_split",
+			value: `"vertical"`,
+			want: `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "claude-sonnet-4-5" # keep in sync with CI
+args = [
+  "--verbose", # noisy
+]
+
+[tmux]
+primary_pane_ratio = 70
+default_split = "vertical"
+
+[workspace.templates.hotfix]
+base_branch = "production"
+`,
+		},
+		{
+			name:  "appends a missing table",
+			key:   "workspace.templates.docs.base_branch",
+			value: `"main"`,
+			want: commentedConfig + `
+[workspace.templates.docs]
+base_branch = "main"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDocument(commentedConfig)
+			doc.set(tt.key, tt.value)
+			assert.Equal(t, tt.want, doc.String())
+		})
+	}
+}
+
+func TestDocumentRemove(t *testing.T) {
+	doc := parseDocument(commentedConfig)
+
+	assert.True(t, doc.remove("opencode.args"))
+	assert.True(t, doc.remove("workspace.templates.hotfix"))
+	assert.False(t, doc.remove("tmux.default_split"))
+
+	assert.Equal(t, `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "claude-sonnet-4-5" # keep in sync with CI
+
+[tmux]
+primary_pane_ratio = 70
+`, doc.String())
+}
+
+func TestSaveConfigPreservesComments(t *testing.T) {
+	dir := t.TempDir()
+	path := RepoConfigPath(dir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(commentedConfig), 0644))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	cfg.Merge.DraftPR = true
+	cfg.OpenCode.Model = "gpt-5"
+	delete(cfg.Workspace.Templates, "hotfix")
+
+	require.NoError(t, SaveConfig(dir, cfg))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Team defaults
+[opencode]
+# Pinned for reproducible runs
+model = "gpt-5" # keep in sync with CI
+args = [
+  "--verbose", # noisy
+]
+
+[tmux]
+primary_pane_ratio = 70
+
+[merge]
+draft_pr = true
+`, string(data))
+}
+
+func TestSetValueWritesValidTOMLStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ocw", LocalConfigFile)
+
+	command := "printf '\x1b[1m\a' \"$OCW_BRANCH\"\tdone\\ ✓"
+	_, err := SetValue(path, "editor.command", command)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[editor]\ncommand = \"printf '\\u001B[1m\\u0007' \\\"$OCW_BRANCH\\\"\\tdone\\\\ ✓\"\n", string(data))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, command, cfg.Editor.Command)
+}
+
+func TestSetValueAndUnsetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ocw", LocalConfigFile)
+
+	// A value equal to the default is still written, so it overrides lower layers
+	_, err := SetValue(path, "merge.draft_pr", "false")
+	require.NoError(t, err)
+	cfg, err := SetValue(path, "ui.max_instances", "3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.UI.MaxInstances)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[merge]\ndraft_pr = false\n\n[ui]\nmax_instances = 3\n", string(data))
+
+	_, err = SetValue(path, "tmux.primary_pane_ratio", "150")
+	assert.ErrorContains(t, err, "out of range")
+	_, err = SetValue(path, "ui.max_instances", "many")
+	assert.ErrorContains(t, err, "not an integer")
+	_, err = SetValue(path, "tmux.nope", "1")
+	assert.ErrorContains(t, err, "unknown key")
+
+	removed, err := UnsetValue(path, "merge.draft_pr")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = UnsetValue(path, "merge.draft_pr")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[merge]\n\n[ui]\nmax_instances = 3\n", string(data))
+}
@@ -1,15 +1,19 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/tommyzliu/ocw/internal/state"
 )
 
-// InitWorkspace creates the .ocw directory with default config.toml and empty state.json
+// InitWorkspace creates the .ocw directory with a skeleton config.toml and
+// empty state.json
 func InitWorkspace(repoRoot string) error {
 	ocwDir := filepath.Join(repoRoot, ".ocw")
 
@@ -18,15 +22,27 @@ func InitWorkspace(repoRoot string) error {
 		return fmt.Errorf("failed to create .ocw directory: %w", err)
 	}
 
-	// Write default config.toml
-	defaultConfig := DefaultConfig()
-	if err := SaveConfig(repoRoot, defaultConfig); err != nil {
-		return fmt.Errorf("failed to save default config: %w", err)
+	// Write config.toml. Only the repo-specific keys are set, so that the
+	// user config still applies to everything else.
+	skeleton, err := configSkeleton(DefaultConfig())
+	if err != nil {
+		return fmt.Errorf("failed to encode default config: %w", err)
+	}
+	if err := os.WriteFile(RepoConfigPath(repoRoot), []byte(skeleton), 0644); err != nil {
+		return fmt.Errorf("failed to write config.toml: %w", err)
+	}
+
+	// Keep personal overrides out of git even if .ocw itself is committed
+	gitignorePath := filepath.Join(ocwDir, ".gitignore")
+	if err := os.WriteFile(gitignorePath, []byte(LocalConfigFile+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write .ocw/.gitignore: %w", err)
 	}
 
 	// Write empty state.json
 	emptyState := &state.State{
-		Instances: []state.Instance{},
+		SchemaVersion: state.CurrentSchemaVersion,
+		Instances:     []state.Instance{},
+		Archive:       []state.ArchivedInstance{},
 	}
 
 	statePath := filepath.Join(ocwDir, "state.json")
@@ -41,3 +57,44 @@ func InitWorkspace(repoRoot string) error {
 
 	return nil
 }
+
+// skeletonKeys are the keys ocw init sets in a new repo config
+var skeletonKeys = []string{"workspace.worktree_dir", "workspace.base_branch"}
+
+// configSkeleton renders cfg as a config file in which only skeletonKeys are
+// set and every other value is commented out for reference
+func configSkeleton(cfg *Config) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("# OCW repo config. Values set here override ~/.config/ocw/config.toml;\n")
+	b.WriteString("# uncomment a default below to change it for everyone using this repo.\n\n")
+
+	table := ""
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		text := strings.TrimSpace(line)
+		switch {
+		case text == "":
+			b.WriteString("\n")
+			continue
+		case strings.HasPrefix(text, "["):
+			header, _, _ := strings.Cut(strings.TrimLeft(text, "["), "]")
+			table = normalizeKey(header)
+			if table == "workspace" {
+				b.WriteString(line + "\n")
+				continue
+			}
+		default:
+			name, _, _ := strings.Cut(text, "=")
+			if contains(skeletonKeys, joinKey(table, normalizeKey(name))) {
+				b.WriteString(line + "\n")
+				continue
+			}
+		}
+		b.WriteString("# " + line + "\n")
+	}
+	return b.String(), nil
+}
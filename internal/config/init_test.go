@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitWorkspaceKeepsUserConfig(t *testing.T) {
+	userPath, _ := setupLayers(t)
+	repo := t.TempDir()
+
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+[opencode]
+model = "gpt-5"
+
+[editor]
+command = "nvim"
+`), 0644))
+
+	require.NoError(t, InitWorkspace(repo))
+	assert.FileExists(t, filepath.Join(repo, ".ocw", "state.json"))
+
+	cfg, origins, err := LoadLayered(repo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-5", cfg.OpenCode.Model)
+	assert.Equal(t, LayerUser, origins["opencode.model"].Layer)
+	assert.Equal(t, "nvim", cfg.Editor.Command)
+	assert.Equal(t, LayerUser, origins["editor.command"].Layer)
+
+	assert.Equal(t, DefaultConfig().Workspace.BaseBranch, cfg.Workspace.BaseBranch)
+	assert.Equal(t, LayerRepo, origins["workspace.base_branch"].Layer)
+	assert.Equal(t, LayerRepo, origins["workspace.worktree_dir"].Layer)
+	assert.Equal(t, LayerDefault, origins["tmux.session_prefix"].Layer)
+}
+
+func TestConfigSkeletonDocumentsDefaults(t *testing.T) {
+	skeleton, err := configSkeleton(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Contains(t, skeleton, "\n[workspace]\n")
+	assert.Contains(t, skeleton, "\n  worktree_dir = \".worktrees\"\n")
+	assert.Contains(t, skeleton, "\n#   stash_on_delete = true\n")
+	assert.Contains(t, skeleton, "\n# [opencode]\n")
+
+	// Setting a commented-out key adds it rather than editing the comment
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(skeleton), 0644))
+	_, err = SetValue(path, "opencode.model", "gpt-5")
+	require.NoError(t, err)
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-5", cfg.OpenCode.Model)
+}
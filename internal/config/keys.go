@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys returns the dotted keys of every value in cfg, e.g. "opencode.model",
+// in declaration order. Map entries such as templates are expanded per entry.
+func Keys(cfg *Config) []string {
+	var keys []string
+	walkValues(reflect.ValueOf(cfg).Elem(), "", func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Get returns the value of a dotted key formatted as TOML
+func Get(cfg *Config, key string) (string, error) {
+	v, ok := lookup(cfg, key)
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+	return formatValue(v), nil
+}
+
+// lookup returns the leaf value of a dotted key
+func lookup(cfg *Config, key string) (reflect.Value, bool) {
+	var found reflect.Value
+	ok := false
+	walkValues(reflect.ValueOf(cfg).Elem(), "", func(k string, v reflect.Value) {
+		if k == key {
+			found, ok = v, true
+		}
+	})
+	return found, ok
+}
+
+// Set parses raw according to the type of the dotted key and stores it in cfg.
+// Lists are given comma-separated. Map entries are created as needed, e.g.
+// "workspace.templates.docs.base_branch" adds a "docs" template.
+func Set(cfg *Config, key, raw string) error {
+	path := strings.Split(key, ".")
+	if err := setPath(reflect.ValueOf(cfg).Elem(), path, raw); err != nil {
+		return fmt.Errorf("cannot set %s: %w", key, err)
+	}
+	return nil
+}
+
+// walkValues calls fn for every leaf value below v
+func walkValues(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := tomlName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			walkValues(v.Field(i), joinKey(prefix, name), fn)
+		}
+	case reflect.Map:
+		names := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			names = append(names, k.String())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			walkValues(v.MapIndex(reflect.ValueOf(name)), joinKey(prefix, name), fn)
+		}
+	default:
+		fn(prefix, v)
+	}
+}
+
+// setPath walks path below v and parses raw into the leaf. v must be settable.
+func setPath(v reflect.Value, path []string, raw string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		if len(path) == 0 {
+			return fmt.Errorf("key names a table, not a value")
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if tomlName(t.Field(i)) == path[0] {
+				return setPath(v.Field(i), path[1:], raw)
+			}
+		}
+		return fmt.Errorf("unknown key %q", path[0])
+	case reflect.Map:
+		if len(path) == 0 {
+			return fmt.Errorf("key names a table, not a value")
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// Map elements are not addressable: update a copy and store it back
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(reflect.ValueOf(path[0])); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setPath(elem, path[1:], raw); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(path[0]), elem)
+		return nil
+	}
+
+	if len(path) > 0 {
+		return fmt.Errorf("unknown key %q", path[0])
+	}
+	return parseInto(v, raw)
+}
+
+// parseInto parses raw into a leaf value according to its kind
+func parseInto(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(unquote(raw))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean (use true or false)", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		items := []string{}
+		raw = strings.TrimSuffix(strings.TrimPrefix(raw, "["), "]")
+		for _, item := range strings.Split(raw, ",") {
+			if item = unquote(strings.TrimSpace(item)); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Type())
+	}
+
+	return nil
+}
+
+// formatValue renders a leaf value as TOML
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return quoteString(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Struct:
+		// Structs in lists, such as layout panes, are written as inline tables
+		t := v.Type()
+		fields := make([]string, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			if name := tomlName(t.Field(i)); name != "" {
+				fields = append(fields, name+" = "+formatValue(v.Field(i)))
+			}
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// quoteString renders s as a TOML basic string. Unlike strconv.Quote it only
+// uses escapes TOML knows: control characters are written as \uXXXX.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquote strips one pair of surrounding double quotes, as written in TOML
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
+
+func tomlName(f reflect.StructField) string {
+	tag := strings.Split(f.Tag.Get("toml"), ",")[0]
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return strings.ToLower(f.Name)
+	}
+	return tag
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
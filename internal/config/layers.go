@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Layer identifies where a configuration value came from.
+// Layers are applied in the order listed; later layers win.
+type Layer string
+
+const (
+	LayerDefault Layer = "default" // built-in DefaultConfig()
+	LayerUser    Layer = "user"    // ~/.config/ocw/config.toml
+	LayerRepo    Layer = "repo"    // <repo>/.ocw/config.toml
+	LayerLocal   Layer = "local"   // <repo>/.ocw/config.local.toml, git-ignored
+	LayerEnv     Layer = "env"     // OCW_* environment variables
+	LayerFlag    Layer = "flag"    // --set key=value
+)
+
+// Origin records the layer, and the file, variable or flag within it, that set a value
+type Origin struct {
+	Layer  Layer
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Layer)
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// Origins maps dotted config keys to the origin of their effective value
+type Origins map[string]Origin
+
+// EnvPrefix prefixes environment variables that override config keys,
+// e.g. OCW_OPENCODE_MODEL for opencode.model
+const EnvPrefix = "OCW_"
+
+// LocalConfigFile is the per-user repo config that is kept out of git
+const LocalConfigFile = "config.local.toml"
+
+// overrides holds the --set key=value flags of the current process
+var overrides []string
+
+// SetOverrides registers key=value pairs given with --set. They are applied
+// on top of every other layer by LoadConfig.
+func SetOverrides(pairs []string) error {
+	for _, pair := range pairs {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid --set %q (expected key=value)", pair)
+		}
+	}
+	overrides = pairs
+	return nil
+}
+
+// UserConfigPath returns the path of the user-global config file:
+// $XDG_CONFIG_HOME/ocw/config.toml, falling back to ~/.config/ocw/config.toml
+func UserConfigPath() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" && filepath.IsAbs(configHome) {
+		return filepath.Join(configHome, "ocw", "config.toml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "ocw", "config.toml"), nil
+}
+
+// RepoConfigPath returns the path of the shared repo config file
+func RepoConfigPath(dir string) string {
+	return filepath.Join(dir, ".ocw", "config.toml")
+}
+
+// LocalConfigPath returns the path of the git-ignored repo config file
+func LocalConfigPath(dir string) string {
+	return filepath.Join(dir, ".ocw", LocalConfigFile)
+}
+
+// EnvName returns the environment variable that overrides a dotted key
+func EnvName(key string) string {
+	name := strings.NewReplacer(".", "_", "-", "_").Replace(key)
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// File is a config file layer
+type File struct {
+	Layer Layer
+	Path  string
+}
+
+// configFiles lists the config file layers for the repository at dir, lowest precedence first
+func configFiles(dir string) []File {
+	var files []File
+	if userPath, err := UserConfigPath(); err == nil {
+		files = append(files, File{Layer: LayerUser, Path: userPath})
+	}
+	if dir != "" {
+		files = append(files,
+			File{Layer: LayerRepo, Path: RepoConfigPath(dir)},
+			File{Layer: LayerLocal, Path: LocalConfigPath(dir)},
+		)
+	}
+	return files
+}
+
+// LoadLayered builds the effective config for the repository at dir and records
+// the origin of every value. An empty dir loads only the non-repo layers.
+//
+// Unknown keys, values of the wrong type and values rejected by the semantic
+// validators are errors: LoadLayered then returns a *ValidationError listing
+// every problem found, including warnings, with the file and line it came from.
+func LoadLayered(dir string) (*Config, Origins, error) {
+	cfg := DefaultConfig()
+	origins := Origins{}
+	for _, key := range Keys(cfg) {
+		origins[key] = Origin{Layer: LayerDefault}
+	}
+
+	var problems []Problem
+	for _, f := range configFiles(dir) {
+		problems = append(problems, applyFile(cfg, origins, f.Layer, f.Path)...)
+		if f.Layer == LayerRepo {
+			problems = append(problems, applyTemplateFiles(cfg, origins, dir)...)
+		}
+	}
+
+	problems = append(problems, applyEnv(cfg, origins)...)
+
+	for _, pair := range overrides {
+		key, value, _ := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		source := "--set " + key
+		if err := Set(cfg, key, value); err != nil {
+			problems = append(problems, Problem{
+				Severity: SeverityError,
+				Source:   source,
+				Message:  fmt.Sprintf("invalid --set %q: %v", pair, err),
+			})
+			continue
+		}
+		markOrigin(cfg, origins, key, Origin{Layer: LayerFlag, Source: source})
+	}
+
+	problems = append(problems, Check(cfg, origins)...)
+	if HasErrors(problems) {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, origins, nil
+}
+
+// applyFile decodes a TOML file over cfg; a missing file is skipped
+func applyFile(cfg *Config, origins Origins, layer Layer, path string) []Problem {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	meta, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return []Problem{decodeProblem(layer, path, err)}
+	}
+
+	for _, key := range meta.Keys() {
+		markOrigin(cfg, origins, key.String(), Origin{Layer: layer, Source: path})
+	}
+
+	return fileProblems(path, meta, nil)
+}
+
+// applyEnv applies OCW_* variables that name a known key. Other OCW_*
+// variables are left alone.
+func applyEnv(cfg *Config, origins Origins) []Problem {
+	var problems []Problem
+	for _, key := range Keys(cfg) {
+		name := EnvName(key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := Set(cfg, key, value); err != nil {
+			problems = append(problems, Problem{
+				Severity: SeverityError,
+				Source:   name,
+				Message:  fmt.Sprintf("invalid environment variable: %v", err),
+			})
+			continue
+		}
+		origins[key] = Origin{Layer: LayerEnv, Source: name}
+	}
+	return problems
+}
+
+// markOrigin records origin for key. A map entry such as a template is replaced
+// as a whole when decoded, so every value below it takes the same origin.
+func markOrigin(cfg *Config, origins Origins, key string, origin Origin) {
+	entry := isMapEntry(key)
+	for _, k := range Keys(cfg) {
+		if k == key || (entry && strings.HasPrefix(k, key+".")) {
+			origins[k] = origin
+		}
+	}
+}
+
+// isMapEntry reports whether key names an entry of a map, e.g. "workspace.templates.feature"
+func isMapEntry(key string) bool {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		switch t.Kind() {
+		case reflect.Map:
+			if i == len(parts)-1 {
+				return true
+			}
+			t = t.Elem()
+		case reflect.Struct:
+			found := false
+			for j := 0; j < t.NumField(); j++ {
+				if tomlName(t.Field(j)) == part {
+					t = t.Field(j).Type
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// isolateUserConfig keeps the developer's user config, OCW_* variables and
+// --set overrides out of a test. It returns the user config path.
+func isolateUserConfig(t *testing.T) string {
+	t.Helper()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	for _, pair := range os.Environ() {
+		if name, _, _ := strings.Cut(pair, "="); strings.HasPrefix(name, EnvPrefix) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+	t.Cleanup(func() { overrides = nil })
+	return filepath.Join(configHome, "ocw", "config.toml")
+}
+
+// setupLayers isolates the user config and returns a repo dir with an .ocw directory
+func setupLayers(t *testing.T) (userPath, repo string) {
+	t.Helper()
+	userPath = isolateUserConfig(t)
+	require.NoError(t, os.MkdirAll(filepath.Dir(userPath), 0755))
+
+	repo = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".ocw"), 0755))
+	return userPath, repo
+}
+
+func TestLoadLayeredPrecedence(t *testing.T) {
+	userPath, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+[opencode]
+model = "user-model"
+provider = "user-provider"
+
+[editor]
+command = "code"
+`), 0644))
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[opencode]
+model = "repo-model"
+
+[workspace]
+base_branch = "main"
+`), 0644))
+	require.NoError(t, os.WriteFile(LocalConfigPath(repo), []byte(`
+[workspace]
+base_branch = "develop"
+`), 0644))
+	t.Setenv("OCW_UI_MAX_INSTANCES", "4")
+	t.Setenv("OCW_EDITOR_COMMAND", "zed")
+	require.NoError(t, SetOverrides([]string{"ui.max_instances=6", "opencode.args=--verbose, --fast"}))
+
+	cfg, origins, err := LoadLayered(repo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "repo-model", cfg.OpenCode.Model)
+	assert.Equal(t, LayerRepo, origins["opencode.model"].Layer)
+	assert.Equal(t, RepoConfigPath(repo), origins["opencode.model"].Source)
+
+	assert.Equal(t, "user-provider", cfg.OpenCode.Provider)
+	assert.Equal(t, LayerUser, origins["opencode.provider"].Layer)
+
+	assert.Equal(t, "develop", cfg.Workspace.BaseBranch)
+	assert.Equal(t, LayerLocal, origins["workspace.base_branch"].Layer)
+
+	assert.Equal(t, "zed", cfg.Editor.Command)
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "OCW_EDITOR_COMMAND"}, origins["editor.command"])
+
+	assert.Equal(t, 6, cfg.UI.MaxInstances)
+	assert.Equal(t, LayerFlag, origins["ui.max_instances"].Layer)
+	assert.Equal(t, []string{"--verbose", "--fast"}, cfg.OpenCode.Args)
+
+	// Untouched values keep their defaults, including siblings of overridden ones
+	assert.Equal(t, ".worktrees", cfg.Workspace.WorktreeDir)
+	assert.Equal(t, LayerDefault, origins["workspace.worktree_dir"].Layer)
+	assert.Equal(t, LayerDefault, origins["workspace.stash_on_delete"].Layer)
+
+	// LoadConfig returns the same effective config
+	loaded, err := LoadConfig(repo)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
+
+func TestLoadLayeredTemplateOrigin(t *testing.T) {
+	_, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[workspace.templates.hotfix]
+base_branch = "release"
+`), 0644))
+
+	cfg, origins, err := LoadLayered(repo)
+	require.NoError(t, err)
+
+	// A template is replaced as a whole, so its unset fields come from the same layer
+	assert.Equal(t, "release", cfg.Workspace.Templates["hotfix"].BaseBranch)
+	assert.Equal(t, "", cfg.Workspace.Templates["hotfix"].InitCommand)
+	assert.Equal(t, LayerRepo, origins["workspace.templates.hotfix.init_command"].Layer)
+	assert.Equal(t, LayerDefault, origins["workspace.templates.feature.base_branch"].Layer)
+}
+
+func TestLoadLayeredErrors(t *testing.T) {
+	userPath, repo := setupLayers(t)
+
+	t.Setenv("OCW_UI_MAX_INSTANCES", "many")
+	_, _, err := LoadLayered(repo)
+	assert.ErrorContains(t, err, "OCW_UI_MAX_INSTANCES")
+	os.Unsetenv("OCW_UI_MAX_INSTANCES")
+
+	require.NoError(t, SetOverrides([]string{"opencode.nope=1"}))
+	_, _, err = LoadLayered(repo)
+	assert.ErrorContains(t, err, "opencode.nope")
+	overrides = nil
+
+	assert.Error(t, SetOverrides([]string{"missing-equals"}))
+
+	require.NoError(t, os.WriteFile(userPath, []byte("not = [valid"), 0644))
+	_, _, err = LoadLayered(repo)
+	assert.ErrorContains(t, err, "user config file")
+}
+
+func TestKeysGetSet(t *testing.T) {
+	cfg := DefaultConfig()
+
+	keys := Keys(cfg)
+	assert.Equal(t, "workspace.worktree_dir", keys[0])
+	assert.Contains(t, keys, "workspace.templates.hotfix.init_command")
+	assert.Contains(t, keys, "ui.max_instances")
+
+	require.NoError(t, Set(cfg, "tmux.primary_pane_ratio", "55"))
+	require.NoError(t, Set(cfg, "merge.draft_pr", "true"))
+	require.NoError(t, Set(cfg, "editor.command", `"code --wait"`))
+	require.NoError(t, Set(cfg, "editor.terminal_editors", `["hx", "kak"]`))
+	require.NoError(t, Set(cfg, "workspace.templates.docs.base_branch", "docs"))
+
+	assert.Equal(t, 55, cfg.Tmux.PrimaryPaneRatio)
+	assert.True(t, cfg.Merge.DraftPR)
+	assert.Equal(t, "code --wait", cfg.Editor.Command)
+	assert.Equal(t, []string{"hx", "kak"}, cfg.Editor.TerminalEditors)
+	assert.Equal(t, "docs", cfg.Workspace.Templates["docs"].BaseBranch)
+
+	value, err := Get(cfg, "editor.terminal_editors")
+	require.NoError(t, err)
+	assert.Equal(t, `["hx", "kak"]`, value)
+
+	value, err = Get(cfg, "tmux.primary_pane_ratio")
+	require.NoError(t, err)
+	assert.Equal(t, "55", value)
+
+	_, err = Get(cfg, "tmux.nope")
+	assert.Error(t, err)
+
+	assert.Error(t, Set(cfg, "tmux.primary_pane_ratio", "wide"))
+	assert.Error(t, Set(cfg, "merge.draft_pr", "maybe"))
+	assert.Error(t, Set(cfg, "tmux", "x"))
+	assert.Error(t, Set(cfg, "workspace.templates.docs", "x"))
+	assert.Error(t, Set(cfg, "tmux.primary_pane_ratio.x", "1"))
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "OCW_OPENCODE_MODEL", EnvName("opencode.model"))
+	assert.Equal(t, "OCW_WORKSPACE_TEMPLATES_HOTFIX_BASE_BRANCH", EnvName("workspace.templates.hotfix.base_branch"))
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TemplatesDir returns the directory holding standalone template files. Each
+// <name>.toml file in it defines the template <name> with the same fields as
+// a [workspace.templates.<name>] table, and replaces a template of the same
+// name from the config files.
+func TemplatesDir(dir string) string {
+	return filepath.Join(dir, ".ocw", "templates")
+}
+
+// TemplateNames returns the names of all templates, sorted
+func (c *Config) TemplateNames() []string {
+	return sortedTemplateNames(c.Workspace.Templates)
+}
+
+// Template returns the template with the given name
+func (c *Config) Template(name string) (Template, error) {
+	tmpl, ok := c.Workspace.Templates[name]
+	if !ok {
+		available := "none"
+		if names := c.TemplateNames(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		return Template{}, fmt.Errorf("template %q not found (available: %s)", name, available)
+	}
+	return tmpl, nil
+}
+
+// applyTemplateFiles decodes every template file of the repository at dir into cfg
+func applyTemplateFiles(cfg *Config, origins Origins, dir string) []Problem {
+	paths, err := filepath.Glob(filepath.Join(TemplatesDir(dir), "*.toml"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(paths)
+
+	var problems []Problem
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".toml")
+		prefix := toml.Key{"workspace", "templates", name}
+
+		var tmpl Template
+		meta, err := toml.DecodeFile(path, &tmpl)
+		if err != nil {
+			problems = append(problems, decodeProblem(LayerRepo, path, err))
+			continue
+		}
+
+		if cfg.Workspace.Templates == nil {
+			cfg.Workspace.Templates = map[string]Template{}
+		}
+		cfg.Workspace.Templates[name] = tmpl
+		markOrigin(cfg, origins, prefix.String(), Origin{Layer: LayerRepo, Source: path})
+
+		problems = append(problems, fileProblems(path, meta, prefix)...)
+	}
+	return problems
+}
+
+// isTemplateFile reports whether path is a standalone template file, whose
+// keys are written without the workspace.templates.<name> prefix
+func isTemplateFile(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == "templates" && filepath.Ext(path) == ".toml"
+}
+
+// fileKey returns key as written in the file at path
+func fileKey(path, key string) string {
+	if !isTemplateFile(path) {
+		return key
+	}
+	parts := strings.SplitN(key, ".", 4)
+	if len(parts) < 4 {
+		return ""
+	}
+	return parts[3]
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplateFiles(t *testing.T) {
+	_, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[workspace.templates.spike]
+base_branch = "develop"
+`), 0644))
+	require.NoError(t, os.MkdirAll(TemplatesDir(repo), 0755))
+	spikePath := filepath.Join(TemplatesDir(repo), "spike.toml")
+	require.NoError(t, os.WriteFile(spikePath, []byte(`
+base_branch = "main"
+branch_pattern = "spike/{name}"
+command = "aider"
+args = ["--yes"]
+files = [".env"]
+prompt = "Explore the problem"
+tags = ["spike"]
+
+[env]
+STAGE = "spike"
+
+[[layout]]
+label = "server"
+command = "npm run dev"
+`), 0644))
+
+	cfg, origins, err := LoadLayered(repo)
+	require.NoError(t, err)
+
+	spike, err := cfg.Template("spike")
+	require.NoError(t, err)
+	assert.Equal(t, Template{
+		BaseBranch:    "main",
+		BranchPattern: "spike/{name}",
+		Command:       "aider",
+		Args:          []string{"--yes"},
+		Env:           map[string]string{"STAGE": "spike"},
+		Layout:        []Pane{{Label: "server", Command: "npm run dev"}},
+		Files:         []string{".env"},
+		Prompt:        "Explore the problem",
+		Tags:          []string{"spike"},
+	}, spike)
+
+	// The template file replaces the template from config.toml
+	assert.Equal(t, Origin{Layer: LayerRepo, Source: spikePath}, origins["workspace.templates.spike.base_branch"])
+	assert.Equal(t, []string{"feature", "hotfix", "spike"}, cfg.TemplateNames())
+
+	value, err := Get(cfg, "workspace.templates.spike.layout")
+	require.NoError(t, err)
+	assert.Equal(t, `[{label = "server", command = "npm run dev"}]`, value)
+
+	_, err = cfg.Template("missing")
+	assert.EqualError(t, err, `template "missing" not found (available: feature, hotfix, spike)`)
+}
+
+func TestTemplateFileProblems(t *testing.T) {
+	_, repo := setupLayers(t)
+
+	require.NoError(t, os.MkdirAll(TemplatesDir(repo), 0755))
+	path := filepath.Join(TemplatesDir(repo), "spike.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`modle = "gpt-5"
+files = ["/etc/passwd"]
+
+[env]
+"BAD-NAME" = "1"
+`), 0644))
+
+	_, _, err := LoadLayered(repo)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	assert.Contains(t, validationErr.Problems, Problem{
+		Severity: SeverityError, Source: path, Line: 1, Key: "workspace.templates.spike.modle",
+		Message: "unknown key", Suggestion: "workspace.templates.spike.model",
+	})
+
+	byKey := map[string]Problem{}
+	for _, p := range validationErr.Problems {
+		byKey[p.Key] = p
+	}
+	assert.Equal(t, 2, byKey["workspace.templates.spike.files"].Line)
+	assert.Equal(t, 5, byKey["workspace.templates.spike.env.BAD-NAME"].Line)
+}
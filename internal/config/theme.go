@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ThemeConfig selects the TUI colors: a built-in theme by name, with any of
+// its colors overridden. Colors are ANSI numbers from 0 to 255, e.g. "62", or
+// hex codes, e.g. "#5f5fd7". Empty colors are taken from the named theme.
+type ThemeConfig struct {
+	Name          string `toml:"name"`                     // dark, light or high-contrast
+	Accent        string `toml:"accent,omitempty"`         // titles and focused borders
+	Text          string `toml:"text,omitempty"`           // list entries
+	Muted         string `toml:"muted,omitempty"`          // footers, previews and paused instances
+	Selection     string `toml:"selection,omitempty"`      // background of the selected entry
+	SelectionText string `toml:"selection_text,omitempty"` // text of the selected entry
+	Success       string `toml:"success,omitempty"`        // running instances and added files
+	Warning       string `toml:"warning,omitempty"`        // idle instances, conflicts and modified files
+	Error         string `toml:"error,omitempty"`          // errors and deleted files
+	Info          string `toml:"info,omitempty"`           // merged instances and renamed files
+	PROpen        string `toml:"pr_open,omitempty"`        // instances with an open pull request
+}
+
+// ThemeNames lists the built-in themes
+var ThemeNames = []string{"dark", "light", "high-contrast"}
+
+// builtinThemes are the palettes of the built-in themes
+var builtinThemes = map[string]ThemeConfig{
+	"dark": {
+		Accent:        "62",
+		Text:          "250",
+		Muted:         "240",
+		Selection:     "57",
+		SelectionText: "229",
+		Success:       "46",
+		Warning:       "226",
+		Error:         "196",
+		Info:          "33",
+		PROpen:        "51",
+	},
+	"light": {
+		Accent:        "55",
+		Text:          "236",
+		Muted:         "244",
+		Selection:     "189",
+		SelectionText: "17",
+		Success:       "28",
+		Warning:       "130",
+		Error:         "160",
+		Info:          "25",
+		PROpen:        "30",
+	},
+	// The 16 basic colors, which terminals adapt to their own background
+	"high-contrast": {
+		Accent:        "15",
+		Text:          "15",
+		Muted:         "7",
+		Selection:     "11",
+		SelectionText: "0",
+		Success:       "10",
+		Warning:       "11",
+		Error:         "9",
+		Info:          "14",
+		PROpen:        "13",
+	},
+}
+
+// Palette returns every color of the theme: the named theme's colors with
+// the overrides applied. An unknown name falls back to the dark theme.
+func (t ThemeConfig) Palette() ThemeConfig {
+	palette, ok := builtinThemes[t.Name]
+	if !ok {
+		palette = builtinThemes["dark"]
+	}
+	palette.Name = t.Name
+
+	overrides := reflect.ValueOf(t)
+	v := reflect.ValueOf(&palette).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if color := overrides.Field(i).String(); color != "" {
+			v.Field(i).SetString(color)
+		}
+	}
+	return palette
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether color is an ANSI color number or a hex code
+func validColor(color string) bool {
+	if hexColorPattern.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// checkTheme reports an unknown theme name and malformed color overrides
+func checkTheme(theme ThemeConfig) []Problem {
+	var problems []Problem
+	if !contains(ThemeNames, theme.Name) {
+		problems = append(problems, Problem{
+			Severity: SeverityError,
+			Key:      "ui.theme.name",
+			Message:  fmt.Sprintf("%q is not a theme (use %s)", theme.Name, strings.Join(ThemeNames, ", ")),
+		})
+	}
+
+	v := reflect.ValueOf(theme)
+	for i := 0; i < v.NumField(); i++ {
+		name := tomlName(v.Type().Field(i))
+		if color := v.Field(i).String(); name != "name" && color != "" && !validColor(color) {
+			problems = append(problems, Problem{
+				Severity: SeverityError,
+				Key:      "ui.theme." + name,
+				Message:  fmt.Sprintf("%q is not a color (use an ANSI number from 0 to 255 or a hex code such as \"#5f5fd7\")", color),
+			})
+		}
+	}
+	return problems
+}
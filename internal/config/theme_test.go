@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemePalette(t *testing.T) {
+	p := ThemeConfig{Name: "light", Accent: "#5f5fd7"}.Palette()
+	assert.Equal(t, "light", p.Name)
+	assert.Equal(t, "#5f5fd7", p.Accent)
+	assert.Equal(t, builtinThemes["light"].Text, p.Text)
+
+	p = ThemeConfig{Name: "unknown"}.Palette()
+	assert.Equal(t, builtinThemes["dark"].Accent, p.Accent)
+}
+
+func TestCheckTheme(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme ThemeConfig
+		want  []string
+	}{
+		{"default", ThemeConfig{Name: "dark"}, nil},
+		{"overrides", ThemeConfig{Name: "high-contrast", Accent: "255", Error: "#f00", Info: "#00afff"}, nil},
+		{"unknown name", ThemeConfig{Name: "solarized"},
+			[]string{`ui.theme.name: "solarized" is not a theme (use dark, light, high-contrast)`}},
+		{"out of range", ThemeConfig{Name: "dark", Muted: "256"},
+			[]string{`ui.theme.muted: "256" is not a color (use an ANSI number from 0 to 255 or a hex code such as "#5f5fd7")`}},
+		{"malformed hex", ThemeConfig{Name: "dark", PROpen: "#12345"},
+			[]string{`ui.theme.pr_open: "#12345" is not a color (use an ANSI number from 0 to 255 or a hex code such as "#5f5fd7")`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range checkTheme(tt.theme) {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
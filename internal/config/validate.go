@@ -0,0 +1,458 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// Severity tells whether a problem makes the config unusable
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a single issue found while loading or validating the config
+type Problem struct {
+	Severity   Severity
+	Source     string // file, environment variable or flag; empty for built-in defaults
+	Line       int    // line in Source, 0 if unknown
+	Key        string // dotted key, empty if the problem is not about a single key
+	Message    string
+	Suggestion string // likely intended key for unknown keys
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Source != "" {
+		b.WriteString(p.Source)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+		}
+		b.WriteString(": ")
+	}
+	if p.Key != "" {
+		b.WriteString(p.Key + ": ")
+	}
+	b.WriteString(p.Message)
+	if p.Suggestion != "" {
+		fmt.Fprintf(&b, " (did you mean %q?)", p.Suggestion)
+	}
+	return b.String()
+}
+
+// ValidationError is returned by LoadLayered when the config has errors.
+// Problems holds every error and warning found, in load order.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var lines []string
+	for _, p := range e.Problems {
+		if p.Severity == SeverityError {
+			lines = append(lines, p.String())
+		}
+	}
+	if len(lines) == 1 {
+		return "invalid config: " + lines[0]
+	}
+	return fmt.Sprintf("invalid config (%d errors):\n  %s", len(lines), strings.Join(lines, "\n  "))
+}
+
+// HasErrors reports whether any problem is an error
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Check runs the semantic validators over an effective config and locates each
+// problem in the layer that set the offending value.
+func Check(cfg *Config, origins Origins) []Problem {
+	problems := checkValues(cfg)
+
+	lines := map[string]map[string]int{}
+	for i := range problems {
+		origin, ok := origins[problems[i].Key]
+		if !ok || origin.Layer == LayerDefault {
+			continue
+		}
+		problems[i].Source = origin.Source
+		if origin.Layer == LayerUser || origin.Layer == LayerRepo || origin.Layer == LayerLocal {
+			if _, ok := lines[origin.Source]; !ok {
+				lines[origin.Source] = keyLinesOf(origin.Source)
+			}
+			problems[i].Line = lines[origin.Source][fileKey(origin.Source, problems[i].Key)]
+		}
+	}
+
+	return problems
+}
+
+var (
+	// tmux uses '.' and ':' as target separators, so session names may not contain them
+	sessionPrefixPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	envNamePattern       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	splitDirections      = []string{"horizontal", "vertical"}
+	mergeProviders       = []string{"github", "gitlab"}
+)
+
+// checkValues validates the values of every section
+func checkValues(cfg *Config) []Problem {
+	var problems []Problem
+	fail := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityError, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityWarning, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// workspace
+	if strings.TrimSpace(cfg.Workspace.WorktreeDir) == "" {
+		fail("workspace.worktree_dir", "must not be empty")
+	}
+	if strings.TrimSpace(cfg.Workspace.BaseBranch) == "" {
+		fail("workspace.base_branch", "must not be empty")
+	} else if strings.ContainsAny(cfg.Workspace.BaseBranch, " \t") {
+		fail("workspace.base_branch", "%q is not a valid branch name", cfg.Workspace.BaseBranch)
+	}
+	problems = append(problems, checkBootstrap(cfg.Workspace.Bootstrap)...)
+	for _, name := range sortedTemplateNames(cfg.Workspace.Templates) {
+		tmpl := cfg.Workspace.Templates[name]
+		prefix := "workspace.templates." + name + "."
+		if strings.ContainsAny(tmpl.BaseBranch, " \t") {
+			fail(prefix+"base_branch", "%q is not a valid branch name", tmpl.BaseBranch)
+		}
+		if strings.ContainsAny(tmpl.BranchPattern, " \t") {
+			fail(prefix+"branch_pattern", "%q is not a valid branch name pattern", tmpl.BranchPattern)
+		}
+		if tmpl.Command != "" && !commandExists(tmpl.Command) {
+			warn(prefix+"command", "%q was not found in PATH", commandName(tmpl.Command))
+		}
+		for _, key := range sortedKeys(tmpl.Env) {
+			if !envNamePattern.MatchString(key) {
+				fail(prefix+"env."+key, "%q is not a valid environment variable name", key)
+			}
+		}
+		for _, file := range tmpl.Files {
+			if file == "" || filepath.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+				fail(prefix+"files", "%q must be a path relative to the repository root", file)
+			}
+		}
+		for _, tag := range tmpl.Tags {
+			if err := state.ValidateTag(tag); err != nil {
+				fail(prefix+"tags", "%v", err)
+			}
+		}
+		seen := map[string]bool{}
+		for _, v := range tmpl.Vars {
+			switch {
+			case !varNamePattern.MatchString(v.Name):
+				fail(prefix+"vars", "%q is not a valid variable name (use letters, digits and '_')", v.Name)
+			case v.Name == NameVar:
+				fail(prefix+"vars", "%q is built in and cannot be declared", v.Name)
+			case seen[v.Name]:
+				fail(prefix+"vars", "variable %q is declared more than once", v.Name)
+			}
+			seen[v.Name] = true
+			if v.Pattern != "" {
+				if _, err := regexp.Compile(v.Pattern); err != nil {
+					fail(prefix+"vars", "variable %q has an invalid pattern: %v", v.Name, err)
+				}
+			}
+		}
+		for _, field := range []struct{ key, text string }{
+			{"branch_pattern", tmpl.BranchPattern},
+			{"name_pattern", tmpl.NamePattern},
+			{"prompt", tmpl.Prompt},
+		} {
+			if err := tmpl.checkPlaceholders(field.text); err != nil {
+				fail(prefix+field.key, "%v", err)
+			}
+		}
+	}
+
+	// opencode
+	if strings.TrimSpace(cfg.OpenCode.Command) == "" {
+		fail("opencode.command", "must not be empty")
+	} else if !commandExists(cfg.OpenCode.Command) {
+		warn("opencode.command", "%q was not found in PATH", commandName(cfg.OpenCode.Command))
+	}
+
+	// agents
+	problems = append(problems, checkAgents(cfg)...)
+
+	// editor
+	if cfg.Editor.Command != "" && !commandExists(cfg.Editor.Command) {
+		warn("editor.command", "%q was not found in PATH", commandName(cfg.Editor.Command))
+	}
+	for _, editor := range cfg.Editor.TerminalEditors {
+		if strings.TrimSpace(editor) == "" {
+			fail("editor.terminal_editors", "must not contain empty entries")
+			break
+		}
+	}
+
+	// merge
+	if !contains(mergeProviders, cfg.Merge.Provider) {
+		fail("merge.provider", "%q is not supported (use %s)", cfg.Merge.Provider, strings.Join(mergeProviders, " or "))
+	}
+
+	// tmux
+	if !sessionPrefixPattern.MatchString(cfg.Tmux.SessionPrefix) {
+		fail("tmux.session_prefix", "%q is not a valid tmux session prefix (use letters, digits, '-' and '_')", cfg.Tmux.SessionPrefix)
+	}
+	if !contains(splitDirections, cfg.Tmux.DefaultSplit) {
+		fail("tmux.default_split", "%q is not a split direction (use %s)", cfg.Tmux.DefaultSplit, strings.Join(splitDirections, " or "))
+	}
+	if cfg.Tmux.PrimaryPaneRatio < 1 || cfg.Tmux.PrimaryPaneRatio > 99 {
+		fail("tmux.primary_pane_ratio", "%d is out of range (must be a percentage between 1 and 99)", cfg.Tmux.PrimaryPaneRatio)
+	}
+
+	// ui
+	if cfg.UI.MaxInstances < 1 {
+		fail("ui.max_instances", "%d is out of range (must be at least 1)", cfg.UI.MaxInstances)
+	}
+	problems = append(problems, checkTheme(cfg.UI.Theme)...)
+
+	// hooks
+	if cfg.Hooks.TimeoutSeconds < 1 {
+		fail("hooks.timeout_seconds", "%d is out of range (must be at least 1)", cfg.Hooks.TimeoutSeconds)
+	}
+	for _, hook := range HookNames {
+		for _, command := range cfg.Hooks.Commands(hook) {
+			if strings.TrimSpace(command) == "" {
+				fail("hooks."+hook, "must not contain empty commands")
+				break
+			}
+		}
+	}
+
+	// ports
+	if cfg.Ports.Start < 1 || cfg.Ports.Start > 65535 {
+		fail("ports.start", "%d is out of range (must be a port between 1 and 65535)", cfg.Ports.Start)
+	}
+	if cfg.Ports.End < cfg.Ports.Start || cfg.Ports.End > 65535 {
+		fail("ports.end", "%d is out of range (must be a port between ports.start and 65535)", cfg.Ports.End)
+	}
+	if cfg.Ports.PerInstance < 0 {
+		fail("ports.per_instance", "%d is out of range (must not be negative)", cfg.Ports.PerInstance)
+	} else if size := cfg.Ports.End - cfg.Ports.Start + 1; size > 0 && cfg.Ports.PerInstance > size {
+		fail("ports.per_instance", "%d is more than the %d ports from ports.start to ports.end", cfg.Ports.PerInstance, size)
+	}
+
+	// fanout
+	if cfg.FanOut.TestTimeoutSeconds < 1 {
+		fail("fanout.test_timeout_seconds", "%d is out of range (must be at least 1)", cfg.FanOut.TestTimeoutSeconds)
+	}
+
+	// keys
+	problems = append(problems, checkKeys(cfg.Keys)...)
+
+	return problems
+}
+
+// fileProblems reports keys in a decoded file that do not match any config
+// field. Only the outermost unknown key is reported, e.g. "tmx" for a
+// misspelled [tmx] table rather than each key inside it. prefix is the key of
+// the table the file was decoded into, e.g. a template for template files.
+func fileProblems(path string, meta toml.MetaData, prefix toml.Key) []Problem {
+	undecoded := meta.Undecoded()
+	if len(undecoded) == 0 {
+		return nil
+	}
+
+	unknown := map[string]bool{}
+	for _, key := range undecoded {
+		unknown[key.String()] = true
+	}
+
+	lines := keyLinesOf(path)
+	var problems []Problem
+	for _, key := range undecoded {
+		if len(key) > 1 && unknown[key[:len(key)-1].String()] {
+			continue
+		}
+		full := append(append(toml.Key{}, prefix...), key...)
+		problems = append(problems, Problem{
+			Severity:   SeverityError,
+			Source:     path,
+			Line:       lines[key.String()],
+			Key:        full.String(),
+			Message:    "unknown key",
+			Suggestion: suggestKey(full),
+		})
+	}
+	return problems
+}
+
+// decodePattern matches the type errors of the TOML decoder, which carry the
+// position in the message rather than as a ParseError
+var decodePattern = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// decodeProblem turns a TOML decode error into a located problem
+func decodeProblem(layer Layer, path string, err error) Problem {
+	p := Problem{Severity: SeverityError, Source: path}
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		p.Line = parseErr.Position.Line
+		p.Key = parseErr.LastKey
+		p.Message = fmt.Sprintf("failed to decode %s config file: %s", layer, parseErr.Message)
+		return p
+	}
+
+	if m := decodePattern.FindStringSubmatch(err.Error()); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Key = m[2]
+		p.Message = fmt.Sprintf("failed to decode %s config file: %s", layer, m[3])
+		return p
+	}
+
+	p.Message = fmt.Sprintf("failed to decode %s config file: %v", layer, err)
+	return p
+}
+
+// suggestKey returns the known key closest to an unknown one, or "" if none is close
+func suggestKey(key toml.Key) string {
+	names := fieldNames(key[:len(key)-1])
+	last := key[len(key)-1]
+
+	best, bestDistance := "", len(last)/3+2
+	for _, name := range names {
+		if d := levenshtein(last, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return joinKey(key[:len(key)-1].String(), best)
+}
+
+// fieldNames returns the keys allowed in the table at path. Map tables such
+// as workspace.templates accept any entry name, so nothing is suggested there.
+func fieldNames(path []string) []string {
+	t := reflect.TypeOf(Config{})
+	for _, part := range path {
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			field, ok := fieldByTOMLName(t, part)
+			if !ok {
+				return nil
+			}
+			t = field.Type
+		default:
+			return nil
+		}
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := tomlName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func fieldByTOMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if tomlName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// keyLinesOf maps the dotted keys and table headers of a TOML file to their line numbers
+func keyLinesOf(path string) map[string]int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return map[string]int{}
+	}
+	return keyLines(string(data))
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// commandName returns the executable of a command line such as "code --wait"
+func commandName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func commandExists(command string) bool {
+	_, err := exec.LookPath(commandName(command))
+	return err == nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedTemplateNames(templates map[string]Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLayeredUnknownKeys(t *testing.T) {
+	_, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`# repo config
+[tmux]
+default_splt = "vertical"
+
+[tmx]
+session_prefix = "x"
+
+[workspace.templates.docs]
+base_brnch = "main"
+`), 0644))
+
+	_, _, err := LoadLayered(repo)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	path := RepoConfigPath(repo)
+	assert.Contains(t, validationErr.Problems, Problem{
+		Severity: SeverityError, Source: path, Line: 3, Key: "tmux.default_splt",
+		Message: "unknown key", Suggestion: "tmux.default_split",
+	})
+	assert.Contains(t, validationErr.Problems, Problem{
+		Severity: SeverityError, Source: path, Line: 5, Key: "tmx",
+		Message: "unknown key", Suggestion: "tmux",
+	})
+	assert.Contains(t, validationErr.Problems, Problem{
+		Severity: SeverityError, Source: path, Line: 9, Key: "workspace.templates.docs.base_brnch",
+		Message: "unknown key", Suggestion: "workspace.templates.docs.base_branch",
+	})
+
+	// Keys inside an unknown table are not reported separately
+	for _, p := range validationErr.Problems {
+		assert.NotEqual(t, "tmx.session_prefix", p.Key)
+	}
+
+	assert.Contains(t, err.Error(), path+":3: tmux.default_splt: unknown key (did you mean \"tmux.default_split\"?)")
+}
+
+func TestLoadLayeredInvalidValues(t *testing.T) {
+	userPath, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+[merge]
+provider = "bitbucket"
+`), 0644))
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[tmux]
+default_split = "diagonal"
+primary_pane_ratio = 150
+`), 0644))
+	t.Setenv("OCW_UI_MAX_INSTANCES", "0")
+
+	_, _, err := LoadLayered(repo)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	byKey := map[string]Problem{}
+	for _, p := range validationErr.Problems {
+		byKey[p.Key] = p
+	}
+
+	assert.Equal(t, userPath, byKey["merge.provider"].Source)
+	assert.Equal(t, 3, byKey["merge.provider"].Line)
+
+	assert.Equal(t, RepoConfigPath(repo), byKey["tmux.default_split"].Source)
+	assert.Equal(t, 3, byKey["tmux.default_split"].Line)
+	assert.Equal(t, 4, byKey["tmux.primary_pane_ratio"].Line)
+
+	assert.Equal(t, "OCW_UI_MAX_INSTANCES", byKey["ui.max_instances"].Source)
+	assert.Equal(t, 0, byKey["ui.max_instances"].Line)
+}
+
+func TestLoadLayeredTypeError(t *testing.T) {
+	_, repo := setupLayers(t)
+
+	require.NoError(t, os.WriteFile(RepoConfigPath(repo), []byte(`
+[ui]
+max_instances = "ten"
+`), 0644))
+
+	_, _, err := LoadLayered(repo)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.NotEmpty(t, validationErr.Problems)
+
+	p := validationErr.Problems[0]
+	assert.Equal(t, RepoConfigPath(repo), p.Source)
+	assert.Equal(t, 3, p.Line)
+	assert.Equal(t, "ui.max_instances", p.Key)
+	assert.Contains(t, p.Message, "repo config file")
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		key    string
+	}{
+		{"empty worktree dir", func(c *Config) { c.Workspace.WorktreeDir = "" }, "workspace.worktree_dir"},
+		{"empty base branch", func(c *Config) { c.Workspace.BaseBranch = " " }, "workspace.base_branch"},
+		{"template branch with spaces", func(c *Config) {
+			c.Workspace.Templates["feature"] = Template{BaseBranch: "my branch"}
+		}, "workspace.templates.feature.base_branch"},
+		{"undeclared template variable", func(c *Config) {
+			c.Workspace.Templates["feature"] = Template{BranchPattern: "feature/{ticket}"}
+		}, "workspace.templates.feature.branch_pattern"},
+		{"unknown template filter", func(c *Config) {
+			c.Workspace.Templates["feature"] = Template{Vars: []Var{{Name: "title"}}, Prompt: "Fix {title|camel}"}
+		}, "workspace.templates.feature.prompt"},
+		{"duplicate template variable", func(c *Config) {
+			c.Workspace.Templates["feature"] = Template{Vars: []Var{{Name: "ticket"}, {Name: "ticket"}}}
+		}, "workspace.templates.feature.vars"},
+		{"invalid variable pattern", func(c *Config) {
+			c.Workspace.Templates["feature"] = Template{Vars: []Var{{Name: "ticket", Pattern: "[A-Z"}}}
+		}, "workspace.templates.feature.vars"},
+		{"empty opencode command", func(c *Config) { c.OpenCode.Command = "" }, "opencode.command"},
+		{"empty terminal editor", func(c *Config) { c.Editor.TerminalEditors = []string{"vim", ""} }, "editor.terminal_editors"},
+		{"unknown merge provider", func(c *Config) { c.Merge.Provider = "bitbucket" }, "merge.provider"},
+		{"session prefix with dot", func(c *Config) { c.Tmux.SessionPrefix = "my.ocw" }, "tmux.session_prefix"},
+		{"invalid split", func(c *Config) { c.Tmux.DefaultSplit = "diagonal" }, "tmux.default_split"},
+		{"ratio too small", func(c *Config) { c.Tmux.PrimaryPaneRatio = 0 }, "tmux.primary_pane_ratio"},
+		{"ratio too large", func(c *Config) { c.Tmux.PrimaryPaneRatio = 150 }, "tmux.primary_pane_ratio"},
+		{"no instances", func(c *Config) { c.UI.MaxInstances = 0 }, "ui.max_instances"},
+		{"port out of range", func(c *Config) { c.Ports.Start = 0 }, "ports.start"},
+		{"port range reversed", func(c *Config) { c.Ports.End = 3000 }, "ports.end"},
+		{"more ports than the range", func(c *Config) { c.Ports.PerInstance = 1000 }, "ports.per_instance"},
+		{"no fan-out test time", func(c *Config) { c.FanOut.TestTimeoutSeconds = 0 }, "fanout.test_timeout_seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.OpenCode.Command = "sh" // found in PATH, so no warning
+			tt.modify(cfg)
+
+			var errs []string
+			for _, p := range Check(cfg, Origins{}) {
+				if p.Severity == SeverityError {
+					errs = append(errs, p.Key)
+				}
+			}
+			assert.Equal(t, []string{tt.key}, errs)
+		})
+	}
+
+	t.Run("defaults are valid", func(t *testing.T) {
+		cfg := DefaultConfig()
+		assert.False(t, HasErrors(Check(cfg, Origins{})))
+	})
+
+	t.Run("missing commands are warnings", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.OpenCode.Command = "ocw-no-such-command --flag"
+		cfg.Editor.Command = "ocw-no-such-editor"
+
+		problems := Check(cfg, Origins{})
+		assert.False(t, HasErrors(problems))
+		require.Len(t, problems, 2)
+		assert.Equal(t, `opencode.command: "ocw-no-such-command" was not found in PATH`, problems[0].String())
+		assert.Equal(t, "editor.command", problems[1].Key)
+	})
+}
+
+func TestKeyLines(t *testing.T) {
+	lines := keyLines(`# comment
+[opencode]
+command = "opencode"
+args = [
+  "--a = b",
+]
+model = """
+x = 1
+"""
+
+["workspace".templates.docs]
+"base_branch" = "main"
+`)
+
+	assert.Equal(t, 2, lines["opencode"])
+	assert.Equal(t, 3, lines["opencode.command"])
+	assert.Equal(t, 4, lines["opencode.args"])
+	assert.Equal(t, 7, lines["opencode.model"])
+	assert.NotContains(t, lines, "opencode.x")
+	assert.NotContains(t, lines, `opencode."--a`)
+	assert.Equal(t, 12, lines["workspace.templates.docs.base_branch"])
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Var is a variable declared by a template. Its value is given with
+// ocw new --var name=value or in the create form, and fills {name}
+// placeholders in the template's branch pattern, name pattern and prompt.
+type Var struct {
+	Name        string `toml:"name"`
+	Description string `toml:"description,omitempty"`
+	Default     string `toml:"default,omitempty"`
+	Required    bool   `toml:"required,omitempty"`
+	Pattern     string `toml:"pattern,omitempty"` // regular expression the value must match
+}
+
+// NameVar is the built-in variable holding the name given to ocw new
+const NameVar = "name"
+
+// maxSlugLength bounds slugs so branch names stay readable
+const maxSlugLength = 40
+
+var (
+	varNamePattern     = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	placeholderPattern = regexp.MustCompile(`\{\{|\{([a-zA-Z_][a-zA-Z0-9_]*)((?:\|[a-z]+)*)\}`)
+	slugPattern        = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// filters transform variable values, e.g. {title|slug}
+var filters = map[string]func(string) string{
+	"slug":  Slugify,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// Slugify turns text into a lowercase, hyphen-separated slug for branch
+// names, e.g. "Fix login timeout!" becomes "fix-login-timeout"
+func Slugify(text string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		// Cut at the last complete word if there is one
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// ParseVars parses name=value pairs given with --var
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q (expected name=value)", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// Var returns the declared variable with the given name
+func (t Template) Var(name string) (Var, bool) {
+	for _, v := range t.Vars {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
+
+// UsesVar reports whether any pattern of the template refers to the variable
+func (t Template) UsesVar(name string) bool {
+	for _, text := range []string{t.BranchPattern, t.NamePattern, t.Prompt} {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if m[1] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResolveVars checks the given values against the declared variables and
+// fills in defaults. The built-in name variable is accepted but optional
+// unless a pattern uses it.
+func (t Template) ResolveVars(given map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(t.Vars)+1)
+	for name, value := range given {
+		if _, declared := t.Var(name); !declared && name != NameVar {
+			return nil, fmt.Errorf("unknown variable %q (declared: %s)", name, t.varNames())
+		}
+		resolved[name] = strings.TrimSpace(value)
+	}
+
+	var missing []string
+	for _, v := range t.Vars {
+		value := resolved[v.Name]
+		if value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			if v.Required {
+				missing = append(missing, v.Name)
+			}
+			continue
+		}
+		if v.Pattern != "" {
+			re, err := regexp.Compile(v.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("variable %q has an invalid pattern: %w", v.Name, err)
+			}
+			if !re.MatchString(value) {
+				return nil, fmt.Errorf("variable %q: %q does not match %s", v.Name, value, v.Pattern)
+			}
+		}
+		resolved[v.Name] = value
+	}
+
+	if resolved[NameVar] == "" && t.UsesVar(NameVar) {
+		missing = append(missing, NameVar)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing required variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	return resolved, nil
+}
+
+// Expand substitutes {var} and {var|filter} placeholders in text. {{ is
+// written as a literal {. Variables without a value expand to "".
+func Expand(text string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if match == "{{" {
+			return "{"
+		}
+		m := placeholderPattern.FindStringSubmatch(match)
+		value := vars[m[1]]
+		for _, name := range strings.Split(strings.TrimPrefix(m[2], "|"), "|") {
+			if filter, ok := filters[name]; ok {
+				value = filter(value)
+			}
+		}
+		return value
+	})
+}
+
+// checkPlaceholders reports placeholders in text that refer to undeclared
+// variables or unknown filters
+func (t Template) checkPlaceholders(text string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if m[0] == "{{" {
+			continue
+		}
+		if _, declared := t.Var(m[1]); !declared && m[1] != NameVar {
+			return fmt.Errorf("{%s} refers to an undeclared variable (declared: %s)", m[1], t.varNames())
+		}
+		if m[2] == "" {
+			continue
+		}
+		for _, name := range strings.Split(strings.TrimPrefix(m[2], "|"), "|") {
+			if _, ok := filters[name]; !ok {
+				return fmt.Errorf("{%s%s} uses unknown filter %q (use slug, lower or upper)", m[1], m[2], name)
+			}
+		}
+	}
+	return nil
+}
+
+// varNames lists the variables usable in patterns, including the built-in name
+func (t Template) varNames() string {
+	names := []string{NameVar}
+	for _, v := range t.Vars {
+		names = append(names, v.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Fix login", "fix-login"},
+		{"  Fix   login timeout!  ", "fix-login-timeout"},
+		{"OCW-123: Crash on_start", "ocw-123-crash-on-start"},
+		{"Über café", "ber-caf"},
+		{"!!!", ""},
+		{"Refactor the session store so that it survives restarts of the server", "refactor-the-session-store-so-that-it"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, Slugify(tt.input))
+		})
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"ticket=OCW-123", "title=Fix login = now", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ticket": "OCW-123", "title": "Fix login = now", "empty": ""}, vars)
+
+	_, err = ParseVars([]string{"ticket"})
+	assert.EqualError(t, err, `invalid variable "ticket" (expected name=value)`)
+}
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"ticket": "OCW-123", "title": "Fix Login"}
+
+	assert.Equal(t, "feature/OCW-123-fix-login", Expand("feature/{ticket}-{title|slug}", vars))
+	assert.Equal(t, "ocw-123 FIX LOGIN", Expand("{ticket|lower} {title|upper}", vars))
+	assert.Equal(t, "{literal} Fix Login", Expand("{{literal} {title}", vars))
+	assert.Equal(t, "owner: ", Expand("owner: {owner}", vars))
+}
+
+func TestResolveVars(t *testing.T) {
+	tmpl := Template{
+		BranchPattern: "feature/{ticket}-{title|slug}",
+		Vars: []Var{
+			{Name: "ticket", Required: true, Pattern: `^[A-Z]+-[0-9]+$`},
+			{Name: "title", Required: true},
+			{Name: "owner", Default: "team"},
+		},
+	}
+
+	vars, err := tmpl.ResolveVars(map[string]string{"ticket": "OCW-123", "title": " Fix login "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ticket": "OCW-123", "title": "Fix login", "owner": "team"}, vars)
+
+	_, err = tmpl.ResolveVars(map[string]string{"ticket": "OCW-123"})
+	assert.EqualError(t, err, "missing required variable(s): title")
+
+	_, err = tmpl.ResolveVars(map[string]string{"ticket": "123", "title": "x"})
+	assert.EqualError(t, err, `variable "ticket": "123" does not match ^[A-Z]+-[0-9]+$`)
+
+	_, err = tmpl.ResolveVars(map[string]string{"ticket": "OCW-1", "title": "x", "tikcet": "y"})
+	assert.EqualError(t, err, `unknown variable "tikcet" (declared: name, ticket, title, owner)`)
+
+	// The built-in name is required only when a pattern uses it
+	_, err = Template{BranchPattern: "hotfix/{name}"}.ResolveVars(nil)
+	assert.EqualError(t, err, "missing required variable(s): name")
+}
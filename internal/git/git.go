@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -29,6 +30,20 @@ func (g *Git) run(args ...string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// runEnv is run with extra VAR=value pairs in git's environment
+func (g *Git) runEnv(env []string, args ...string) (string, error) {
+	cmdArgs := append([]string{"-C", g.repoPath}, args...)
+	cmd := exec.Command("git", cmdArgs...)
+	cmd.Env = append(os.Environ(), env...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git command failed: %w: %s", err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // IsGitRepo checks if the specified path is a git repository
 func (g *Git) IsGitRepo() bool {
 	_, err := g.run("rev-parse", "--git-dir")
@@ -126,3 +141,24 @@ func (g *Git) DiffNameOnly(ref1, ref2 string) ([]string, error) {
 	files := strings.Split(strings.TrimSpace(output), "\n")
 	return files, nil
 }
+
+// TrackedFiles returns the files tracked by git at or below the given paths,
+// relative to the repository root
+func (g *Git) TrackedFiles(paths ...string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{}, nil
+	}
+
+	output, err := g.run(append([]string{"ls-files", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"fmt"
+)
+
+// RevParse resolves a revision to a commit SHA
+func (g *Git) RevParse(rev string) (string, error) {
+	output, err := g.run("rev-parse", "--verify", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return output, nil
+}
+
+// UpdateRef points ref at the given SHA, creating the ref if needed
+func (g *Git) UpdateRef(ref, sha string) error {
+	_, err := g.run("update-ref", ref, sha)
+	if err != nil {
+		return fmt.Errorf("failed to update ref %s: %w", ref, err)
+	}
+	return nil
+}
+
+// DeleteRef deletes a ref
+func (g *Git) DeleteRef(ref string) error {
+	_, err := g.run("update-ref", "-d", ref)
+	if err != nil {
+		return fmt.Errorf("failed to delete ref %s: %w", ref, err)
+	}
+	return nil
+}
+
+// RefExists checks if a fully qualified ref exists
+func (g *Git) RefExists(ref string) bool {
+	_, err := g.run("show-ref", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+// CheckBranchName reports whether name is a valid branch name
+func (g *Git) CheckBranchName(name string) error {
+	if _, err := g.run("check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch, along with its upstream configuration
+// and the HEAD of any worktree that has it checked out
+func (g *Git) RenameBranch(branch, newBranch string) error {
+	_, err := g.run("branch", "-m", branch, newBranch)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch %s to %s: %w", branch, newBranch, err)
+	}
+	return nil
+}
+
+// SetUpstream makes branch track the branch of the same name on remote
+func (g *Git) SetUpstream(branch, remote string) error {
+	if _, err := g.run("fetch", remote, branch); err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %w", branch, remote, err)
+	}
+	_, err := g.run("branch", "--set-upstream-to="+remote+"/"+branch, branch)
+	if err != nil {
+		return fmt.Errorf("failed to set upstream of %s: %w", branch, err)
+	}
+	return nil
+}
+
+// CreateBranch creates a branch at startPoint without checking it out
+func (g *Git) CreateBranch(branch, startPoint string) error {
+	_, err := g.run("branch", branch, startPoint)
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
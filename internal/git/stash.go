@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// HasUncommittedChanges reports whether the working tree has staged, unstaged or untracked changes
+func (g *Git) HasUncommittedChanges() (bool, error) {
+	output, err := g.run("status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to get status: %w", err)
+	}
+	return output != "", nil
+}
+
+// StashSnapshot stashes all uncommitted changes, including untracked files, and
+// returns the SHA of the stash commit. The entry is dropped from the stash list
+// right away, so the commit is only reachable through the returned SHA; callers
+// must keep it alive with a ref.
+func (g *Git) StashSnapshot(message string) (string, error) {
+	if _, err := g.run("stash", "push", "--include-untracked", "--message", message); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %w", err)
+	}
+
+	sha, err := g.run("rev-parse", "--verify", "refs/stash")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stash: %w", err)
+	}
+
+	if _, err := g.run("stash", "drop", "--quiet"); err != nil {
+		return sha, fmt.Errorf("failed to drop stash entry: %w", err)
+	}
+
+	return sha, nil
+}
+
+// StashApply applies a stash commit (for example one returned by StashSnapshot)
+// to the working tree, restoring untracked files too
+func (g *Git) StashApply(sha string) error {
+	if _, err := g.run("stash", "apply", sha); err != nil {
+		return fmt.Errorf("failed to apply stashed changes %s: %w", sha, err)
+	}
+	return nil
+}
+
+// StashApplyIndex is StashApply that also restores the staged changes to the index
+func (g *Git) StashApplyIndex(sha string) error {
+	if _, err := g.run("stash", "apply", "--index", sha); err != nil {
+		return fmt.Errorf("failed to apply stashed changes %s: %w", sha, err)
+	}
+	return nil
+}
+
+// StashCreate records all uncommitted changes, including untracked files, in a
+// stash commit like StashSnapshot's but leaves the working tree, the index and
+// the stash list untouched. It returns "" if there are no changes. The commit is
+// only reachable through the returned SHA; callers must keep it alive with a ref.
+func (g *Git) StashCreate(message string) (string, error) {
+	dirty, err := g.HasUncommittedChanges()
+	if err != nil || !dirty {
+		return "", err
+	}
+
+	head, err := g.run("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	// The staged changes, committed on top of HEAD
+	indexTree, err := g.run("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to record staged changes: %w", err)
+	}
+	indexCommit, err := g.run("commit-tree", indexTree, "-p", head, "-m", "index on "+message)
+	if err != nil {
+		return "", fmt.Errorf("failed to record staged changes: %w", err)
+	}
+
+	// Tracked files as they are in the working tree and untracked files are
+	// each written through a scratch index, so the real one is not touched
+	scratch, err := os.MkdirTemp("", "ocw-stash-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch index: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(scratch, "index")}
+
+	if _, err := g.runEnv(env, "read-tree", indexTree); err != nil {
+		return "", fmt.Errorf("failed to record unstaged changes: %w", err)
+	}
+	if _, err := g.runEnv(env, "add", "--update", "--", ":/"); err != nil {
+		return "", fmt.Errorf("failed to record unstaged changes: %w", err)
+	}
+	workTree, err := g.runEnv(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to record unstaged changes: %w", err)
+	}
+	parents := []string{"-p", head, "-p", indexCommit}
+
+	untracked, err := g.run("ls-files", "-z", "--others", "--exclude-standard", "--full-name", "--", ":/")
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	if untracked != "" {
+		commit, err := g.untrackedCommit(scratch, untracked, message)
+		if err != nil {
+			return "", fmt.Errorf("failed to record untracked files: %w", err)
+		}
+		parents = append(parents, "-p", commit)
+	}
+
+	sha, err := g.run(append(append([]string{"commit-tree", workTree}, parents...), "-m", message)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stash commit: %w", err)
+	}
+	return sha, nil
+}
+
+// untrackedCommit commits the NUL-separated untracked files, and nothing
+// else, through a scratch index in the scratch directory
+func (g *Git) untrackedCommit(scratch, files, message string) (string, error) {
+	list := filepath.Join(scratch, "untracked")
+	if err := os.WriteFile(list, []byte(files), 0600); err != nil {
+		return "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(scratch, "index"), "GIT_LITERAL_PATHSPECS=1"}
+	if _, err := g.runEnv(env, "read-tree", "--empty"); err != nil {
+		return "", err
+	}
+	if _, err := g.runEnv(env, "add", "--force", "--pathspec-from-file="+list, "--pathspec-file-nul"); err != nil {
+		return "", err
+	}
+	tree, err := g.runEnv(env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return g.run("commit-tree", tree, "-m", "untracked files on "+message)
+}
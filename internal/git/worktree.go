@@ -47,6 +47,15 @@ func (g *Git) WorktreeRemove(path string, force bool) error {
 	return nil
 }
 
+// WorktreeMove moves the worktree at path to newPath
+func (g *Git) WorktreeMove(path, newPath string) error {
+	_, err := g.run("worktree", "move", path, newPath)
+	if err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+	return nil
+}
+
 // WorktreeList returns a list of all worktrees by parsing `git worktree list --porcelain`
 func (g *Git) WorktreeList() ([]WorktreeInfo, error) {
 	output, err := g.run("worktree", "list", "--porcelain")
@@ -0,0 +1,232 @@
+// Package registry keeps a user-level list of repositories that use ocw, so
+// instances and tmux sessions can be found across repositories.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// Entry is a repository registered with ocw
+type Entry struct {
+	Path         string    `json:"path"`
+	Name         string    `json:"name"`
+	Session      string    `json:"session"`
+	RegisteredAt time.Time `json:"registered_at"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// file is the on-disk layout of the registry
+type file struct {
+	Repos []Entry `json:"repos"`
+}
+
+// Registry manages the registry file with file locking
+type Registry struct {
+	dir string
+}
+
+// New creates a Registry stored in dir
+func New(dir string) *Registry {
+	return &Registry{dir: dir}
+}
+
+// Default returns the registry under $XDG_STATE_HOME/ocw, falling back to ~/.local/state/ocw
+func Default() (*Registry, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(dir), nil
+}
+
+// DefaultDir returns the directory holding the user-level registry
+func DefaultDir() (string, error) {
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" && filepath.IsAbs(stateHome) {
+		return filepath.Join(stateHome, "ocw"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "ocw"), nil
+}
+
+// Path returns the path of the registry file
+func (r *Registry) Path() string {
+	return filepath.Join(r.dir, "repos.json")
+}
+
+func (r *Registry) lockPath() string {
+	return filepath.Join(r.dir, "repos.json.lock")
+}
+
+// Register adds a repository or refreshes its entry. Entries are keyed by path.
+func (r *Registry) Register(path, name, session string) error {
+	return r.update(func(f *file) error {
+		now := time.Now()
+		for i := range f.Repos {
+			if f.Repos[i].Path == path {
+				f.Repos[i].Name = name
+				f.Repos[i].Session = session
+				f.Repos[i].LastSeen = now
+				return nil
+			}
+		}
+
+		f.Repos = append(f.Repos, Entry{
+			Path:         path,
+			Name:         name,
+			Session:      session,
+			RegisteredAt: now,
+			LastSeen:     now,
+		})
+		return nil
+	})
+}
+
+// Unregister removes a repository from the registry
+func (r *Registry) Unregister(path string) error {
+	return r.update(func(f *file) error {
+		kept := f.Repos[:0]
+		for _, e := range f.Repos {
+			if e.Path != path {
+				kept = append(kept, e)
+			}
+		}
+		f.Repos = kept
+		return nil
+	})
+}
+
+// List returns the registered repositories sorted by name
+func (r *Registry) List() ([]Entry, error) {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+
+	lock := flock.New(r.lockPath())
+	if err := lock.RLock(); err != nil {
+		return nil, fmt.Errorf("failed to acquire registry read lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	f, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(f.Repos)
+	return f.Repos, nil
+}
+
+// Prune removes repositories that no longer exist or are no longer initialized
+// for ocw, and returns the removed entries.
+func (r *Registry) Prune() ([]Entry, error) {
+	var pruned []Entry
+	err := r.update(func(f *file) error {
+		pruned = nil
+		kept := f.Repos[:0]
+		for _, e := range f.Repos {
+			if Exists(e.Path) {
+				kept = append(kept, e)
+			} else {
+				pruned = append(pruned, e)
+			}
+		}
+		f.Repos = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pruned, nil
+}
+
+// Exists reports whether path is still a repository initialized for ocw
+func Exists(path string) bool {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(path, ".ocw")); err != nil {
+		return false
+	}
+	return true
+}
+
+// update applies fn to the registry under an exclusive lock and writes the result atomically
+func (r *Registry) update(fn func(*file) error) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+
+	lock := flock.New(r.lockPath())
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire registry write lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	f, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(f); err != nil {
+		return err
+	}
+
+	sortEntries(f.Repos)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+
+	tmpPath := r.Path() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	if err := os.Rename(tmpPath, r.Path()); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename registry file: %w", err)
+	}
+
+	return nil
+}
+
+// read loads the registry file; a missing file is an empty registry. Caller must hold the lock.
+func (r *Registry) read() (*file, error) {
+	data, err := os.ReadFile(r.Path())
+	if os.IsNotExist(err) {
+		return &file{Repos: []Entry{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse registry %s: %w", r.Path(), err)
+	}
+	if f.Repos == nil {
+		f.Repos = []Entry{}
+	}
+
+	return &f, nil
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Path < entries[j].Path
+	})
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepo creates a directory that looks like a repository initialized for ocw
+func newRepo(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.MkdirAll(filepath.Join(path, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, ".ocw"), 0755))
+	return path
+}
+
+func TestRegisterAndList(t *testing.T) {
+	reg := New(t.TempDir())
+
+	entries, err := reg.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	beta := newRepo(t, "beta")
+	alpha := newRepo(t, "alpha")
+	require.NoError(t, reg.Register(beta, "beta", "ocw-beta"))
+	require.NoError(t, reg.Register(alpha, "alpha", "ocw-alpha"))
+
+	entries, err = reg.List()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "alpha", entries[0].Name)
+	assert.Equal(t, "beta", entries[1].Name)
+
+	// Registering again updates the entry in place
+	registeredAt := entries[0].RegisteredAt
+	require.NoError(t, reg.Register(alpha, "alpha", "dev-alpha"))
+
+	entries, err = reg.List()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "dev-alpha", entries[0].Session)
+	assert.Equal(t, registeredAt.UnixNano(), entries[0].RegisteredAt.UnixNano())
+	assert.False(t, entries[0].LastSeen.Before(registeredAt))
+
+	require.NoError(t, reg.Unregister(beta))
+	entries, err = reg.List()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, alpha, entries[0].Path)
+}
+
+func TestPrune(t *testing.T) {
+	reg := New(t.TempDir())
+
+	kept := newRepo(t, "kept")
+	deleted := newRepo(t, "deleted")
+	uninitialized := newRepo(t, "uninitialized")
+	for _, path := range []string{kept, deleted, uninitialized} {
+		require.NoError(t, reg.Register(path, filepath.Base(path), "ocw-"+filepath.Base(path)))
+	}
+
+	require.NoError(t, os.RemoveAll(deleted))
+	require.NoError(t, os.RemoveAll(filepath.Join(uninitialized, ".ocw")))
+
+	pruned, err := reg.Prune()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(pruned))
+	assert.Equal(t, "deleted", pruned[0].Name)
+	assert.Equal(t, "uninitialized", pruned[1].Name)
+
+	entries, err := reg.List()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, kept, entries[0].Path)
+}
+
+func TestDefaultDir(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	dir, err := DefaultDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(stateHome, "ocw"), dir)
+
+	// Relative values are ignored as required by the XDG spec
+	home := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", "relative/path")
+	t.Setenv("HOME", home)
+
+	dir, err = DefaultDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "state", "ocw"), dir)
+}
+
+func TestCorruptRegistry(t *testing.T) {
+	dir := t.TempDir()
+	reg := New(dir)
+	require.NoError(t, os.WriteFile(reg.Path(), []byte("{not json"), 0644))
+
+	_, err := reg.List()
+	assert.Error(t, err)
+	assert.Error(t, reg.Register(newRepo(t, "repo"), "repo", "ocw-repo"))
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind describes how an instance differs between two states
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// FieldChange is a single field that differs between two versions of an instance
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// InstanceChange describes an instance that was added, removed or changed
+type InstanceChange struct {
+	Kind       ChangeKind
+	InstanceID string
+	Name       string
+	Fields     []FieldChange
+}
+
+// DiffStates compares two states instance by instance. Instances are reported in
+// the order they appear in to, followed by instances only present in from.
+func DiffStates(from, to *State) []InstanceChange {
+	fromByID := make(map[string]Instance, len(from.Instances))
+	for _, inst := range from.Instances {
+		fromByID[inst.ID] = inst
+	}
+	toByID := make(map[string]bool, len(to.Instances))
+
+	changes := make([]InstanceChange, 0)
+
+	for _, inst := range to.Instances {
+		toByID[inst.ID] = true
+
+		before, ok := fromByID[inst.ID]
+		if !ok {
+			changes = append(changes, InstanceChange{Kind: ChangeAdded, InstanceID: inst.ID, Name: inst.Name})
+			continue
+		}
+
+		fields := diffInstance(before, inst)
+		if len(fields) > 0 {
+			changes = append(changes, InstanceChange{Kind: ChangeChanged, InstanceID: inst.ID, Name: inst.Name, Fields: fields})
+		}
+	}
+
+	for _, inst := range from.Instances {
+		if !toByID[inst.ID] {
+			changes = append(changes, InstanceChange{Kind: ChangeRemoved, InstanceID: inst.ID, Name: inst.Name})
+		}
+	}
+
+	return changes
+}
+
+// diffInstance lists the user-visible fields that differ between two versions of an instance
+func diffInstance(from, to Instance) []FieldChange {
+	before := instanceFields(from)
+	after := instanceFields(to)
+
+	var fields []FieldChange
+	for i := range before {
+		if before[i][1] != after[i][1] {
+			fields = append(fields, FieldChange{Field: before[i][0], From: before[i][1], To: after[i][1]})
+		}
+	}
+
+	return fields
+}
+
+// instanceFields renders the compared fields of an instance as ordered name/value pairs
+func instanceFields(inst Instance) [][2]string {
+	return [][2]string{
+		{"name", inst.Name},
+		{"branch", inst.Branch},
+		{"base_branch", inst.BaseBranch},
+		{"worktree_path", inst.WorktreePath},
+		{"tmux_window", inst.TmuxWindow},
+		{"primary_pane", inst.PrimaryPane},
+		{"sub_terminals", strconv.Itoa(len(inst.SubTerminals))},
+		{"pid", strconv.Itoa(inst.PID)},
+		{"ports", inst.PortList()},
+		{"status", string(inst.Status)},
+		{"pr_url", inst.PRUrl},
+		{"conflicts_with", fmt.Sprintf("[%s]", strings.Join(inst.ConflictsWith, ", "))},
+		{"depends_on", fmt.Sprintf("[%s]", strings.Join(inst.DependsOn, ", "))},
+		{"labels", inst.Labels()},
+		{"task", inst.Task},
+		{"notes", strconv.Itoa(len(inst.Notes))},
+		{"fan_out", inst.FanOut},
+		{"forked_from", inst.ForkedFrom},
+	}
+}
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// HookRun records one run of a lifecycle hook command and its output
+type HookRun struct {
+	Time       time.Time `json:"time"`
+	Hook       string    `json:"hook"` // e.g. pre_create
+	InstanceID string    `json:"instance_id"`
+	Instance   string    `json:"instance,omitempty"`
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMS int64     `json:"duration_ms"`
+	Output     string    `json:"output,omitempty"` // combined stdout and stderr
+	Error      string    `json:"error,omitempty"`  // set when the command could not run or timed out
+}
+
+// Failed reports whether the hook command did not succeed
+func (r HookRun) Failed() bool {
+	return r.ExitCode != 0 || r.Error != ""
+}
+
+func (s *Store) hookLogPath() string {
+	return filepath.Join(s.dir, ".ocw", "hooks.jsonl")
+}
+
+func (s *Store) hookLogLockPath() string {
+	return filepath.Join(s.dir, ".ocw", "hooks.jsonl.lock")
+}
+
+// AppendHookRun appends a hook run to the hook log in .ocw/hooks.jsonl
+func (s *Store) AppendHookRun(run HookRun) error {
+	if run.Time.IsZero() {
+		run.Time = time.Now()
+	}
+
+	ocwDir := filepath.Join(s.dir, ".ocw")
+	if err := os.MkdirAll(ocwDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .ocw directory: %w", err)
+	}
+
+	lock := flock.New(s.hookLogLockPath())
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire hook log lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook run: %w", err)
+	}
+
+	f, err := os.OpenFile(s.hookLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open hook log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write hook run: %w", err)
+	}
+
+	return nil
+}
+
+// ReadHookRuns returns the hook runs of an instance, oldest first. An empty
+// instanceID returns the runs of every instance. Lines that cannot be parsed
+// are skipped.
+func (s *Store) ReadHookRuns(instanceID string) ([]HookRun, error) {
+	runs := []HookRun{}
+
+	if _, err := os.Stat(s.hookLogPath()); os.IsNotExist(err) {
+		return runs, nil
+	}
+
+	lock := flock.New(s.hookLogLockPath())
+	if err := lock.RLock(); err != nil {
+		return nil, fmt.Errorf("failed to acquire hook log read lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	f, err := os.Open(s.hookLogPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hook log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var run HookRun
+		if err := json.Unmarshal(line, &run); err != nil {
+			continue
+		}
+
+		if instanceID == "" || run.InstanceID == instanceID {
+			runs = append(runs, run)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hook log: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,34 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndReadHookRuns(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	runs, err := store.ReadHookRuns("")
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	require.NoError(t, store.AppendHookRun(HookRun{Hook: "pre_create", InstanceID: "inst1", Command: "npm ci", Output: "added 12 packages\n"}))
+	require.NoError(t, store.AppendHookRun(HookRun{Hook: "pre_create", InstanceID: "inst2", Command: "false", ExitCode: 1}))
+	require.NoError(t, store.AppendHookRun(HookRun{Hook: "post_create", InstanceID: "inst1", Command: "sleep 10", ExitCode: -1, Error: "timed out after 5s"}))
+
+	runs, err = store.ReadHookRuns("inst1")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "npm ci", runs[0].Command)
+	assert.Equal(t, "added 12 packages\n", runs[0].Output)
+	assert.False(t, runs[0].Failed())
+	assert.False(t, runs[0].Time.IsZero(), "AppendHookRun should stamp the run time")
+	assert.True(t, runs[1].Failed())
+
+	runs, err = store.ReadHookRuns("")
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+	assert.True(t, runs[1].Failed())
+}
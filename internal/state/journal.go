@@ -0,0 +1,200 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// EventType identifies the kind of lifecycle event recorded in the journal
+type EventType string
+
+const (
+	EventCreated            EventType = "created"
+	EventStatusChanged      EventType = "status-changed"
+	EventPaused             EventType = "paused"
+	EventResumed            EventType = "resumed"
+	EventRenamed            EventType = "renamed"
+	EventLabelsChanged      EventType = "labels-changed"
+	EventTaskChanged        EventType = "task-changed"
+	EventNoteAdded          EventType = "note-added"
+	EventDependencyAdded    EventType = "dependency-added"
+	EventDependencyRemoved  EventType = "dependency-removed"
+	EventPRCreated          EventType = "pr-created"
+	EventDeleted            EventType = "deleted"
+	EventRestored           EventType = "restored"
+	EventArchivePurged      EventType = "archive-purged"
+	EventRemovedByReconcile EventType = "removed-by-reconcile"
+	EventHookFailed         EventType = "hook-failed"
+	EventBootstrapped       EventType = "bootstrapped"
+	EventPromptSent         EventType = "prompt-sent"
+	EventFanOutPicked       EventType = "fanout-picked"
+)
+
+// EventTypes lists every known event type in display order
+var EventTypes = []EventType{
+	EventCreated,
+	EventStatusChanged,
+	EventPaused,
+	EventResumed,
+	EventRenamed,
+	EventLabelsChanged,
+	EventTaskChanged,
+	EventNoteAdded,
+	EventDependencyAdded,
+	EventDependencyRemoved,
+	EventPRCreated,
+	EventDeleted,
+	EventRestored,
+	EventArchivePurged,
+	EventRemovedByReconcile,
+	EventHookFailed,
+	EventBootstrapped,
+	EventPromptSent,
+	EventFanOutPicked,
+}
+
+// Event represents a single entry in the lifecycle event journal
+type Event struct {
+	Time       time.Time         `json:"time"`
+	Type       EventType         `json:"type"`
+	InstanceID string            `json:"instance_id"`
+	Instance   string            `json:"instance,omitempty"`
+	Branch     string            `json:"branch,omitempty"`
+	From       string            `json:"from,omitempty"`
+	To         string            `json:"to,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// EventFilter selects events when reading the journal.
+// Zero-valued fields match everything.
+type EventFilter struct {
+	Instance string // Matches instance ID, name or branch
+	Types    []EventType
+	Since    time.Time
+	Until    time.Time
+}
+
+// Matches reports whether the event satisfies the filter
+func (f EventFilter) Matches(ev Event) bool {
+	if f.Instance != "" && ev.InstanceID != f.Instance && ev.Instance != f.Instance && ev.Branch != f.Instance {
+		return false
+	}
+
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if ev.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !f.Since.IsZero() && ev.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && ev.Time.After(f.Until) {
+		return false
+	}
+
+	return true
+}
+
+func (s *Store) eventsPath() string {
+	return filepath.Join(s.dir, ".ocw", "events.jsonl")
+}
+
+func (s *Store) eventsLockPath() string {
+	return filepath.Join(s.dir, ".ocw", "events.jsonl.lock")
+}
+
+// AppendEvent appends an event to the append-only journal in .ocw/events.jsonl
+func (s *Store) AppendEvent(ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	ocwDir := filepath.Join(s.dir, ".ocw")
+	if err := os.MkdirAll(ocwDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .ocw directory: %w", err)
+	}
+
+	lock := flock.New(s.eventsLockPath())
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire journal lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	f, err := os.OpenFile(s.eventsPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
+
+// ReadEvents returns all journal events matching the filter, oldest first.
+// Lines that cannot be parsed (e.g. a write torn by a crash) are skipped.
+func (s *Store) ReadEvents(filter EventFilter) ([]Event, error) {
+	events := []Event{}
+
+	if _, err := os.Stat(s.eventsPath()); os.IsNotExist(err) {
+		return events, nil
+	}
+
+	lock := flock.New(s.eventsLockPath())
+	if err := lock.RLock(); err != nil {
+		return nil, fmt.Errorf("failed to acquire journal read lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	f, err := os.Open(s.eventsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+
+		if filter.Matches(ev) {
+			events = append(events, ev)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndReadEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	require.NoError(t, store.AppendEvent(Event{Type: EventCreated, InstanceID: "inst1", Instance: "feature-1"}))
+	require.NoError(t, store.AppendEvent(Event{Type: EventPaused, InstanceID: "inst1", Instance: "feature-1", From: "running", To: "paused"}))
+	require.NoError(t, store.AppendEvent(Event{Type: EventCreated, InstanceID: "inst2", Instance: "feature-2"}))
+
+	events, err := store.ReadEvents(EventFilter{})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, EventCreated, events[0].Type)
+	assert.Equal(t, EventPaused, events[1].Type)
+	assert.Equal(t, "paused", events[1].To)
+	assert.False(t, events[0].Time.IsZero(), "AppendEvent should stamp the event time")
+}
+
+func TestReadEventsNonexistentJournal(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	events, err := store.ReadEvents(EventFilter{})
+	require.NoError(t, err)
+
+	assert.NotNil(t, events)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestReadEventsSkipsMalformedLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	require.NoError(t, store.AppendEvent(Event{Type: EventCreated, InstanceID: "inst1"}))
+
+	// Simulate a torn write at the end of the journal
+	f, err := os.OpenFile(filepath.Join(tmpDir, ".ocw", "events.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"type":"paus`)
+	require.NoError(t, err)
+	f.Close()
+
+	events, err := store.ReadEvents(EventFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(events))
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ev := Event{
+		Time:       base,
+		Type:       EventStatusChanged,
+		InstanceID: "abc123",
+		Instance:   "feature-1",
+		Branch:     "feature/one",
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: EventFilter{}, want: true},
+		{name: "match by ID", filter: EventFilter{Instance: "abc123"}, want: true},
+		{name: "match by name", filter: EventFilter{Instance: "feature-1"}, want: true},
+		{name: "match by branch", filter: EventFilter{Instance: "feature/one"}, want: true},
+		{name: "other instance", filter: EventFilter{Instance: "def456"}, want: false},
+		{name: "matching type", filter: EventFilter{Types: []EventType{EventCreated, EventStatusChanged}}, want: true},
+		{name: "other type", filter: EventFilter{Types: []EventType{EventDeleted}}, want: false},
+		{name: "since before", filter: EventFilter{Since: base.Add(-time.Hour)}, want: true},
+		{name: "since after", filter: EventFilter{Since: base.Add(time.Hour)}, want: false},
+		{name: "until after", filter: EventFilter{Until: base.Add(time.Hour)}, want: true},
+		{name: "until before", filter: EventFilter{Until: base.Add(-time.Hour)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(ev))
+		})
+	}
+}
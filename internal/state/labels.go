@@ -0,0 +1,225 @@
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// labelPattern restricts tags and metadata keys to characters that are safe in
+// selectors and on the command line
+var labelPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+
+// ValidateTag checks that a tag can be used in selectors
+func ValidateTag(tag string) error {
+	if !labelPattern.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q (use letters, digits, '-', '_', '.' and '/', starting with a letter or digit)", tag)
+	}
+	return nil
+}
+
+// ValidateMetadataKey checks that a metadata key can be used in selectors
+func ValidateMetadataKey(key string) error {
+	if !labelPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q (use letters, digits, '-', '_', '.' and '/', starting with a letter or digit)", key)
+	}
+	return nil
+}
+
+// ParseMetadata parses key=value pairs, as given to --meta, into a map
+func ParseMetadata(pairs []string) (map[string]string, error) {
+	metadata := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid metadata %q (expected key=value)", pair)
+		}
+		key = strings.TrimSpace(key)
+		if err := ValidateMetadataKey(key); err != nil {
+			return nil, err
+		}
+		if strings.Contains(value, ",") {
+			return nil, fmt.Errorf("invalid metadata %q (values cannot contain ',')", pair)
+		}
+		metadata[key] = strings.TrimSpace(value)
+	}
+	return metadata, nil
+}
+
+// HasTag reports whether the instance carries the given tag
+func (inst *Instance) HasTag(tag string) bool {
+	for _, t := range inst.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds tags the instance does not carry yet, keeping the list sorted
+func (inst *Instance) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !inst.HasTag(tag) {
+			inst.Tags = append(inst.Tags, tag)
+		}
+	}
+	sort.Strings(inst.Tags)
+}
+
+// RemoveTags removes the given tags from the instance
+func (inst *Instance) RemoveTags(tags ...string) {
+	kept := inst.Tags[:0]
+	for _, t := range inst.Tags {
+		remove := false
+		for _, tag := range tags {
+			if t == tag {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	inst.Tags = kept
+}
+
+// Labels renders the tags and metadata of an instance as a selector that matches it,
+// e.g. "urgent,team=infra"
+func (inst *Instance) Labels() string {
+	parts := make([]string, 0, len(inst.Tags)+len(inst.Metadata))
+	parts = append(parts, inst.Tags...)
+
+	keys := make([]string, 0, len(inst.Metadata))
+	for key := range inst.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+inst.Metadata[key])
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// SelectorOp is the comparison made by a selector requirement
+type SelectorOp string
+
+const (
+	SelectorHasTag    SelectorOp = "tag"  // urgent
+	SelectorNotTag    SelectorOp = "!tag" // !urgent
+	SelectorEquals    SelectorOp = "="    // team=infra
+	SelectorNotEquals SelectorOp = "!="   // team!=infra
+)
+
+// Requirement is a single comma-separated term of a selector
+type Requirement struct {
+	Op    SelectorOp
+	Key   string // tag name or metadata key
+	Value string
+}
+
+// Matches reports whether an instance satisfies the requirement
+func (r Requirement) Matches(inst Instance) bool {
+	switch r.Op {
+	case SelectorHasTag:
+		return inst.HasTag(r.Key)
+	case SelectorNotTag:
+		return !inst.HasTag(r.Key)
+	case SelectorEquals:
+		value, ok := inst.Metadata[r.Key]
+		return ok && value == r.Value
+	case SelectorNotEquals:
+		value, ok := inst.Metadata[r.Key]
+		return !ok || value != r.Value
+	}
+	return false
+}
+
+// Selector filters instances by tags and metadata. Every requirement must match;
+// an empty selector matches everything.
+type Selector []Requirement
+
+// ParseSelector parses a comma-separated selector such as "team=infra,ticket=OCW-12".
+// Terms are:
+//   - key=value   metadata key equals value
+//   - key!=value  metadata key is missing or differs from value
+//   - tag         instance carries the tag
+//   - !tag        instance does not carry the tag
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var req Requirement
+		if key, value, ok := strings.Cut(term, "!="); ok {
+			req = Requirement{Op: SelectorNotEquals, Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+		} else if key, value, ok := strings.Cut(term, "="); ok {
+			req = Requirement{Op: SelectorEquals, Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+		} else if tag, ok := strings.CutPrefix(term, "!"); ok {
+			req = Requirement{Op: SelectorNotTag, Key: strings.TrimSpace(tag)}
+		} else {
+			req = Requirement{Op: SelectorHasTag, Key: term}
+		}
+
+		if err := ValidateMetadataKey(req.Key); err != nil {
+			return nil, fmt.Errorf("invalid selector term %q: %w", term, err)
+		}
+		selector = append(selector, req)
+	}
+
+	return selector, nil
+}
+
+// Empty reports whether the selector has no requirements
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// Matches reports whether an instance satisfies every requirement
+func (s Selector) Matches(inst Instance) bool {
+	for _, req := range s {
+		if !req.Matches(inst) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the instances matching the selector, in their original order
+func (s Selector) Filter(instances []Instance) []Instance {
+	if s.Empty() {
+		return instances
+	}
+
+	matched := make([]Instance, 0, len(instances))
+	for _, inst := range instances {
+		if s.Matches(inst) {
+			matched = append(matched, inst)
+		}
+	}
+	return matched
+}
+
+// String renders the selector in the form accepted by ParseSelector
+func (s Selector) String() string {
+	terms := make([]string, len(s))
+	for i, req := range s {
+		switch req.Op {
+		case SelectorHasTag:
+			terms[i] = req.Key
+		case SelectorNotTag:
+			terms[i] = "!" + req.Key
+		default:
+			terms[i] = req.Key + string(req.Op) + req.Value
+		}
+	}
+	return strings.Join(terms, ",")
+}
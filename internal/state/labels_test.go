@@ -0,0 +1,114 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	selector, err := ParseSelector("team=infra, ticket!=OCW-12,urgent,!wip,")
+	require.NoError(t, err)
+
+	assert.Equal(t, Selector{
+		{Op: SelectorEquals, Key: "team", Value: "infra"},
+		{Op: SelectorNotEquals, Key: "ticket", Value: "OCW-12"},
+		{Op: SelectorHasTag, Key: "urgent"},
+		{Op: SelectorNotTag, Key: "wip"},
+	}, selector)
+	assert.Equal(t, "team=infra,ticket!=OCW-12,urgent,!wip", selector.String())
+
+	empty, err := ParseSelector("  ")
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+
+	for _, bad := range []string{"=infra", "!", "team =x,has space", "-leading"} {
+		_, err := ParseSelector(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	inst := Instance{
+		Tags:     []string{"urgent"},
+		Metadata: map[string]string{"team": "infra", "ticket": "OCW-12"},
+	}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"team=infra", true},
+		{"team=infra,ticket=OCW-12", true},
+		{"team=infra,ticket=OCW-13", false},
+		{"team!=web", true},
+		{"owner!=alice", true},
+		{"team!=infra", false},
+		{"urgent", true},
+		{"!urgent", false},
+		{"wip", false},
+		{"!wip,team=infra", true},
+		{"owner=", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, selector.Matches(inst))
+		})
+	}
+}
+
+func TestSelectorFilter(t *testing.T) {
+	instances := []Instance{
+		{ID: "a", Metadata: map[string]string{"team": "infra"}},
+		{ID: "b", Metadata: map[string]string{"team": "web"}},
+		{ID: "c", Tags: []string{"urgent"}, Metadata: map[string]string{"team": "infra"}},
+	}
+
+	selector, err := ParseSelector("team=infra")
+	require.NoError(t, err)
+
+	matched := selector.Filter(instances)
+	require.Equal(t, 2, len(matched))
+	assert.Equal(t, "a", matched[0].ID)
+	assert.Equal(t, "c", matched[1].ID)
+
+	assert.Equal(t, 3, len(Selector(nil).Filter(instances)))
+}
+
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata([]string{"team=infra", "note=", "url=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "infra", "note": "", "url": "a=b"}, metadata)
+
+	_, err = ParseMetadata([]string{"team"})
+	assert.Error(t, err)
+
+	_, err = ParseMetadata([]string{"bad key=x"})
+	assert.Error(t, err)
+
+	_, err = ParseMetadata([]string{"list=a,b"})
+	assert.Error(t, err)
+}
+
+func TestInstanceTags(t *testing.T) {
+	var inst Instance
+
+	inst.AddTags("wip", "urgent", "wip")
+	assert.Equal(t, []string{"urgent", "wip"}, inst.Tags)
+	assert.True(t, inst.HasTag("wip"))
+
+	inst.RemoveTags("wip", "missing")
+	assert.Equal(t, []string{"urgent"}, inst.Tags)
+
+	inst.RemoveTags("urgent")
+	assert.Nil(t, inst.Tags)
+
+	inst.AddTags("urgent")
+	inst.Metadata = map[string]string{"ticket": "OCW-12", "team": "infra"}
+	assert.Equal(t, "urgent,team=infra,ticket=OCW-12", inst.Labels())
+}
@@ -0,0 +1,305 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CurrentSchemaVersion is the state.json schema version written by this build.
+// Bump it together with a new entry in migrations whenever the on-disk format changes.
+const CurrentSchemaVersion = 11
+
+// Migration upgrades a raw state document from schema version From to From+1.
+// Migrations operate on the decoded JSON document rather than on State so that
+// renamed or removed fields are still reachable.
+type Migration struct {
+	From        int
+	Description string
+	Migrate     func(doc map[string]any) error
+}
+
+// migrations is the ordered registry of schema upgrade steps
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "add schema_version and normalize null instance lists",
+		Migrate:     migrateV0ToV1,
+	},
+	{
+		From:        1,
+		Description: "map free-form statuses onto the status state machine",
+		Migrate:     migrateV1ToV2,
+	},
+	{
+		From:        2,
+		Description: "add archive of deleted instances",
+		Migrate:     migrateV2ToV3,
+	},
+	{
+		From:        3,
+		Description: "add instance tags and metadata",
+		Migrate:     migrateV3ToV4,
+	},
+	{
+		From:        4,
+		Description: "add instance task and notes",
+		Migrate:     migrateV4ToV5,
+	},
+	{
+		From:        5,
+		Description: "add instance template and environment",
+		Migrate:     migrateV5ToV6,
+	},
+	{
+		From:        6,
+		Description: "record the agent profile of instances",
+		Migrate:     migrateV6ToV7,
+	},
+	{
+		From:        7,
+		Description: "replace the single instance port with a list of ports",
+		Migrate:     migrateV7ToV8,
+	},
+	{
+		From:        8,
+		Description: "record the initial prompt of instances",
+		Migrate:     migrateV8ToV9,
+	},
+	{
+		From:        9,
+		Description: "add fan-out groups of instances",
+		Migrate:     migrateV9ToV10,
+	},
+	{
+		From:        10,
+		Description: "record the instance a fork was made from",
+		Migrate:     migrateV10ToV11,
+	},
+}
+
+// SchemaVersionError is returned when state.json was written by a newer ocw
+type SchemaVersionError struct {
+	Found     int
+	Supported int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("state file uses schema version %d, but this ocw binary only supports up to version %d\n\nThe state was written by a newer version of ocw.\n\nTo fix:\n  1. Upgrade ocw: go install github.com/tommyzliu/ocw@latest\n  2. Or restore an older backup of .ocw/state.json", e.Found, e.Supported)
+}
+
+// peekSchemaVersion reads only the schema_version field of a state document.
+// Documents written before versioning was introduced report version 0.
+func peekSchemaVersion(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return header.SchemaVersion, nil
+}
+
+// migrateDocument applies every registered migration from version from up to CurrentSchemaVersion
+func migrateDocument(doc map[string]any, from int) error {
+	for version := from; version < CurrentSchemaVersion; version++ {
+		var step *Migration
+		for i := range migrations {
+			if migrations[i].From == version {
+				step = &migrations[i]
+				break
+			}
+		}
+		if step == nil {
+			return fmt.Errorf("no migration registered from schema version %d", version)
+		}
+
+		if err := step.Migrate(doc); err != nil {
+			return fmt.Errorf("migration v%d→v%d (%s) failed: %w", version, version+1, step.Description, err)
+		}
+		doc["schema_version"] = version + 1
+	}
+
+	return nil
+}
+
+// migrateLocked upgrades the state document read from disk to CurrentSchemaVersion.
+// The original file is copied to .ocw/state.json.v<N>.<timestamp>.bak before it is rewritten.
+// Caller must hold the exclusive state lock.
+func (s *Store) migrateLocked(data []byte, from int) (*State, error) {
+	backupPath := filepath.Join(s.dir, ".ocw",
+		fmt.Sprintf("state.json.v%d.%s.bak", from, time.Now().Format("20060102T150405")))
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to back up state before migration: %w", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	if err := migrateDocument(doc, from); err != nil {
+		return nil, fmt.Errorf("failed to migrate state (backup kept at %s): %w", backupPath, err)
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated state: %w", err)
+	}
+
+	state, err := decodeState(migrated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse migrated state: %w", err)
+	}
+
+	if err := s.writeLocked(state); err != nil {
+		return nil, fmt.Errorf("failed to write migrated state (backup kept at %s): %w", backupPath, err)
+	}
+
+	return state, nil
+}
+
+// migrateV0ToV1 replaces null lists left by early versions with empty ones
+func migrateV0ToV1(doc map[string]any) error {
+	instances, ok := doc["instances"].([]any)
+	if !ok {
+		if doc["instances"] != nil {
+			return fmt.Errorf("instances is not a list")
+		}
+		doc["instances"] = []any{}
+		return nil
+	}
+
+	for i, raw := range instances {
+		inst, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("instance %d is not an object", i)
+		}
+		for _, field := range []string{"sub_terminals", "conflicts_with", "depends_on"} {
+			if inst[field] == nil {
+				inst[field] = []any{}
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateV1ToV2 maps the free-form statuses of earlier versions onto Status.
+// "merged" used to be set as soon as a pull request was opened, so it becomes
+// pr-open; "done" meant the work had landed.
+func migrateV1ToV2(doc map[string]any) error {
+	instances, _ := doc["instances"].([]any)
+	for i, raw := range instances {
+		inst, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("instance %d is not an object", i)
+		}
+
+		status, _ := inst["status"].(string)
+		switch status {
+		case "merged":
+			inst["status"] = string(StatusPROpen)
+		case "done":
+			inst["status"] = string(StatusMerged)
+		default:
+			if !Status(status).Valid() {
+				inst["status"] = string(StatusError)
+				inst["status_reason"] = fmt.Sprintf("unknown status %q found during migration", status)
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateV2ToV3 adds the archive list; older binaries would silently drop it on save
+func migrateV2ToV3(doc map[string]any) error {
+	if doc["archive"] == nil {
+		doc["archive"] = []any{}
+	}
+	return nil
+}
+
+// migrateV3ToV4 only bumps the version: tags and metadata are optional fields,
+// but older binaries would silently drop them on save
+func migrateV3ToV4(doc map[string]any) error {
+	return nil
+}
+
+// migrateV4ToV5 only bumps the version so older binaries don't drop task and notes on save
+func migrateV4ToV5(doc map[string]any) error {
+	return nil
+}
+
+// migrateV5ToV6 only bumps the version so older binaries don't drop template and env on save
+func migrateV5ToV6(doc map[string]any) error {
+	return nil
+}
+
+// migrateV6ToV7 records the agent profile of existing instances, archived ones
+// included. Before profiles every instance ran opencode.
+func migrateV6ToV7(doc map[string]any) error {
+	instances, _ := doc["instances"].([]any)
+	archive, _ := doc["archive"].([]any)
+	for _, raw := range archive {
+		if archived, ok := raw.(map[string]any); ok {
+			instances = append(instances, archived["instance"])
+		}
+	}
+
+	for i, raw := range instances {
+		inst, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("instance %d is not an object", i)
+		}
+		if agent, _ := inst["agent"].(string); agent == "" {
+			inst["agent"] = "opencode"
+		}
+	}
+	return nil
+}
+
+// migrateV7ToV8 turns the port of each instance, which was never set by ocw
+// itself, into a one-element ports list
+func migrateV7ToV8(doc map[string]any) error {
+	instances, _ := doc["instances"].([]any)
+	archive, _ := doc["archive"].([]any)
+	for _, raw := range archive {
+		if archived, ok := raw.(map[string]any); ok {
+			instances = append(instances, archived["instance"])
+		}
+	}
+
+	for i, raw := range instances {
+		inst, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("instance %d is not an object", i)
+		}
+		if port, _ := inst["port"].(float64); port > 0 {
+			inst["ports"] = []any{port}
+		}
+		delete(inst, "port")
+	}
+	return nil
+}
+
+// migrateV8ToV9 only bumps the version so older binaries don't drop the prompt on save
+func migrateV8ToV9(doc map[string]any) error {
+	return nil
+}
+
+// migrateV9ToV10 only bumps the version so older binaries don't drop fan-out groups on save
+func migrateV9ToV10(doc map[string]any) error {
+	return nil
+}
+
+// migrateV10ToV11 only bumps the version so older binaries don't drop fork links on save
+func migrateV10ToV11(doc map[string]any) error {
+	return nil
+}
@@ -0,0 +1,183 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRawState(t *testing.T, dir, content string) {
+	t.Helper()
+	ocwDir := filepath.Join(dir, ".ocw")
+	require.NoError(t, os.MkdirAll(ocwDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(ocwDir, "state.json"), []byte(content), 0644))
+}
+
+func TestLoadMigratesUnversionedState(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	legacy := `{
+  "repo": "/test/repo",
+  "tmux_session": "ocw-test",
+  "instances": [
+    {"id": "inst1", "name": "feature-1", "status": "running", "sub_terminals": null, "conflicts_with": null, "depends_on": null}
+  ]
+}`
+	writeRawState(t, tmpDir, legacy)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, CurrentSchemaVersion, loaded.SchemaVersion)
+	require.Equal(t, 1, len(loaded.Instances))
+	assert.Equal(t, "inst1", loaded.Instances[0].ID)
+	assert.NotNil(t, loaded.Instances[0].SubTerminals)
+	assert.NotNil(t, loaded.Instances[0].DependsOn)
+
+	// Original file is backed up untouched
+	backups, err := filepath.Glob(filepath.Join(tmpDir, ".ocw", "state.json.v0.*.bak"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(backups))
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(backup))
+
+	// Migrated file is persisted with the new version
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".ocw", "state.json"))
+	require.NoError(t, err)
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	require.NoError(t, json.Unmarshal(data, &header))
+	assert.Equal(t, CurrentSchemaVersion, header.SchemaVersion)
+
+	// A second load does not migrate again
+	_, err = store.Load()
+	require.NoError(t, err)
+	backups, err = filepath.Glob(filepath.Join(tmpDir, ".ocw", "state.json.v0.*.bak"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(backups))
+}
+
+func TestLoadRejectsNewerSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	writeRawState(t, tmpDir, `{"schema_version": 999, "instances": []}`)
+
+	_, err := store.Load()
+	require.Error(t, err)
+
+	var versionErr *SchemaVersionError
+	require.True(t, errors.As(err, &versionErr))
+	assert.Equal(t, 999, versionErr.Found)
+	assert.Equal(t, CurrentSchemaVersion, versionErr.Supported)
+	assert.Contains(t, err.Error(), "newer version of ocw")
+}
+
+func TestSaveStampsSchemaVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	require.NoError(t, store.Save(&State{Instances: []Instance{}}))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, loaded.SchemaVersion)
+}
+
+func TestMigrationRegistryIsContiguous(t *testing.T) {
+	for version := 0; version < CurrentSchemaVersion; version++ {
+		found := false
+		for _, m := range migrations {
+			if m.From == version {
+				found = true
+				assert.NotEmpty(t, m.Description)
+				assert.NotNil(t, m.Migrate)
+			}
+		}
+		assert.True(t, found, "missing migration from schema version %d", version)
+	}
+}
+
+func TestLoadMigratesFreeFormStatuses(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	writeRawState(t, tmpDir, `{
+  "schema_version": 1,
+  "instances": [
+    {"id": "a", "status": "running"},
+    {"id": "b", "status": "merged", "pr_url": "https://github.com/test/repo/pull/1"},
+    {"id": "c", "status": "done"},
+    {"id": "d", "status": "active"}
+  ]
+}`)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(loaded.Instances))
+
+	assert.Equal(t, StatusRunning, loaded.Instances[0].Status)
+	assert.Equal(t, StatusPROpen, loaded.Instances[1].Status)
+	assert.Equal(t, StatusMerged, loaded.Instances[2].Status)
+	assert.Equal(t, StatusError, loaded.Instances[3].Status)
+	assert.Contains(t, loaded.Instances[3].StatusReason, "active")
+}
+
+func TestLoadRecordsOpenCodeAgent(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	writeRawState(t, tmpDir, `{
+  "schema_version": 6,
+  "instances": [
+    {"id": "a", "status": "running"},
+    {"id": "b", "status": "running", "agent": "aider"}
+  ],
+  "archive": [
+    {"instance": {"id": "c", "status": "exited"}, "head_ref": "refs/ocw/archive/c"}
+  ]
+}`)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(loaded.Instances))
+	require.Equal(t, 1, len(loaded.Archive))
+
+	assert.Equal(t, "opencode", loaded.Instances[0].Agent)
+	assert.Equal(t, "aider", loaded.Instances[1].Agent)
+	assert.Equal(t, "opencode", loaded.Archive[0].Instance.Agent)
+}
+
+func TestLoadMigratesPortToPorts(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	writeRawState(t, tmpDir, `{
+  "schema_version": 7,
+  "instances": [
+    {"id": "a", "status": "running", "port": 8080},
+    {"id": "b", "status": "running"}
+  ],
+  "archive": [
+    {"instance": {"id": "c", "status": "exited", "port": 0}, "head_ref": "refs/ocw/archive/c"}
+  ]
+}`)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(loaded.Instances))
+	require.Equal(t, 1, len(loaded.Archive))
+
+	assert.Equal(t, []int{8080}, loaded.Instances[0].Ports)
+	assert.Equal(t, 8080, loaded.Instances[0].Port())
+	assert.Nil(t, loaded.Instances[1].Ports)
+	assert.Nil(t, loaded.Archive[0].Instance.Ports)
+}
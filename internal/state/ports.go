@@ -0,0 +1,23 @@
+package state
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Port returns the first port allocated to the instance, or 0 if it has none
+func (inst *Instance) Port() int {
+	if len(inst.Ports) == 0 {
+		return 0
+	}
+	return inst.Ports[0]
+}
+
+// PortList renders the instance's ports for display, e.g. "3100,3101"
+func (inst *Instance) PortList() string {
+	ports := make([]string, len(inst.Ports))
+	for i, port := range inst.Ports {
+		ports[i] = strconv.Itoa(port)
+	}
+	return strings.Join(ports, ",")
+}
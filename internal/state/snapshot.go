@@ -0,0 +1,325 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// MaxSnapshots is the number of state snapshots kept in .ocw/snapshots/.
+// Older snapshots are pruned each time a new one is written. Writes that
+// leave the state as it was in the newest snapshot add none, so they cannot
+// rotate out the ones worth restoring.
+const MaxSnapshots = 20
+
+// snapshotTimeLayout sorts lexically in chronological order
+const snapshotTimeLayout = "20060102T150405.000000"
+
+// Snapshot describes a saved copy of state.json
+type Snapshot struct {
+	Name      string
+	Path      string
+	Time      time.Time
+	Revision  int64
+	Instances int
+	// Err is set when the snapshot cannot be loaded
+	Err error
+}
+
+// Valid reports whether the snapshot can be restored
+func (s Snapshot) Valid() bool {
+	return s.Err == nil
+}
+
+func (s *Store) snapshotDir() string {
+	return filepath.Join(s.dir, ".ocw", "snapshots")
+}
+
+// snapshotLocked stores data as a new snapshot and prunes the oldest ones beyond MaxSnapshots.
+// Nothing is stored if data differs from the newest snapshot only in its revision.
+// Caller must hold the exclusive state lock.
+func (s *Store) snapshotLocked(data []byte, revision int64) error {
+	dir := s.snapshotDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	if s.sameAsLatestSnapshot(data) {
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-r%d", time.Now().UTC().Format(snapshotTimeLayout), revision)
+	path := filepath.Join(dir, name+".json")
+
+	// Write via temp file so readers never see a partial snapshot
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	names, err := s.snapshotNames()
+	if err != nil {
+		return err
+	}
+	for len(names) > MaxSnapshots {
+		os.Remove(filepath.Join(dir, names[0]+".json"))
+		names = names[1:]
+	}
+
+	return nil
+}
+
+// sameAsLatestSnapshot reports whether data holds the same state as the
+// newest snapshot, apart from the revision
+func (s *Store) sameAsLatestSnapshot(data []byte) bool {
+	names, err := s.snapshotNames()
+	if err != nil || len(names) == 0 {
+		return false
+	}
+	latest, err := os.ReadFile(filepath.Join(s.snapshotDir(), names[len(names)-1]+".json"))
+	if err != nil {
+		return false
+	}
+
+	a, ok := withoutRevision(latest)
+	if !ok {
+		return false
+	}
+	b, ok := withoutRevision(data)
+	return ok && bytes.Equal(a, b)
+}
+
+// withoutRevision re-encodes serialized state with its revision cleared
+func withoutRevision(data []byte) ([]byte, bool) {
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, false
+	}
+	st.Revision = 0
+	out, err := json.Marshal(st)
+	return out, err == nil
+}
+
+// snapshotNames returns the names of all snapshots, oldest first
+func (s *Store) snapshotNames() ([]string, error) {
+	entries, err := os.ReadDir(s.snapshotDir())
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ListSnapshots returns all snapshots, newest first. Snapshots that cannot be
+// loaded are included with Err set.
+func (s *Store) ListSnapshots() ([]Snapshot, error) {
+	names, err := s.snapshotNames()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		snapshots = append(snapshots, s.inspectSnapshot(names[i]))
+	}
+
+	return snapshots, nil
+}
+
+// inspectSnapshot loads a snapshot by name and summarizes it
+func (s *Store) inspectSnapshot(name string) Snapshot {
+	snap := Snapshot{
+		Name: name,
+		Path: filepath.Join(s.snapshotDir(), name+".json"),
+	}
+
+	if ts, rev, ok := strings.Cut(name, "-r"); ok {
+		snap.Time, _ = time.Parse(snapshotTimeLayout, ts)
+		snap.Revision, _ = strconv.ParseInt(rev, 10, 64)
+	}
+
+	st, err := readSnapshot(snap.Path)
+	if err != nil {
+		snap.Err = err
+		return snap
+	}
+	snap.Revision = st.Revision
+	snap.Instances = len(st.Instances)
+
+	return snap
+}
+
+// readSnapshot parses a snapshot file, migrating it in memory if it was
+// written by an older schema version
+func readSnapshot(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	version, err := peekSchemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentSchemaVersion {
+		return nil, &SchemaVersionError{Found: version, Supported: CurrentSchemaVersion}
+	}
+	if version < CurrentSchemaVersion {
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %w", err)
+		}
+		if err := migrateDocument(doc, version); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("failed to marshal migrated state: %w", err)
+		}
+	}
+
+	return decodeState(data)
+}
+
+// ResolveSnapshot finds a snapshot by exact name, unique name prefix, or
+// revision number (e.g. "42" or "r42"; the newest snapshot of that revision wins)
+func (s *Store) ResolveSnapshot(ref string) (Snapshot, error) {
+	names, err := s.snapshotNames()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	for _, name := range names {
+		if name == ref {
+			return s.inspectSnapshot(name), nil
+		}
+	}
+
+	if rev, err := strconv.ParseInt(strings.TrimPrefix(ref, "r"), 10, 64); err == nil {
+		suffix := fmt.Sprintf("-r%d", rev)
+		for i := len(names) - 1; i >= 0; i-- {
+			if strings.HasSuffix(names[i], suffix) {
+				return s.inspectSnapshot(names[i]), nil
+			}
+		}
+	}
+
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, ref) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 1 {
+		return s.inspectSnapshot(matches[0]), nil
+	}
+	if len(matches) > 1 {
+		return Snapshot{}, fmt.Errorf("snapshot %q is ambiguous (%d matches)\n\nTo fix:\n  1. List snapshots: ocw state list-snapshots\n  2. Use the full snapshot name", ref, len(matches))
+	}
+
+	return Snapshot{}, fmt.Errorf("snapshot %q not found\n\nTo fix:\n  1. List snapshots: ocw state list-snapshots\n  2. Use a snapshot name or revision number", ref)
+}
+
+// LoadSnapshot reads the state stored in a snapshot
+func (s *Store) LoadSnapshot(ref string) (*State, error) {
+	snap, err := s.ResolveSnapshot(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.Valid() {
+		return nil, fmt.Errorf("snapshot %s is not valid: %w", snap.Name, snap.Err)
+	}
+
+	return readSnapshot(snap.Path)
+}
+
+// LatestValidSnapshot returns the newest snapshot that can be restored, or nil if there is none
+func (s *Store) LatestValidSnapshot() (*Snapshot, error) {
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range snapshots {
+		if snapshots[i].Valid() {
+			return &snapshots[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Rollback replaces state.json with the contents of a snapshot. The restored state
+// gets a new revision, so processes holding state loaded before the rollback cannot
+// overwrite it, and the rollback itself is snapshotted and can be undone.
+// It works even when state.json is corrupt.
+func (s *Store) Rollback(ref string) (*Snapshot, error) {
+	ocwDir := filepath.Join(s.dir, ".ocw")
+	if err := os.MkdirAll(ocwDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create .ocw directory: %w", err)
+	}
+
+	lock := flock.New(s.lockPath())
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("failed to acquire write lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	snap, err := s.ResolveSnapshot(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.Valid() {
+		return nil, fmt.Errorf("snapshot %s is not valid: %w", snap.Name, snap.Err)
+	}
+
+	restored, err := readSnapshot(snap.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A corrupt state.json reports revision 0, so also look past the newest
+	// snapshot to keep revisions increasing
+	revision, err := s.diskRevisionLocked()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range snapshots {
+		if other.Revision > revision {
+			revision = other.Revision
+		}
+	}
+
+	if err := s.commitLocked(restored, revision+1); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", snap.Name, err)
+	}
+
+	return &snap, nil
+}
@@ -0,0 +1,216 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveWritesSnapshots(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+	require.NoError(t, store.AddInstance(Instance{ID: "inst2"}))
+
+	snapshots, err := store.ListSnapshots()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(snapshots))
+
+	// Newest first
+	assert.Equal(t, int64(2), snapshots[0].Revision)
+	assert.Equal(t, 2, snapshots[0].Instances)
+	assert.Equal(t, int64(1), snapshots[1].Revision)
+	assert.Equal(t, 1, snapshots[1].Instances)
+	assert.True(t, snapshots[0].Valid())
+	assert.False(t, snapshots[0].Time.IsZero())
+}
+
+func TestSnapshotsArePruned(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for i := 0; i < MaxSnapshots+5; i++ {
+		require.NoError(t, store.AddInstance(Instance{ID: fmt.Sprintf("inst%d", i)}))
+	}
+
+	snapshots, err := store.ListSnapshots()
+	require.NoError(t, err)
+	require.Equal(t, MaxSnapshots, len(snapshots))
+	assert.Equal(t, int64(MaxSnapshots+5), snapshots[0].Revision)
+	assert.Equal(t, int64(6), snapshots[len(snapshots)-1].Revision)
+}
+
+func TestUnchangedWritesKeepSnapshots(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for i := 0; i < MaxSnapshots; i++ {
+		require.NoError(t, store.AddInstance(Instance{ID: fmt.Sprintf("inst%d", i)}))
+	}
+
+	for i := 0; i < MaxSnapshots; i++ {
+		require.NoError(t, store.Transact(func(*State) error { return nil }))
+	}
+
+	snapshots, err := store.ListSnapshots()
+	require.NoError(t, err)
+	require.Equal(t, MaxSnapshots, len(snapshots))
+	assert.Equal(t, int64(MaxSnapshots), snapshots[0].Revision)
+	assert.Equal(t, int64(1), snapshots[len(snapshots)-1].Revision, "the oldest snapshot is kept")
+
+	// A change is snapshotted again
+	require.NoError(t, store.RemoveInstance("inst0"))
+	snapshots, err = store.ListSnapshots()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*MaxSnapshots+1), snapshots[0].Revision)
+}
+
+func TestRollback(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1", Status: "running"}))
+	require.NoError(t, store.AddInstance(Instance{ID: "inst2", Status: "running"}))
+
+	stale, err := store.Load()
+	require.NoError(t, err)
+
+	snap, err := store.Rollback("r1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), snap.Revision)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(loaded.Instances))
+	assert.Equal(t, "inst1", loaded.Instances[0].ID)
+	assert.Equal(t, int64(3), loaded.Revision, "rollback should get a new revision")
+
+	// State loaded before the rollback can no longer be saved
+	assert.ErrorIs(t, store.Save(stale), ErrStaleState)
+
+	// The rollback itself is snapshotted
+	latest, err := store.LatestValidSnapshot()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, int64(3), latest.Revision)
+}
+
+func TestRollbackCorruptState(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+	require.NoError(t, store.AddInstance(Instance{ID: "inst2"}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".ocw", "state.json"), []byte(`{"instances": [`), 0644))
+	_, err := store.Load()
+	require.Error(t, err)
+
+	latest, err := store.LatestValidSnapshot()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+
+	_, err = store.Rollback(latest.Name)
+	require.NoError(t, err)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(loaded.Instances))
+	assert.Equal(t, int64(3), loaded.Revision)
+}
+
+func TestLatestValidSnapshotSkipsCorrupt(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+	require.NoError(t, store.AddInstance(Instance{ID: "inst2"}))
+
+	snapshots, err := store.ListSnapshots()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(snapshots[0].Path, []byte("not json"), 0644))
+
+	snapshots, err = store.ListSnapshots()
+	require.NoError(t, err)
+	assert.False(t, snapshots[0].Valid())
+
+	latest, err := store.LatestValidSnapshot()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, int64(1), latest.Revision)
+
+	_, err = store.Rollback(snapshots[0].Name)
+	assert.Error(t, err)
+}
+
+func TestLatestValidSnapshotNone(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	latest, err := store.LatestValidSnapshot()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+	require.NoError(t, store.AddInstance(Instance{ID: "inst2"}))
+
+	snapshots, err := store.ListSnapshots()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    int64
+		wantErr bool
+	}{
+		{name: "full name", ref: snapshots[1].Name, want: 1},
+		{name: "revision", ref: "2", want: 2},
+		{name: "prefixed revision", ref: "r1", want: 1},
+		{name: "unknown revision", ref: "r9", wantErr: true},
+		{name: "unknown name", ref: "nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, err := store.ResolveSnapshot(tt.ref)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, snap.Revision)
+		})
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	from := &State{Instances: []Instance{
+		{ID: "a", Name: "alpha", Status: "running"},
+		{ID: "b", Name: "beta", Status: "running"},
+	}}
+	to := &State{Instances: []Instance{
+		{ID: "a", Name: "alpha", Status: "error", DependsOn: []string{"c"}},
+		{ID: "c", Name: "gamma", Status: "running"},
+	}}
+
+	changes := DiffStates(from, to)
+	require.Equal(t, 3, len(changes))
+
+	assert.Equal(t, ChangeChanged, changes[0].Kind)
+	assert.Equal(t, "a", changes[0].InstanceID)
+	assert.Equal(t, []FieldChange{
+		{Field: "status", From: "running", To: "error"},
+		{Field: "depends_on", From: "[]", To: "[c]"},
+	}, changes[0].Fields)
+
+	assert.Equal(t, ChangeAdded, changes[1].Kind)
+	assert.Equal(t, "c", changes[1].InstanceID)
+
+	assert.Equal(t, ChangeRemoved, changes[2].Kind)
+	assert.Equal(t, "b", changes[2].InstanceID)
+
+	assert.Empty(t, DiffStates(to, to))
+}
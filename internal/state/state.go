@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,29 +15,75 @@ import (
 
 // State represents the complete OCW state
 type State struct {
-	Repo        string     `json:"repo"`
-	TmuxSession string     `json:"tmux_session"`
-	Instances   []Instance `json:"instances"`
+	SchemaVersion int        `json:"schema_version"`
+	Revision      int64      `json:"revision"`
+	Repo          string     `json:"repo"`
+	TmuxSession   string     `json:"tmux_session"`
+	Instances     []Instance `json:"instances"`
+	// Archive holds deleted instances that can still be restored
+	Archive []ArchivedInstance `json:"archive"`
 }
 
 // Instance represents a single OCW instance
 type Instance struct {
-	ID            string        `json:"id"`
-	Name          string        `json:"name"`
-	Branch        string        `json:"branch"`
-	BaseBranch    string        `json:"base_branch"`
-	WorktreePath  string        `json:"worktree_path"`
-	TmuxWindow    string        `json:"tmux_window"`
-	PrimaryPane   string        `json:"primary_pane"`
-	SubTerminals  []SubTerminal `json:"sub_terminals"`
-	PID           int           `json:"pid"`
-	Port          int           `json:"port,omitempty"`
-	Status        string        `json:"status"`
-	CreatedAt     time.Time     `json:"created_at"`
-	LastActivity  time.Time     `json:"last_activity"`
-	PRUrl         string        `json:"pr_url,omitempty"`
-	ConflictsWith []string      `json:"conflicts_with"`
-	DependsOn     []string      `json:"depends_on"`
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	Branch          string        `json:"branch"`
+	BaseBranch      string        `json:"base_branch"`
+	WorktreePath    string        `json:"worktree_path"`
+	TmuxWindow      string        `json:"tmux_window"`
+	PrimaryPane     string        `json:"primary_pane"`
+	SubTerminals    []SubTerminal `json:"sub_terminals"`
+	PID             int           `json:"pid"`
+	Ports           []int         `json:"ports,omitempty"` // allocated from the [ports] range, first is OCW_PORT
+	Status          Status        `json:"status"`
+	StatusReason    string        `json:"status_reason,omitempty"`
+	StatusChangedAt time.Time     `json:"status_changed_at"`
+	CreatedAt       time.Time     `json:"created_at"`
+	LastActivity    time.Time     `json:"last_activity"`
+	PRUrl           string        `json:"pr_url,omitempty"`
+	ConflictsWith   []string      `json:"conflicts_with"`
+	DependsOn       []string      `json:"depends_on"`
+	// Tags and Metadata are user-defined labels matched by selectors
+	Tags     []string          `json:"tags,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Task is the multi-line description of the work given to the agent
+	Task string `json:"task,omitempty"`
+	// Notes is an append-only log of remarks about the instance
+	Notes []Note `json:"notes,omitempty"`
+	// Template is the name of the template the instance was created from
+	Template string `json:"template,omitempty"`
+	// Env holds extra environment variables exported into the instance's panes
+	Env map[string]string `json:"env,omitempty"`
+	// Agent is the name of the agent profile running in the primary pane
+	Agent string `json:"agent,omitempty"`
+	// Prompt is the initial prompt delivered to the agent once it was ready
+	Prompt string `json:"prompt,omitempty"`
+	// FanOut is the ID of the fan-out the instance is a sibling in, until a
+	// winner is picked
+	FanOut string `json:"fan_out,omitempty"`
+	// Variant is the matrix cell a fan-out sibling runs, e.g. "model=opus"
+	Variant string `json:"variant,omitempty"`
+	// ForkedFrom is the ID of the instance this one was forked from
+	ForkedFrom string `json:"forked_from,omitempty"`
+}
+
+// Note is a timestamped entry in an instance's notes log
+type Note struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// ArchivedInstance is a deleted instance together with the git refs that keep its work reachable
+type ArchivedInstance struct {
+	Instance   Instance  `json:"instance"`
+	ArchivedAt time.Time `json:"archived_at"`
+	// HeadRef points at the branch tip at deletion time (refs/ocw/archive/<id>)
+	HeadRef string `json:"head_ref"`
+	HeadSHA string `json:"head_sha"`
+	// StashRef points at a stash commit of uncommitted changes, if any were stashed
+	StashRef      string `json:"stash_ref,omitempty"`
+	BranchDeleted bool   `json:"branch_deleted"`
 }
 
 // SubTerminal represents a sub-terminal within an instance
@@ -46,6 +93,10 @@ type SubTerminal struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ErrStaleState is returned when saving a state that was loaded at an older revision
+// than the one currently on disk, i.e. another writer saved in the meantime.
+var ErrStaleState = errors.New("state was modified by another process since it was loaded")
+
 // Store manages state persistence with file locking
 type Store struct {
 	dir string
@@ -64,7 +115,9 @@ func (s *Store) lockPath() string {
 	return filepath.Join(s.dir, ".ocw", "state.json.lock")
 }
 
-// Load reads the state from state.json with read lock
+// Load reads the state from state.json with read lock.
+// State written by an older schema is migrated (and backed up) first;
+// state written by a newer ocw returns a *SchemaVersionError.
 func (s *Store) Load() (*State, error) {
 	statePath := s.statePath()
 	lockPath := s.lockPath()
@@ -85,9 +138,7 @@ func (s *Store) Load() (*State, error) {
 	// Check if state file exists
 	if _, err := os.Stat(statePath); os.IsNotExist(err) {
 		// Return empty state if file doesn't exist
-		return &State{
-			Instances: []Instance{},
-		}, nil
+		return newEmptyState(), nil
 	}
 
 	// Read file
@@ -96,7 +147,65 @@ func (s *Store) Load() (*State, error) {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	// Parse JSON
+	version, err := peekSchemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentSchemaVersion {
+		return nil, &SchemaVersionError{Found: version, Supported: CurrentSchemaVersion}
+	}
+	if version < CurrentSchemaVersion {
+		// Migration rewrites the file, so trade the read lock for the write lock
+		lock.Unlock()
+
+		writeLock := flock.New(lockPath)
+		if err := writeLock.Lock(); err != nil {
+			return nil, fmt.Errorf("failed to acquire write lock: %w", err)
+		}
+		defer writeLock.Unlock()
+
+		return s.loadLocked()
+	}
+
+	return decodeState(data)
+}
+
+// loadLocked reads state.json, migrating it to the current schema if needed.
+// Caller must hold the exclusive state lock.
+func (s *Store) loadLocked() (*State, error) {
+	data, err := os.ReadFile(s.statePath())
+	if os.IsNotExist(err) {
+		return newEmptyState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	version, err := peekSchemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentSchemaVersion {
+		return nil, &SchemaVersionError{Found: version, Supported: CurrentSchemaVersion}
+	}
+	if version < CurrentSchemaVersion {
+		return s.migrateLocked(data, version)
+	}
+
+	return decodeState(data)
+}
+
+// newEmptyState returns the state used when no state.json exists yet
+func newEmptyState() *State {
+	return &State{
+		SchemaVersion: CurrentSchemaVersion,
+		Instances:     []Instance{},
+		Archive:       []ArchivedInstance{},
+	}
+}
+
+// decodeState parses a state document that is already at the current schema version
+func decodeState(data []byte) (*State, error) {
 	var state State
 	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
@@ -106,13 +215,17 @@ func (s *Store) Load() (*State, error) {
 	if state.Instances == nil {
 		state.Instances = []Instance{}
 	}
+	if state.Archive == nil {
+		state.Archive = []ArchivedInstance{}
+	}
 
 	return &state, nil
 }
 
-// Save writes the state to state.json with write lock and atomic write
+// Save writes the state to state.json with write lock and atomic write.
+// It returns ErrStaleState if state.json was saved by someone else after this
+// state was loaded; use Transact for read-modify-write cycles.
 func (s *Store) Save(state *State) error {
-	statePath := s.statePath()
 	lockPath := s.lockPath()
 
 	// Ensure .ocw directory exists
@@ -128,93 +241,169 @@ func (s *Store) Save(state *State) error {
 	}
 	defer lock.Unlock()
 
+	return s.writeLocked(state)
+}
+
+// Transact runs fn against the latest state while holding the exclusive state lock
+// across the whole read-modify-write cycle. If fn returns an error nothing is written
+// and the error is returned unchanged.
+func (s *Store) Transact(fn func(*State) error) error {
+	ocwDir := filepath.Join(s.dir, ".ocw")
+	if err := os.MkdirAll(ocwDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .ocw directory: %w", err)
+	}
+
+	lock := flock.New(s.lockPath())
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire write lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	state, err := s.loadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	if err := s.writeLocked(state); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	return nil
+}
+
+// writeLocked atomically writes state.json stamped with the current schema version
+// and the next revision, rejecting the write if state is not based on the revision
+// currently on disk. Caller must hold the exclusive state lock.
+func (s *Store) writeLocked(state *State) error {
+	current, err := s.diskRevisionLocked()
+	if err != nil {
+		return err
+	}
+	if state.Revision != current {
+		return fmt.Errorf("%w (loaded revision %d, current revision %d)", ErrStaleState, state.Revision, current)
+	}
+
+	return s.commitLocked(state, current+1)
+}
+
+// commitLocked atomically writes state.json at the given revision and records a snapshot of it.
+// On failure state.Revision is left unchanged. Caller must hold the exclusive state lock.
+func (s *Store) commitLocked(state *State, revision int64) error {
+	statePath := s.statePath()
+	previous := state.Revision
+
+	state.SchemaVersion = CurrentSchemaVersion
+	state.Revision = revision
+
 	// Marshal to JSON with indentation
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
+		state.Revision = previous
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
 	// Write to temp file first (atomic write)
 	tmpPath := statePath + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		state.Revision = previous
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 
 	// Rename temp file to actual file (atomic operation)
 	if err := os.Rename(tmpPath, statePath); err != nil {
 		os.Remove(tmpPath) // Clean up temp file on error
+		state.Revision = previous
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
+	// Snapshots are a safety net; failing to write one must not fail the save
+	_ = s.snapshotLocked(data, revision)
+
 	return nil
 }
 
-// AddInstance adds a new instance to the state
-func (s *Store) AddInstance(inst Instance) error {
-	state, err := s.Load()
+// diskRevisionLocked returns the revision of state.json, or 0 if it does not exist
+// or cannot be parsed (a corrupt file may always be overwritten).
+// Caller must hold the exclusive state lock.
+func (s *Store) diskRevisionLocked() (int64, error) {
+	data, err := os.ReadFile(s.statePath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
+		return 0, fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	state.Instances = append(state.Instances, inst)
-
-	if err := s.Save(state); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
+	var header struct {
+		Revision int64 `json:"revision"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, nil
 	}
+	return header.Revision, nil
+}
 
-	return nil
+// AddInstance adds a new instance to the state
+func (s *Store) AddInstance(inst Instance) error {
+	return s.Transact(func(state *State) error {
+		state.Instances = append(state.Instances, inst)
+		return nil
+	})
+}
+
+// PutInstance replaces the instance with the same ID, or adds inst if there
+// is none
+func (s *Store) PutInstance(inst Instance) error {
+	return s.Transact(func(state *State) error {
+		for i := range state.Instances {
+			if state.Instances[i].ID == inst.ID {
+				state.Instances[i] = inst
+				return nil
+			}
+		}
+		state.Instances = append(state.Instances, inst)
+		return nil
+	})
 }
 
 // RemoveInstance removes an instance from the state by ID
 func (s *Store) RemoveInstance(id string) error {
-	state, err := s.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
-	}
-
-	// Filter out the instance with the specified ID
-	filtered := make([]Instance, 0, len(state.Instances))
-	for _, inst := range state.Instances {
-		if inst.ID != id {
-			filtered = append(filtered, inst)
+	return s.Transact(func(state *State) error {
+		// Filter out the instance with the specified ID
+		filtered := make([]Instance, 0, len(state.Instances))
+		for _, inst := range state.Instances {
+			if inst.ID != id {
+				filtered = append(filtered, inst)
+			}
 		}
-	}
-
-	state.Instances = filtered
-
-	if err := s.Save(state); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
-	}
 
-	return nil
+		state.Instances = filtered
+		return nil
+	})
 }
 
-// UpdateInstance updates an instance by ID using the provided function
+// UpdateInstance updates an instance by ID using the provided function.
+// fn must not change the status; use TransitionInstance for that.
 func (s *Store) UpdateInstance(id string, fn func(*Instance)) error {
-	state, err := s.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
-	}
-
-	// Find and update the instance
-	found := false
-	for i := range state.Instances {
-		if state.Instances[i].ID == id {
-			fn(&state.Instances[i])
-			found = true
-			break
+	return s.Transact(func(state *State) error {
+		// Find and update the instance
+		for i := range state.Instances {
+			if state.Instances[i].ID == id {
+				status := state.Instances[i].Status
+				fn(&state.Instances[i])
+				if state.Instances[i].Status != status {
+					return fmt.Errorf("status of instance %s must be changed with TransitionInstance", id)
+				}
+				return nil
+			}
 		}
-	}
 
-	if !found {
 		return fmt.Errorf("instance with ID %s not found", id)
-	}
-
-	if err := s.Save(state); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
-	}
-
-	return nil
+	})
 }
 
 // GenerateID generates a unique 6-character hex ID
@@ -48,7 +48,7 @@ func TestStateJSONRoundtrip(t *testing.T) {
 						PrimaryPane:   "1.1",
 						SubTerminals:  []SubTerminal{},
 						PID:           12345,
-						Port:          8080,
+						Ports:         []int{8080},
 						Status:        "active",
 						CreatedAt:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
 						LastActivity:  time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
@@ -266,7 +266,7 @@ func TestUpdateInstance(t *testing.T) {
 
 	// Update instance
 	err = store.UpdateInstance("inst1", func(inst *Instance) {
-		inst.Status = "completed"
+		inst.Name = "feature-one"
 		inst.PRUrl = "https://github.com/test/repo/pull/1"
 	})
 	require.NoError(t, err)
@@ -276,10 +276,27 @@ func TestUpdateInstance(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, 1, len(loaded.Instances))
-	assert.Equal(t, "completed", loaded.Instances[0].Status)
+	assert.Equal(t, "feature-one", loaded.Instances[0].Name)
 	assert.Equal(t, "https://github.com/test/repo/pull/1", loaded.Instances[0].PRUrl)
 }
 
+func TestUpdateInstanceRejectsStatusChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(tmpDir)
+
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1", Status: StatusRunning}))
+
+	err := store.UpdateInstance("inst1", func(inst *Instance) {
+		inst.Status = StatusMerged
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TransitionInstance")
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, loaded.Instances[0].Status)
+}
+
 func TestUpdateInstanceNotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := NewStore(tmpDir)
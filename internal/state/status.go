@@ -0,0 +1,157 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status is the lifecycle state of an instance
+type Status string
+
+const (
+	// StatusCreating: the instance is being set up; until its tmux window
+	// exists it only holds its reserved ports
+	StatusCreating Status = "creating"
+	// StatusRunning: the agent is running and working
+	StatusRunning Status = "running"
+	// StatusIdle: the agent is running but waiting for input
+	StatusIdle Status = "idle"
+	// StatusPaused: the agent was stopped with SIGSTOP
+	StatusPaused Status = "paused"
+	// StatusExited: the agent exited; the worktree is intact
+	StatusExited Status = "exited"
+	// StatusError: the instance needs manual intervention
+	StatusError Status = "error"
+	// StatusPROpen: a pull request was opened and is awaiting merge
+	StatusPROpen Status = "pr-open"
+	// StatusMerged: the pull request was merged (terminal)
+	StatusMerged Status = "merged"
+	// StatusAbandoned: the work was given up (terminal)
+	StatusAbandoned Status = "abandoned"
+)
+
+// Statuses lists every valid status in lifecycle order
+var Statuses = []Status{
+	StatusCreating,
+	StatusRunning,
+	StatusIdle,
+	StatusPaused,
+	StatusExited,
+	StatusError,
+	StatusPROpen,
+	StatusMerged,
+	StatusAbandoned,
+}
+
+// transitions lists the statuses reachable from each status.
+// Any non-terminal status may move to error or abandoned.
+var transitions = map[Status][]Status{
+	StatusCreating:  {StatusRunning, StatusError, StatusAbandoned},
+	StatusRunning:   {StatusIdle, StatusPaused, StatusExited, StatusPROpen, StatusError, StatusAbandoned},
+	StatusIdle:      {StatusRunning, StatusPaused, StatusExited, StatusPROpen, StatusError, StatusAbandoned},
+	StatusPaused:    {StatusRunning, StatusExited, StatusError, StatusAbandoned},
+	StatusExited:    {StatusRunning, StatusPROpen, StatusError, StatusAbandoned},
+	StatusError:     {StatusRunning, StatusExited, StatusPROpen, StatusAbandoned},
+	StatusPROpen:    {StatusRunning, StatusIdle, StatusExited, StatusMerged, StatusError, StatusAbandoned},
+	StatusMerged:    {},
+	StatusAbandoned: {},
+}
+
+// Valid reports whether s is a known status
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Terminal reports whether no further transitions are possible from s
+func (s Status) Terminal() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+// Live reports whether an agent process is expected to exist in this status
+func (s Status) Live() bool {
+	return s == StatusRunning || s == StatusIdle || s == StatusPaused
+}
+
+// TransitionError is returned for a status change the state machine does not allow
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	if !e.To.Valid() {
+		return fmt.Sprintf("unknown status %q", e.To)
+	}
+	if !e.From.Valid() {
+		return fmt.Sprintf("cannot change status from unknown status %q to %s", e.From, e.To)
+	}
+	if e.From.Terminal() {
+		return fmt.Sprintf("cannot change status from %s to %s: %s is final", e.From, e.To, e.From)
+	}
+
+	allowed := make([]string, 0, len(transitions[e.From]))
+	for _, s := range transitions[e.From] {
+		allowed = append(allowed, string(s))
+	}
+	return fmt.Sprintf("cannot change status from %s to %s (allowed: %s)", e.From, e.To, strings.Join(allowed, ", "))
+}
+
+// CanTransition reports whether an instance may move from one status to another.
+// Staying in the same status is always allowed.
+func CanTransition(from, to Status) bool {
+	if from == to {
+		return to.Valid()
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the instance to a new status, recording why.
+// Illegal moves return a *TransitionError and leave the instance unchanged;
+// staying in the same status is a no-op.
+func (inst *Instance) Transition(to Status, reason string) error {
+	if !CanTransition(inst.Status, to) {
+		return &TransitionError{From: inst.Status, To: to}
+	}
+	if inst.Status == to {
+		return nil
+	}
+
+	inst.Status = to
+	inst.StatusReason = reason
+	inst.StatusChangedAt = time.Now()
+	return nil
+}
+
+// TransitionInstance moves an instance to a new status in a single transaction.
+// fn, if not nil, may update other fields once the transition has been accepted.
+// It returns the instance as it was before the change.
+func (s *Store) TransitionInstance(id string, to Status, reason string, fn func(*Instance)) (Instance, error) {
+	var before Instance
+	err := s.Transact(func(state *State) error {
+		for i := range state.Instances {
+			if state.Instances[i].ID != id {
+				continue
+			}
+
+			before = state.Instances[i]
+			if err := state.Instances[i].Transition(to, reason); err != nil {
+				return err
+			}
+			if fn != nil {
+				fn(&state.Instances[i])
+			}
+			return nil
+		}
+
+		return fmt.Errorf("instance with ID %s not found", id)
+	})
+
+	return before, err
+}
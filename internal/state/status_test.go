@@ -0,0 +1,97 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{StatusCreating, StatusRunning, true},
+		{StatusCreating, StatusPROpen, false},
+		{StatusRunning, StatusPaused, true},
+		{StatusRunning, StatusMerged, false},
+		{StatusPaused, StatusRunning, true},
+		{StatusPaused, StatusPROpen, false},
+		{StatusExited, StatusRunning, true},
+		{StatusError, StatusRunning, true},
+		{StatusPROpen, StatusMerged, true},
+		{StatusPROpen, StatusAbandoned, true},
+		{StatusMerged, StatusRunning, false},
+		{StatusAbandoned, StatusRunning, false},
+		{StatusRunning, StatusRunning, true},
+		{StatusRunning, Status("done"), false},
+		{Status("done"), StatusRunning, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"→"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, CanTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestEveryStatusHasTransitions(t *testing.T) {
+	for _, s := range Statuses {
+		assert.True(t, s.Valid(), "status %s has no transition entry", s)
+		for _, next := range transitions[s] {
+			assert.True(t, next.Valid(), "%s → %s targets an unknown status", s, next)
+		}
+	}
+	assert.Equal(t, len(Statuses), len(transitions))
+
+	assert.True(t, StatusMerged.Terminal())
+	assert.True(t, StatusAbandoned.Terminal())
+	assert.False(t, StatusPROpen.Terminal())
+}
+
+func TestInstanceTransition(t *testing.T) {
+	inst := Instance{ID: "inst1", Status: StatusRunning}
+
+	require.NoError(t, inst.Transition(StatusPaused, "paused by user"))
+	assert.Equal(t, StatusPaused, inst.Status)
+	assert.Equal(t, "paused by user", inst.StatusReason)
+	assert.False(t, inst.StatusChangedAt.IsZero())
+
+	err := inst.Transition(StatusMerged, "should fail")
+	var transitionErr *TransitionError
+	require.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, StatusPaused, transitionErr.From)
+	assert.Equal(t, StatusMerged, transitionErr.To)
+	assert.Contains(t, err.Error(), "allowed:")
+
+	// Rejected transitions leave the instance unchanged
+	assert.Equal(t, StatusPaused, inst.Status)
+	assert.Equal(t, "paused by user", inst.StatusReason)
+}
+
+func TestTransitionInstance(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1", Status: StatusRunning}))
+
+	before, err := store.TransitionInstance("inst1", StatusPROpen, "pull request created", func(inst *Instance) {
+		inst.PRUrl = "https://github.com/test/repo/pull/1"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, before.Status)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, StatusPROpen, loaded.Instances[0].Status)
+	assert.Equal(t, "pull request created", loaded.Instances[0].StatusReason)
+	assert.Equal(t, "https://github.com/test/repo/pull/1", loaded.Instances[0].PRUrl)
+
+	_, err = store.TransitionInstance("inst1", StatusPaused, "", nil)
+	assert.Error(t, err)
+
+	_, err = store.TransitionInstance("missing", StatusRunning, "", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
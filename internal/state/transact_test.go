@@ -0,0 +1,156 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	helperDirEnv   = "OCW_TRANSACT_HELPER_DIR"
+	helperNameEnv  = "OCW_TRANSACT_HELPER_NAME"
+	helperCountEnv = "OCW_TRANSACT_HELPER_COUNT"
+)
+
+func TestTransactIncrementsRevision(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.Transact(func(st *State) error {
+			st.Instances = append(st.Instances, Instance{ID: fmt.Sprintf("inst%d", i)})
+			return nil
+		}))
+	}
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), loaded.Revision)
+	assert.Equal(t, 3, len(loaded.Instances))
+}
+
+func TestTransactErrorWritesNothing(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+
+	sentinel := errors.New("abort")
+	err := store.Transact(func(st *State) error {
+		st.Instances = nil
+		return sentinel
+	})
+	assert.ErrorIs(t, err, sentinel)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), loaded.Revision)
+	assert.Equal(t, 1, len(loaded.Instances))
+}
+
+func TestSaveRejectsStaleState(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.AddInstance(Instance{ID: "inst1"}))
+
+	first, err := store.Load()
+	require.NoError(t, err)
+	second, err := store.Load()
+	require.NoError(t, err)
+
+	first.Instances[0].Name = "first"
+	require.NoError(t, store.Save(first))
+
+	second.Instances[0].Name = "second"
+	err = store.Save(second)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStaleState)
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "first", loaded.Instances[0].Name)
+}
+
+func TestTransactConcurrentGoroutines(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	const workers = 16
+	const perWorker = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each goroutine uses its own Store, like separate commands would
+			s := NewStore(store.dir)
+			for i := 0; i < perWorker; i++ {
+				errs <- s.AddInstance(Instance{ID: fmt.Sprintf("g%d-%d", w, i)})
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, workers*perWorker, len(loaded.Instances))
+	assert.Equal(t, int64(workers*perWorker), loaded.Revision)
+}
+
+func TestTransactConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns subprocesses")
+	}
+
+	dir := t.TempDir()
+
+	const processes = 4
+	const perProcess = 15
+
+	cmds := make([]*exec.Cmd, processes)
+	for p := 0; p < processes; p++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestTransactHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			helperDirEnv+"="+dir,
+			helperNameEnv+"="+fmt.Sprintf("p%d", p),
+			helperCountEnv+"="+strconv.Itoa(perProcess),
+		)
+		require.NoError(t, cmd.Start())
+		cmds[p] = cmd
+	}
+
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Wait())
+	}
+
+	loaded, err := NewStore(dir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, processes*perProcess, len(loaded.Instances))
+	assert.Equal(t, int64(processes*perProcess), loaded.Revision)
+}
+
+// TestTransactHelperProcess is run as a subprocess by TestTransactConcurrentProcesses
+func TestTransactHelperProcess(t *testing.T) {
+	dir := os.Getenv(helperDirEnv)
+	if dir == "" {
+		t.Skip("helper process only")
+	}
+
+	name := os.Getenv(helperNameEnv)
+	count, err := strconv.Atoi(os.Getenv(helperCountEnv))
+	require.NoError(t, err)
+
+	store := NewStore(dir)
+	for i := 0; i < count; i++ {
+		require.NoError(t, store.AddInstance(Instance{ID: fmt.Sprintf("%s-%d", name, i)}))
+	}
+}
@@ -8,17 +8,19 @@ import (
 
 // PaneInfo contains information about a tmux pane.
 type PaneInfo struct {
-	ID      string
-	PID     int
-	Dead    bool
-	Command string
+	ID         string
+	PID        int
+	Dead       bool
+	ExitStatus int // exit status of a dead pane's process
+	Command    string
 }
 
 // SplitWindow splits a window or pane into two panes.
 // split should be "horizontal" (left/right) or "vertical" (top/bottom).
 // percentage is the size of the new pane (0-100).
+// env holds VAR=value pairs set in the environment of the new pane's shell.
 // Returns the new pane ID.
-func (t *Tmux) SplitWindow(target, dir, split string, percentage int) (string, error) {
+func (t *Tmux) SplitWindow(target, dir, split string, percentage int, env ...string) (string, error) {
 	args := []string{"split-window", "-t", target, "-P", "-F", "#{pane_id}"}
 
 	// Map split direction to tmux flags
@@ -38,6 +40,7 @@ func (t *Tmux) SplitWindow(target, dir, split string, percentage int) (string, e
 	if dir != "" {
 		args = append(args, "-c", dir)
 	}
+	args = append(args, envArgs(env)...)
 
 	output, err := t.run(args...)
 	if err != nil {
@@ -55,9 +58,26 @@ func (t *Tmux) KillPane(target string) error {
 	return nil
 }
 
+// RespawnPane replaces the process of a pane with command, run by the default
+// shell in dir. The pane's current process is killed.
+// env holds VAR=value pairs set in the environment of the new process.
+func (t *Tmux) RespawnPane(target, dir, command string, env ...string) error {
+	args := []string{"respawn-pane", "-k", "-t", target}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	args = append(args, envArgs(env)...)
+	args = append(args, command)
+
+	if _, err := t.run(args...); err != nil {
+		return fmt.Errorf("failed to respawn pane %q: %w", target, err)
+	}
+	return nil
+}
+
 // ListPanes returns information about all panes in a window.
 func (t *Tmux) ListPanes(window string) ([]PaneInfo, error) {
-	output, err := t.run("list-panes", "-t", window, "-F", "#{pane_id}:#{pane_pid}:#{pane_dead}:#{pane_current_command}")
+	output, err := t.run("list-panes", "-t", window, "-F", "#{pane_id}:#{pane_pid}:#{pane_dead}:#{pane_dead_status}:#{pane_current_command}")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list panes for window %q: %w", window, err)
 	}
@@ -70,19 +90,21 @@ func (t *Tmux) ListPanes(window string) ([]PaneInfo, error) {
 			continue
 		}
 
-		parts := strings.SplitN(line, ":", 4)
-		if len(parts) != 4 {
+		parts := strings.SplitN(line, ":", 5)
+		if len(parts) != 5 {
 			continue
 		}
 
 		pid, _ := strconv.Atoi(parts[1])
 		dead := parts[2] == "1"
+		exitStatus, _ := strconv.Atoi(parts[3])
 
 		panes = append(panes, PaneInfo{
-			ID:      parts[0],
-			PID:     pid,
-			Dead:    dead,
-			Command: parts[3],
+			ID:         parts[0],
+			PID:        pid,
+			Dead:       dead,
+			ExitStatus: exitStatus,
+			Command:    parts[4],
 		})
 	}
 
@@ -99,6 +121,17 @@ func (t *Tmux) CapturePaneContent(target string) (string, error) {
 	return output, nil
 }
 
+// CapturePaneText captures the visible content of a pane as plain text,
+// without escape sequences.
+func (t *Tmux) CapturePaneText(target string) (string, error) {
+	output, err := t.run("capture-pane", "-p", "-J", "-t", target)
+	if err != nil {
+		return "", fmt.Errorf("failed to capture pane %q: %w", target, err)
+	}
+
+	return output, nil
+}
+
 // CapturePaneScrollback captures the full scrollback history of a pane.
 // Uses -S - to start from the beginning of scrollback and -E - for the end.
 func (t *Tmux) CapturePaneScrollback(target string) (string, error) {
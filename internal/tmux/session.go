@@ -2,6 +2,7 @@ package tmux
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -74,3 +75,17 @@ func (t *Tmux) AttachWindow(session, window string) error {
 	}
 	return nil
 }
+
+// SwitchClient moves the current tmux client to another session.
+// Only works when running inside tmux.
+func (t *Tmux) SwitchClient(target string) error {
+	if _, err := t.run("switch-client", "-t", target); err != nil {
+		return fmt.Errorf("failed to switch to session %q: %w", target, err)
+	}
+	return nil
+}
+
+// InsideTmux reports whether the current process runs inside a tmux client.
+func InsideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
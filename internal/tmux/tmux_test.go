@@ -1,9 +1,13 @@
 package tmux
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTmux(t *testing.T) {
@@ -264,3 +268,16 @@ func trimWhitespace(s string) string {
 func isWhitespace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
+
+func TestPasteBufferIsUniquePerCall(t *testing.T) {
+	first := pasteBuffer("%12")
+	second := pasteBuffer("%12")
+
+	// The counter alone tells the names apart, however coarse the clock
+	prefix := fmt.Sprintf("ocw-paste-12-%d-", os.Getpid())
+	require.True(t, strings.HasPrefix(first, prefix), first)
+	require.True(t, strings.HasPrefix(second, prefix), second)
+	n1, _, _ := strings.Cut(strings.TrimPrefix(first, prefix), "-")
+	n2, _, _ := strings.Cut(strings.TrimPrefix(second, prefix), "-")
+	assert.NotEqual(t, n1, n2)
+}
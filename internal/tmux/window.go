@@ -2,7 +2,10 @@ package tmux
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // WindowInfo contains information about a tmux window.
@@ -13,12 +16,14 @@ type WindowInfo struct {
 }
 
 // NewWindow creates a new window in the specified session.
+// env holds VAR=value pairs set in the environment of the window's shell.
 // Returns the window ID.
-func (t *Tmux) NewWindow(session, name, dir string) (string, error) {
+func (t *Tmux) NewWindow(session, name, dir string, env ...string) (string, error) {
 	args := []string{"new-window", "-t", session, "-n", name, "-P", "-F", "#{window_id}"}
 	if dir != "" {
 		args = append(args, "-c", dir)
 	}
+	args = append(args, envArgs(env)...)
 
 	output, err := t.run(args...)
 	if err != nil {
@@ -36,6 +41,14 @@ func (t *Tmux) KillWindow(target string) error {
 	return nil
 }
 
+// RenameWindow changes the name of a tmux window.
+func (t *Tmux) RenameWindow(target, name string) error {
+	if _, err := t.run("rename-window", "-t", target, name); err != nil {
+		return fmt.Errorf("failed to rename window %q to %q: %w", target, name, err)
+	}
+	return nil
+}
+
 // ListWindows returns information about all windows in a session.
 func (t *Tmux) ListWindows(session string) ([]WindowInfo, error) {
 	output, err := t.run("list-windows", "-t", session, "-F", "#{window_id}:#{window_name}:#{window_active}")
@@ -82,6 +95,45 @@ func (t *Tmux) SendKeys(target, keys string) error {
 	return nil
 }
 
+// SendText types text into a target pane literally, without pressing Enter.
+func (t *Tmux) SendText(target, text string) error {
+	if _, err := t.run("send-keys", "-t", target, "-l", text); err != nil {
+		return fmt.Errorf("failed to send text to %q: %w", target, err)
+	}
+	return nil
+}
+
+// PasteText pastes text into a target pane as a bracketed paste, so that
+// applications supporting it take newlines as part of the text.
+func (t *Tmux) PasteText(target, text string) error {
+	buffer := pasteBuffer(target)
+	if _, err := t.run("set-buffer", "-b", buffer, "--", text); err != nil {
+		return fmt.Errorf("failed to set paste buffer: %w", err)
+	}
+	if _, err := t.run("paste-buffer", "-p", "-d", "-b", buffer, "-t", target); err != nil {
+		_, _ = t.run("delete-buffer", "-b", buffer)
+		return fmt.Errorf("failed to paste into %q: %w", target, err)
+	}
+	return nil
+}
+
+// pasteCount tells apart the paste buffers of this process
+var pasteCount atomic.Uint64
+
+// pasteBuffer names a buffer for a single paste into target. Buffers are
+// shared by the whole tmux server, so concurrent pastes each need their own.
+func pasteBuffer(target string) string {
+	return fmt.Sprintf("ocw-paste-%s-%d-%d-%d", strings.TrimPrefix(target, "%"), os.Getpid(), pasteCount.Add(1), time.Now().UnixNano())
+}
+
+// SendKey sends a single key such as Enter or C-c to a target pane.
+func (t *Tmux) SendKey(target, key string) error {
+	if _, err := t.run("send-keys", "-t", target, key); err != nil {
+		return fmt.Errorf("failed to send key %s to %q: %w", key, target, err)
+	}
+	return nil
+}
+
 // RunInWindow creates a new window and executes a command in it.
 // Returns the window ID.
 func (t *Tmux) RunInWindow(session, name, dir, command string) (string, error) {
@@ -96,3 +148,12 @@ func (t *Tmux) RunInWindow(session, name, dir, command string) (string, error) {
 
 	return windowID, nil
 }
+
+// envArgs turns VAR=value pairs into -e flags
+func envArgs(env []string) []string {
+	args := make([]string, 0, 2*len(env))
+	for _, pair := range env {
+		args = append(args, "-e", pair)
+	}
+	return args
+}
@@ -2,13 +2,18 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/tommyzliu/ocw/internal/config"
 	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/registry"
 	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
 	"github.com/tommyzliu/ocw/internal/tui/views"
+	"github.com/tommyzliu/ocw/internal/workspace"
 )
 
 // AppState represents the current state of the application
@@ -25,6 +30,10 @@ const (
 	StateLog             AppState = "log"
 	StateSendPrompt      AppState = "send-prompt"
 	StateSubTerminalList AppState = "subterminal-list"
+	StateFilter          AppState = "filter"
+	StateRepoSwitcher    AppState = "repo-switcher"
+	StateFanOut          AppState = "fanout"
+	StatePickConfirm     AppState = "pick-confirm"
 )
 
 // FocusMsg is sent when user wants to focus on an instance
@@ -43,6 +52,11 @@ type DeleteMsg struct {
 	Error   error
 }
 
+// PickMsg is sent when a fan-out's winner has been picked
+type PickMsg struct {
+	Error error
+}
+
 // SendPromptMsg is sent when prompt sending completes
 type SendPromptMsg struct {
 	Success bool
@@ -65,14 +79,20 @@ type App struct {
 	merge                 *views.Merge
 	help                  *views.Help
 	log                   *views.Log
+	fanout                *views.FanOut
 	err                   error
 	program               *tea.Program
 	deleteInstanceID      string
 	deleteInstanceName    string
+	pick                  views.PickWinnerRequestMsg
 	promptInstanceID      string
 	promptText            string
 	promptFeedback        string
 	subTerminalInstanceID string
+	filterText            string
+	filterError           string
+	repos                 []workspace.RepoStatus
+	repoCursor            int
 }
 
 func NewApp(ctx *Context) *App {
@@ -89,47 +109,30 @@ func NewApp(ctx *Context) *App {
 		program:   nil,
 	}
 
+	if ctx.Config != nil {
+		app.keyMap = NewKeyMap(ctx.Config.Keys, ctx.Config.UI.ASCII)
+		app.styles = NewStyles(ctx.Config.UI, os.Getenv("NO_COLOR") != "")
+	}
+
 	if ctx.Manager != nil {
 		stateData, err := ctx.Manager.Store().Load()
 		if err == nil && stateData != nil {
-			app.instances = stateData.Instances
+			app.instances = ctx.Selector.Filter(stateData.Instances)
 		}
 	}
 
-	statusStyles := views.StatusStyles{
-		Active:   app.styles.StatusActiveStyle,
-		Idle:     app.styles.StatusIdleStyle,
-		Paused:   app.styles.StatusPausedStyle,
-		Error:    app.styles.StatusErrorStyle,
-		Merged:   app.styles.StatusMergedStyle,
-		Done:     app.styles.StatusDoneStyle,
-		Conflict: app.styles.ConflictWarning,
-	}
-
-	app.dashboard = views.NewDashboard(app.instances, statusStyles, ctx.Manager)
+	app.dashboard = views.NewDashboard(app.instances, app.styles.Dashboard(), ctx.Manager, app.keyMap.Dashboard.ListKeyMap)
+	app.dashboard.SetSelector(ctx.Selector.String())
+	app.dashboard.SetHints(app.dashboardHints()...)
 
 	// Initialize create view
-	createStyles := views.CreateStyles{
-		Title:      app.styles.Header,
-		Error:      app.styles.ErrorText,
-		Help:       app.styles.Footer,
-		FormBorder: app.styles.FocusedBorder,
-	}
 	defaultBase := "main"
 	if ctx.Config != nil && ctx.Config.Workspace.BaseBranch != "" {
 		defaultBase = ctx.Config.Workspace.BaseBranch
 	}
-	app.create = views.NewCreate(ctx.Manager, defaultBase, createStyles)
+	app.create = views.NewCreate(ctx.Manager, defaultBase, app.styles.Create(), app.keyMap.Create)
 
-	helpStyles := views.HelpStyles{
-		Title:     app.styles.Header,
-		Header:    app.styles.SelectedItem,
-		Border:    app.styles.FocusedBorder,
-		Text:      lipgloss.NewStyle(),
-		Highlight: app.styles.SelectedItem,
-		Footer:    app.styles.Footer,
-	}
-	app.help = views.NewHelp(helpStyles)
+	app.help = views.NewHelp(app.styles.Help(), app.keyMap.HelpView, app.keyMap.HelpSections())
 	app.help.SetSize(app.width, app.height)
 
 	return app
@@ -173,6 +176,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.log != nil {
 			a.log.SetSize(msg.Width, msg.Height)
 		}
+		if a.fanout != nil {
+			a.fanout.SetSize(msg.Width, msg.Height)
+		}
 		return a, nil
 	case views.CreateMsg:
 		// Handle create completion
@@ -191,6 +197,24 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		a.state = StateDashboard
 		return a.refreshInstances()
+	case PickMsg:
+		if msg.Error != nil {
+			a.err = msg.Error
+			a.state = StateDashboard
+			return a, nil
+		}
+		a.state = StateDashboard
+		return a.refreshInstances()
+	case views.FanOutLoadedMsg:
+		if a.fanout != nil {
+			model, cmd := a.fanout.Update(msg)
+			a.fanout = model.(*views.FanOut)
+			return a, cmd
+		}
+	case views.PickWinnerRequestMsg:
+		a.pick = msg
+		a.state = StatePickConfirm
+		return a, nil
 	case SendPromptMsg:
 		if msg.Error != nil {
 			a.err = msg.Error
@@ -258,33 +282,24 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.diff != nil {
 			model, cmd := a.diff.Update(msg)
 			a.diff = model.(*views.Diff)
-			// Check if ESC was pressed to return to dashboard
-			if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
-				a.state = StateDashboard
-				return a, nil
-			}
 			return a, cmd
 		}
 	case StateMerge:
 		if a.merge != nil {
 			model, cmd := a.merge.Update(msg)
 			a.merge = model.(*views.Merge)
-			// Check if ESC was pressed to return to dashboard
-			if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
-				a.state = StateDashboard
-				return a, nil
-			}
 			return a, cmd
 		}
 	case StateLog:
 		if a.log != nil {
 			model, cmd := a.log.Update(msg)
 			a.log = model.(*views.Log)
-			// Check if ESC was pressed to return to dashboard
-			if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "esc" {
-				a.state = StateDashboard
-				return a, nil
-			}
+			return a, cmd
+		}
+	case StateFanOut:
+		if a.fanout != nil {
+			model, cmd := a.fanout.Update(msg)
+			a.fanout = model.(*views.FanOut)
 			return a, cmd
 		}
 	}
@@ -329,12 +344,23 @@ func (a *App) View() string {
 			return a.log.View()
 		}
 		return "Loading..."
+	case StateFanOut:
+		if a.fanout != nil {
+			return a.fanout.View()
+		}
+		return "Loading..."
 	case StateDeleteConfirm:
 		return a.renderDeleteConfirm()
+	case StatePickConfirm:
+		return a.renderPickConfirm()
 	case StateSendPrompt:
 		return a.renderSendPrompt()
 	case StateSubTerminalList:
 		return a.renderSubTerminalList()
+	case StateFilter:
+		return a.renderFilter()
+	case StateRepoSwitcher:
+		return a.renderRepoSwitcher()
 	default:
 		return "Unknown state"
 	}
@@ -342,208 +368,223 @@ func (a *App) View() string {
 
 // handleKeyMsg handles key messages
 func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	// The filter prompt takes every key, including q
+	if a.state == StateFilter {
+		return a.handleFilterKey(msg)
+	}
+	if a.state == StateRepoSwitcher {
+		return a.handleRepoSwitcherKey(msg)
+	}
+
+	switch {
+	case a.matchesGlobal(msg, a.keyMap.Quit):
 		return a, tea.Quit
-	case "?":
+	case a.matchesGlobal(msg, a.keyMap.Help):
 		if a.state == StateDashboard {
 			a.state = StateHelp
-		} else if a.state == StateCreate {
-			a.state = StateDashboard
 		} else {
 			a.state = StateDashboard
 		}
 		return a, nil
-	case "r":
-		if a.state == StateDashboard {
-			return a.refreshInstances()
-		}
-	case "n", "N":
-		if a.state == StateDeleteConfirm {
+	case a.state != StateDashboard && a.matchesGlobal(msg, a.keyMap.Back):
+		a.state = StateDashboard
+		return a, nil
+	}
+
+	// Delegate to current view
+	switch a.state {
+	case StateDashboard:
+		return a.handleDashboardKey(msg)
+	case StateDeleteConfirm:
+		switch {
+		case key.Matches(msg, a.keyMap.Confirm.Yes):
+			return a, a.deleteInstanceCmd(a.deleteInstanceID)
+		case key.Matches(msg, a.keyMap.Confirm.No):
 			a.state = StateDashboard
-			return a, nil
 		}
-		if a.state == StateDashboard {
-			a.state = StateCreate
-			defaultBase := "main"
-			if a.ctx.Config != nil && a.ctx.Config.Workspace.BaseBranch != "" {
-				defaultBase = a.ctx.Config.Workspace.BaseBranch
-			}
-			createStyles := views.CreateStyles{
-				Title:      a.styles.Header,
-				Error:      a.styles.ErrorText,
-				Help:       a.styles.Footer,
-				FormBorder: a.styles.FocusedBorder,
+		return a, nil
+	case StatePickConfirm:
+		switch {
+		case key.Matches(msg, a.keyMap.Confirm.Yes):
+			return a, a.pickWinnerCmd(a.pick.InstanceID)
+		case key.Matches(msg, a.keyMap.Confirm.No):
+			a.state = StateFanOut
+		}
+		return a, nil
+	case StateSendPrompt:
+		return a.handleSendPromptKey(msg)
+	case StateCreate:
+		if a.create != nil {
+			model, cmd := a.create.Update(msg)
+			a.create = model.(*views.Create)
+			return a, cmd
+		}
+	case StateDiff:
+		if a.diff != nil {
+			model, cmd := a.diff.Update(msg)
+			a.diff = model.(*views.Diff)
+			return a, cmd
+		}
+	case StateMerge:
+		if a.merge != nil {
+			model, cmd := a.merge.Update(msg)
+			a.merge = model.(*views.Merge)
+			return a, cmd
+		}
+	case StateLog:
+		if a.log != nil {
+			model, cmd := a.log.Update(msg)
+			a.log = model.(*views.Log)
+			return a, cmd
+		}
+	case StateFanOut:
+		if a.fanout != nil {
+			model, cmd := a.fanout.Update(msg)
+			a.fanout = model.(*views.FanOut)
+			return a, cmd
+		}
+	case StateHelp:
+		if a.help != nil {
+			model, cmd := a.help.Update(msg)
+			a.help = model.(*views.Help)
+			return a, cmd
+		}
+	}
+
+	return a, nil
+}
+
+// matchesGlobal reports whether msg triggers a global binding in the current
+// state. Views that take text input only honor keys that don't type a
+// character, so q can be typed into a branch name.
+func (a *App) matchesGlobal(msg tea.KeyMsg, binding key.Binding) bool {
+	if !key.Matches(msg, binding) {
+		return false
+	}
+	switch a.state {
+	case StateCreate, StateMerge, StateSendPrompt, StateFilter:
+		return !config.Typing(msg.String())
+	}
+	return true
+}
+
+// handleDashboardKey runs the dashboard's actions, most of them on the
+// selected instance, and passes other keys to the instance list
+func (a *App) handleDashboardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := a.keyMap.Dashboard
+
+	switch {
+	case key.Matches(msg, keys.Refresh):
+		return a.refreshInstances()
+	case key.Matches(msg, keys.SwitchRepo):
+		return a.openRepoSwitcher()
+	case key.Matches(msg, keys.Filter):
+		a.state = StateFilter
+		a.filterText = a.ctx.Selector.String()
+		a.filterError = ""
+		return a, nil
+	case key.Matches(msg, keys.New):
+		a.state = StateCreate
+		defaultBase := "main"
+		if a.ctx.Config != nil && a.ctx.Config.Workspace.BaseBranch != "" {
+			defaultBase = a.ctx.Config.Workspace.BaseBranch
+		}
+		a.create = views.NewCreate(a.ctx.Manager, defaultBase, a.styles.Create(), a.keyMap.Create)
+		a.create.SetSize(a.width, a.height)
+		return a, nil
+	case key.Matches(msg, keys.QuickFocus):
+		// The nth key of the binding focuses the nth instance
+		for idx, k := range keys.QuickFocus.Keys() {
+			if k == msg.String() && idx < len(a.instances) {
+				return a, a.focusInstance(idx)
 			}
-			a.create = views.NewCreate(a.ctx.Manager, defaultBase, createStyles)
-			a.create.SetSize(a.width, a.height)
-			return a, nil
 		}
-	case "d":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
+		return a, nil
+	}
+
+	if a.dashboard != nil {
+		selectedIdx := a.dashboard.GetSelectedIndex()
+		if selectedIdx >= 0 && selectedIdx < len(a.instances) {
+			selectedInstance := a.instances[selectedIdx]
+			switch {
+			case key.Matches(msg, keys.Focus):
+				return a, a.focusInstance(selectedIdx)
+			case key.Matches(msg, keys.Delete):
 				a.deleteInstanceID = selectedInstance.ID
 				a.deleteInstanceName = selectedInstance.Name
 				a.state = StateDeleteConfirm
 				return a, nil
-			}
-		}
-	case "f":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
+			case key.Matches(msg, keys.Diff):
 				gitMgr := git.NewGit(selectedInstance.WorktreePath)
-				a.diff = views.NewDiff(selectedInstance, gitMgr)
+				a.diff = views.NewDiff(selectedInstance, gitMgr, a.keyMap.Diff, a.styles.Diff())
 				a.diff.SetSize(a.width, a.height)
 				a.state = StateDiff
 				return a, a.diff.Init()
-			}
-		}
-	case "m":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
+			case key.Matches(msg, keys.Merge):
 				gitMgr := git.NewGit(selectedInstance.WorktreePath)
-				mergeStyles := views.MergeStyles{
-					Title:      a.styles.Header,
-					Error:      a.styles.ErrorText,
-					Success:    a.styles.StatusMergedStyle,
-					Warning:    a.styles.ConflictWarning,
-					Help:       a.styles.Footer,
-					FormBorder: a.styles.FocusedBorder,
-				}
-				a.merge = views.NewMerge(selectedInstance, a.ctx.Manager, gitMgr, a.ctx.Manager.Tmux(), a.instances, mergeStyles)
+				a.merge = views.NewMerge(selectedInstance, a.ctx.Manager, gitMgr, a.ctx.Manager.Tmux(), a.instances, a.styles.Merge(), a.keyMap.Merge)
 				a.merge.SetSize(a.width, a.height)
 				a.state = StateMerge
 				return a, a.merge.Init()
-			}
-		}
-	case "l":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
-				a.log = views.NewLog(selectedInstance, a.ctx.Manager.Tmux())
+			case key.Matches(msg, keys.Logs):
+				a.log = views.NewLog(selectedInstance, a.ctx.Manager.Tmux(), a.ctx.Manager.Store(), a.keyMap.Log, a.styles.Log())
 				a.log.SetSize(a.width, a.height)
 				a.state = StateLog
 				return a, a.log.Init()
-			}
-		}
-	case "s":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
+			case key.Matches(msg, keys.SendPrompt):
 				a.promptInstanceID = selectedInstance.ID
 				a.promptText = ""
 				a.promptFeedback = ""
 				a.state = StateSendPrompt
 				return a, nil
-			}
-		}
-	case "t", "T":
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				selectedInstance := a.instances[selectedIdx]
+			case key.Matches(msg, keys.SubTerminals):
 				a.subTerminalInstanceID = selectedInstance.ID
 				a.state = StateSubTerminalList
 				return a, nil
+			case key.Matches(msg, keys.FanOut) && selectedInstance.FanOut != "":
+				a.fanout = views.NewFanOut(selectedInstance.FanOut, a.ctx.Manager, a.keyMap.FanOut, a.styles.FanOut())
+				a.fanout.SetSize(a.width, a.height)
+				a.state = StateFanOut
+				return a, a.fanout.Init()
 			}
 		}
-	case "enter":
-		if a.state == StateSendPrompt {
-			if a.promptText != "" {
-				return a, a.sendPromptCmd(a.promptInstanceID, a.promptText)
-			}
-			a.state = StateDashboard
-			return a, nil
-		}
-		if a.state == StateDashboard && a.dashboard != nil {
-			selectedIdx := a.dashboard.GetSelectedIndex()
-			if selectedIdx >= 0 && selectedIdx < len(a.instances) {
-				return a, a.focusInstance(selectedIdx)
-			}
-		}
-	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-		if a.state == StateDashboard {
-			idx := int(msg.String()[0] - '0' - 1)
-			if idx >= 0 && idx < len(a.instances) {
-				return a, a.focusInstance(idx)
-			}
-		}
-	case "backspace":
-		if a.state == StateSendPrompt {
-			if len(a.promptText) > 0 {
-				a.promptText = a.promptText[:len(a.promptText)-1]
-			}
-			return a, nil
-		}
-	case "esc":
-		if a.state == StateCreate {
-			a.state = StateDashboard
-			return a, nil
-		}
-		if a.state == StateDeleteConfirm {
-			a.state = StateDashboard
-			return a, nil
-		}
-		if a.state == StateHelp {
-			a.state = StateDashboard
-			return a, nil
-		}
-		if a.state == StateSendPrompt {
-			a.state = StateDashboard
-			return a, nil
-		}
-		if a.state == StateSubTerminalList {
-			a.state = StateDashboard
-			return a, nil
-		}
-	case "y":
-		if a.state == StateDeleteConfirm {
-			return a, a.deleteInstanceCmd(a.deleteInstanceID)
-		}
-	}
 
-	// Handle text input for send prompt
-	if a.state == StateSendPrompt {
-		key := msg.String()
-		if len(key) == 1 && key >= " " && key <= "~" {
-			a.promptText += key
-			return a, nil
-		}
+		model, cmd := a.dashboard.Update(msg)
+		a.dashboard = model.(*views.Dashboard)
+		return a, cmd
 	}
 
-	// Delegate to current view
-	switch a.state {
-	case StateDashboard:
-		if a.dashboard != nil {
-			model, cmd := a.dashboard.Update(msg)
-			a.dashboard = model.(*views.Dashboard)
-			return a, cmd
-		}
-	case StateCreate:
-		if a.create != nil {
-			model, cmd := a.create.Update(msg)
-			a.create = model.(*views.Create)
-			return a, cmd
+	return a, nil
+}
+
+// handleSendPromptKey edits the prompt typed for the selected instance
+func (a *App) handleSendPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if a.promptText != "" {
+			return a, a.sendPromptCmd(a.promptInstanceID, a.promptText)
 		}
-	case StateHelp:
-		if a.help != nil {
-			model, cmd := a.help.Update(msg)
-			a.help = model.(*views.Help)
-			return a, cmd
+		a.state = StateDashboard
+		return a, nil
+	case "backspace":
+		if len(a.promptText) > 0 {
+			a.promptText = a.promptText[:len(a.promptText)-1]
 		}
+		return a, nil
 	}
 
+	typed := msg.String()
+	if len(typed) == 1 && typed >= " " && typed <= "~" {
+		a.promptText += typed
+	}
 	return a, nil
 }
 
+// dashboardHints are the bindings named in the dashboard's footer
+func (a *App) dashboardHints() []key.Binding {
+	return []key.Binding{a.keyMap.Dashboard.Filter, a.keyMap.Help, a.keyMap.Quit}
+}
+
 func (a *App) refreshInstances() (tea.Model, tea.Cmd) {
 	if a.ctx.Manager != nil {
 		stateData, err := a.ctx.Manager.Store().Load()
@@ -552,17 +593,10 @@ func (a *App) refreshInstances() (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 		if stateData != nil {
-			a.instances = stateData.Instances
-			statusStyles := views.StatusStyles{
-				Active:   a.styles.StatusActiveStyle,
-				Idle:     a.styles.StatusIdleStyle,
-				Paused:   a.styles.StatusPausedStyle,
-				Error:    a.styles.StatusErrorStyle,
-				Merged:   a.styles.StatusMergedStyle,
-				Done:     a.styles.StatusDoneStyle,
-				Conflict: a.styles.ConflictWarning,
-			}
-			a.dashboard = views.NewDashboard(a.instances, statusStyles, a.ctx.Manager)
+			a.instances = a.ctx.Selector.Filter(stateData.Instances)
+			a.dashboard = views.NewDashboard(a.instances, a.styles.Dashboard(), a.ctx.Manager, a.keyMap.Dashboard.ListKeyMap)
+			a.dashboard.SetSelector(a.ctx.Selector.String())
+			a.dashboard.SetHints(a.dashboardHints()...)
 			a.dashboard.SetSize(a.width, a.height)
 		}
 	}
@@ -610,7 +644,9 @@ func (a *App) deleteInstanceCmd(instanceID string) tea.Cmd {
 			return DeleteMsg{Success: false, Error: fmt.Errorf("manager not available")}
 		}
 
-		err := a.ctx.Manager.DeleteInstance(instanceID, false, false)
+		err := a.ctx.Manager.DeleteInstance(instanceID, workspace.DeleteOpts{
+			StashChanges: a.ctx.Manager.Config().Workspace.StashOnDelete,
+		})
 		if err != nil {
 			return DeleteMsg{Success: false, Error: fmt.Errorf("failed to delete instance: %w", err)}
 		}
@@ -622,32 +658,46 @@ func (a *App) deleteInstanceCmd(instanceID string) tea.Cmd {
 func (a *App) renderDeleteConfirm() string {
 	title := a.styles.Header.Render("Delete Instance")
 	instanceName := a.styles.FocusedBorder.Render(a.deleteInstanceName)
-	warning := a.styles.ErrorText.Render("⚠ This will remove the worktree and kill all processes")
-	prompt := "Delete instance? [y/N] (ESC to cancel)"
+	warning := a.styles.ErrorText.Render(a.styles.Symbols.Conflict + " This will remove the worktree and kill all processes")
+	hint := a.styles.InfoText.Render("The instance is archived and can be brought back with: ocw restore " + a.deleteInstanceID)
+	prompt := fmt.Sprintf("Delete instance? [%s] delete [%s] keep (%s to cancel)",
+		a.keyMap.Confirm.Yes.Help().Key, a.keyMap.Confirm.No.Help().Key, a.keyMap.Back.Help().Key)
 
-	return fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", title, instanceName, warning, prompt)
+	return fmt.Sprintf("%s\n\n%s\n\n%s\n%s\n\n%s", title, instanceName, warning, hint, prompt)
 }
 
-func (a *App) sendPromptCmd(instanceID, promptText string) tea.Cmd {
+func (a *App) pickWinnerCmd(instanceID string) tea.Cmd {
 	return func() tea.Msg {
 		if a.ctx.Manager == nil {
-			return SendPromptMsg{Success: false, Error: fmt.Errorf("manager not available")}
+			return PickMsg{Error: fmt.Errorf("manager not available")}
 		}
 
-		var instance *state.Instance
-		for i := range a.instances {
-			if a.instances[i].ID == instanceID {
-				instance = &a.instances[i]
-				break
-			}
+		if _, err := a.ctx.Manager.PickWinner(instanceID); err != nil {
+			return PickMsg{Error: fmt.Errorf("failed to pick winner: %w", err)}
 		}
+		return PickMsg{}
+	}
+}
 
-		if instance == nil {
-			return SendPromptMsg{Success: false, Error: fmt.Errorf("instance not found")}
+func (a *App) renderPickConfirm() string {
+	title := a.styles.Header.Render("Pick Winner")
+	instanceName := a.styles.FocusedBorder.Render(a.pick.Name)
+	warning := a.styles.ErrorText.Render(fmt.Sprintf("%s The other %d sibling(s) will be deleted with their branches", a.styles.Symbols.Conflict, a.pick.Others))
+	hint := a.styles.InfoText.Render("They are archived and can be brought back with: ocw restore <id>")
+	prompt := fmt.Sprintf("Keep this instance? [%s] pick [%s] back to the fan-out (%s to cancel)",
+		a.keyMap.Confirm.Yes.Help().Key, a.keyMap.Confirm.No.Help().Key, a.keyMap.Back.Help().Key)
+
+	return fmt.Sprintf("%s\n\n%s\n\n%s\n%s\n\n%s", title, instanceName, warning, hint, prompt)
+}
+
+func (a *App) sendPromptCmd(instanceID, promptText string) tea.Cmd {
+	return func() tea.Msg {
+		if a.ctx.Manager == nil {
+			return SendPromptMsg{Success: false, Error: fmt.Errorf("manager not available")}
 		}
 
-		if err := a.ctx.Manager.Tmux().SendKeys(instance.PrimaryPane, promptText); err != nil {
-			return SendPromptMsg{Success: false, Error: fmt.Errorf("failed to send prompt: %w", err)}
+		if err := a.ctx.Manager.SendPrompt(instanceID, promptText); err != nil {
+			return SendPromptMsg{Success: false, Error: err}
 		}
 
 		return SendPromptMsg{Success: true, Error: nil}
@@ -699,7 +749,7 @@ func (a *App) resolveConflictsCmd(instanceID string) tea.Cmd {
 			conflictFiles := a.merge.GetConflictFiles()
 			if len(conflictFiles) > 0 {
 				prompt := fmt.Sprintf("Please resolve the merge conflicts in the following files: %v", conflictFiles)
-				if sendErr := a.ctx.Manager.Tmux().SendKeys(instance.PrimaryPane, prompt); sendErr != nil {
+				if sendErr := a.ctx.Manager.SendPrompt(instance.ID, prompt); sendErr != nil {
 					return FocusCompleteMsg{Error: fmt.Errorf("focused successfully but failed to send prompt: %w", sendErr)}
 				}
 			}
@@ -709,10 +759,185 @@ func (a *App) resolveConflictsCmd(instanceID string) tea.Cmd {
 	}
 }
 
+// handleFilterKey edits the selector typed into the filter prompt
+func (a *App) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case a.matchesGlobal(msg, a.keyMap.Quit):
+		return a, tea.Quit
+	case a.matchesGlobal(msg, a.keyMap.Back):
+		a.state = StateDashboard
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		selector, err := state.ParseSelector(a.filterText)
+		if err != nil {
+			a.filterError = err.Error()
+			return a, nil
+		}
+		a.ctx.Selector = selector
+		a.state = StateDashboard
+		return a.refreshInstances()
+	case "backspace":
+		if len(a.filterText) > 0 {
+			a.filterText = a.filterText[:len(a.filterText)-1]
+		}
+		return a, nil
+	case "ctrl+u":
+		a.filterText = ""
+		return a, nil
+	}
+
+	key := msg.String()
+	if len(key) == 1 && key >= " " && key <= "~" {
+		a.filterText += key
+		a.filterError = ""
+	}
+	return a, nil
+}
+
+func (a *App) renderFilter() string {
+	title := a.styles.Header.Render("Filter Instances")
+	textBox := a.styles.FocusedBorder.Render(a.filterText + a.styles.Symbols.Cursor)
+	help := a.styles.Footer.Render(fmt.Sprintf("tag, !tag, key=value, key!=value (comma-separated) | Enter: Apply (empty clears) | ctrl+u: Clear | %s: Cancel", a.keyMap.Back.Help().Key))
+	feedback := ""
+	if a.filterError != "" {
+		feedback = "\n\n" + a.styles.ErrorText.Render(a.filterError)
+	}
+	return fmt.Sprintf("%s\n\n%s\n\n%s%s", title, textBox, help, feedback)
+}
+
+// openRepoSwitcher loads the registered repositories and shows the switcher
+func (a *App) openRepoSwitcher() (tea.Model, tea.Cmd) {
+	reg, err := registry.Default()
+	if err != nil {
+		a.err = err
+		return a, nil
+	}
+
+	repos, err := workspace.ListRepos(reg)
+	if err != nil {
+		a.err = err
+		return a, nil
+	}
+
+	a.repos = repos
+	a.repoCursor = 0
+	if a.ctx.Manager != nil {
+		for i, repo := range repos {
+			if repo.Repo.Path == a.ctx.Manager.RepoRoot() {
+				a.repoCursor = i
+				break
+			}
+		}
+	}
+
+	a.state = StateRepoSwitcher
+	return a, nil
+}
+
+// handleRepoSwitcherKey moves through the repo list and switches on enter
+func (a *App) handleRepoSwitcherKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := a.keyMap.Dashboard
+	switch {
+	case key.Matches(msg, a.keyMap.Quit):
+		return a, tea.Quit
+	case key.Matches(msg, a.keyMap.Back), key.Matches(msg, keys.SwitchRepo):
+		a.state = StateDashboard
+		return a, nil
+	case key.Matches(msg, keys.Up):
+		if a.repoCursor > 0 {
+			a.repoCursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if a.repoCursor < len(a.repos)-1 {
+			a.repoCursor++
+		}
+	case key.Matches(msg, keys.Focus):
+		if a.repoCursor >= 0 && a.repoCursor < len(a.repos) {
+			a.state = StateDashboard
+			return a, a.switchRepoCmd(a.repos[a.repoCursor].Repo)
+		}
+	}
+	return a, nil
+}
+
+// switchRepoCmd jumps to the tmux session of another repository, creating it if needed.
+// Inside tmux the client is switched; otherwise the session is attached until detached.
+func (a *App) switchRepoCmd(repo registry.Entry) tea.Cmd {
+	return func() tea.Msg {
+		tmuxClient := tmux.NewTmux()
+		if !tmuxClient.HasSession(repo.Session) {
+			if err := tmuxClient.NewSession(repo.Session, repo.Path); err != nil {
+				return FocusCompleteMsg{Error: err}
+			}
+		}
+
+		if tmux.InsideTmux() {
+			return FocusCompleteMsg{Error: tmuxClient.SwitchClient(repo.Session)}
+		}
+
+		if a.program == nil {
+			return FocusCompleteMsg{Error: fmt.Errorf("program not available")}
+		}
+
+		if err := a.program.ReleaseTerminal(); err != nil {
+			return FocusCompleteMsg{Error: fmt.Errorf("failed to release terminal: %w", err)}
+		}
+
+		err := tmuxClient.AttachSession(repo.Session)
+
+		if restoreErr := a.program.RestoreTerminal(); restoreErr != nil {
+			return FocusCompleteMsg{Error: fmt.Errorf("failed to restore terminal: %w", restoreErr)}
+		}
+
+		return FocusCompleteMsg{Error: err}
+	}
+}
+
+func (a *App) renderRepoSwitcher() string {
+	title := a.styles.Header.Render("Switch Repository")
+
+	var content string
+	if len(a.repos) == 0 {
+		content = a.styles.Footer.Render("No repositories registered yet. Run 'ocw init' in a repository to register it.")
+	} else {
+		for i, repo := range a.repos {
+			session := "no session"
+			if repo.SessionRunning {
+				session = "session running"
+			}
+
+			instances := fmt.Sprintf("%d instance(s)", len(repo.Instances))
+			if repo.Err != nil {
+				instances = "state unreadable"
+			}
+
+			line := fmt.Sprintf("%s  %s | %s | %s", repo.Repo.Name, repo.Repo.Path, instances, session)
+			if a.ctx.Manager != nil && repo.Repo.Path == a.ctx.Manager.RepoRoot() {
+				line += " (current)"
+			}
+
+			if i == a.repoCursor {
+				content += a.styles.SelectedItem.Render(a.styles.Symbols.Pointer+" "+line) + "\n"
+			} else {
+				content += "  " + line + "\n"
+			}
+		}
+	}
+
+	keys := a.keyMap.Dashboard
+	help := a.styles.Footer.Render(fmt.Sprintf("%s/%s: Select | %s: Jump to session | %s: Return to dashboard",
+		keys.Up.Help().Key, keys.Down.Help().Key, keys.Focus.Help().Key, a.keyMap.Back.Help().Key))
+
+	return fmt.Sprintf("%s\n\n%s\n%s", title, content, help)
+}
+
 func (a *App) renderSendPrompt() string {
 	title := a.styles.Header.Render("Send Prompt to Instance")
-	textBox := a.styles.FocusedBorder.Render(a.promptText + "█")
-	help := a.styles.Footer.Render("Type your prompt | Enter: Send | ESC: Cancel")
+	textBox := a.styles.FocusedBorder.Render(a.promptText + a.styles.Symbols.Cursor)
+	help := a.styles.Footer.Render(fmt.Sprintf("Type your prompt | Enter: Send | %s: Cancel", a.keyMap.Back.Help().Key))
 	feedback := ""
 	if a.promptFeedback != "" {
 		feedback = "\n\n" + a.styles.StatusActiveStyle.Render(a.promptFeedback)
@@ -753,7 +978,7 @@ func (a *App) renderSubTerminalList() string {
 		}
 	}
 
-	help := a.styles.Footer.Render("ESC: Return to dashboard")
+	help := a.styles.Footer.Render(fmt.Sprintf("%s: Return to dashboard", a.keyMap.Back.Help().Key))
 
 	return fmt.Sprintf("%s\n\n%s\n\n%s", title, content, help)
 }
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/workspace"
 )
 
@@ -9,8 +10,10 @@ import (
 type Context struct {
 	Config  *config.Config
 	Manager *workspace.Manager
-	Width   int
-	Height  int
+	// Selector limits the dashboard to instances with matching tags and metadata
+	Selector state.Selector
+	Width    int
+	Height   int
 }
 
 // NewContext creates a new TUI context
@@ -1,68 +1,222 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/huh"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/tui/views"
+)
 
 // KeyMap defines all key bindings for the TUI
 type KeyMap struct {
-	Up             key.Binding
-	Down           key.Binding
-	Left           key.Binding
-	Right          key.Binding
-	Enter          key.Binding
-	Escape         key.Binding
-	Quit           key.Binding
-	Help           key.Binding
-	Refresh        key.Binding
-	NewInstance    key.Binding
-	DeleteInstance key.Binding
+	Quit      key.Binding
+	Help      key.Binding
+	Back      key.Binding
+	Dashboard DashboardKeyMap
+	Diff      views.PagerKeyMap
+	Log       views.LogKeyMap
+	Merge     views.MergeKeyMap
+	Create    views.FormKeyMap
+	FanOut    views.FanOutKeyMap
+	HelpView  views.PagerKeyMap
+	Confirm   ConfirmKeyMap
+}
+
+// DashboardKeyMap holds the instance list's navigation and the actions on the
+// selected instance
+type DashboardKeyMap struct {
+	views.ListKeyMap
+	Focus        key.Binding
+	QuickFocus   key.Binding
+	New          key.Binding
+	Delete       key.Binding
+	Diff         key.Binding
+	Merge        key.Binding
+	Logs         key.Binding
+	SendPrompt   key.Binding
+	SubTerminals key.Binding
+	Refresh      key.Binding
+	Filter       key.Binding
+	SwitchRepo   key.Binding
+	FanOut       key.Binding
+}
+
+// ConfirmKeyMap answers the delete and pick confirmations
+type ConfirmKeyMap struct {
+	Yes key.Binding
+	No  key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
 func DefaultKeyMap() KeyMap {
+	return NewKeyMap(config.DefaultConfig().Keys, false)
+}
+
+// NewKeyMap builds the key bindings from the [keys] config section
+func NewKeyMap(keys config.KeysConfig, ascii bool) KeyMap {
+	symbols := keySymbols
+	if ascii {
+		symbols = nil
+	}
+	bind := func(keys []string, desc string) key.Binding {
+		return binding(keys, desc, symbols)
+	}
+
+	back := bind(keys.Global.Back, "back")
+	pager := func(p config.PagerKeys, back key.Binding) views.PagerKeyMap {
+		return views.PagerKeyMap{
+			Up:       bind(p.Up, "up"),
+			Down:     bind(p.Down, "down"),
+			PageUp:   bind(p.PageUp, "page up"),
+			PageDown: bind(p.PageDown, "page down"),
+			Top:      bind(p.Top, "top"),
+			Bottom:   bind(p.Bottom, "bottom"),
+			Back:     back,
+		}
+	}
+	d := keys.Dashboard
+	l := keys.Log
+	quickFocus := bind(d.QuickFocus, "focus the nth instance")
+	quickFocus.SetHelp(rangeLabel(d.QuickFocus, symbols), quickFocus.Help().Desc)
+
 	return KeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "move up"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "move down"),
-		),
-		Left: key.NewBinding(
-			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "move left"),
-		),
-		Right: key.NewBinding(
-			key.WithKeys("right", "l"),
-			key.WithHelp("→/l", "move right"),
-		),
-		Enter: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
-		),
-		Escape: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "back"),
-		),
-		Quit: key.NewBinding(
-			key.WithKeys("ctrl+c", "q"),
-			key.WithHelp("ctrl+c/q", "quit"),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "help"),
-		),
-		Refresh: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
-		NewInstance: key.NewBinding(
-			key.WithKeys("n"),
-			key.WithHelp("n", "new instance"),
-		),
-		DeleteInstance: key.NewBinding(
-			key.WithKeys("d"),
-			key.WithHelp("d", "delete instance"),
-		),
+		Quit: bind(keys.Global.Quit, "quit"),
+		Help: bind(keys.Global.Help, "toggle help"),
+		Back: back,
+		Dashboard: DashboardKeyMap{
+			ListKeyMap: views.ListKeyMap{
+				Up:       bind(d.Up, "move up"),
+				Down:     bind(d.Down, "move down"),
+				PageUp:   bind(d.PageUp, "previous page"),
+				PageDown: bind(d.PageDown, "next page"),
+				Top:      bind(d.Top, "first instance"),
+				Bottom:   bind(d.Bottom, "last instance"),
+			},
+			Focus:        bind(d.Focus, "focus selected instance"),
+			QuickFocus:   quickFocus,
+			New:          bind(d.New, "create new instance"),
+			Delete:       bind(d.Delete, "delete selected instance"),
+			Diff:         bind(d.Diff, "show diff for selected instance"),
+			Merge:        bind(d.Merge, "merge selected instance"),
+			Logs:         bind(d.Logs, "show logs for selected instance"),
+			SendPrompt:   bind(d.SendPrompt, "send a prompt to selected instance"),
+			SubTerminals: bind(d.SubTerminals, "show sub-terminals for selected instance"),
+			Refresh:      bind(d.Refresh, "refresh instances"),
+			Filter:       bind(d.Filter, "filter by tags and metadata"),
+			SwitchRepo:   bind(d.SwitchRepo, "switch to another repository's session"),
+			FanOut:       bind(d.FanOut, "compare the selected instance's fan-out"),
+		},
+		Diff: pager(keys.Diff, back),
+		Log: views.LogKeyMap{
+			PagerKeyMap: pager(config.PagerKeys{
+				Up: l.Up, Down: l.Down, PageUp: l.PageUp, PageDown: l.PageDown, Top: l.Top, Bottom: l.Bottom,
+			}, back),
+			ToggleHooks: bind(l.ToggleHooks, "hooks/pane output"),
+		},
+		Merge: views.MergeKeyMap{
+			FormKeyMap:       formKeyMap(bind, keys.Merge.Next, keys.Merge.Prev, keys.Merge.NewLine, back),
+			ResolveConflicts: bind(keys.Merge.ResolveConflicts, "resolve conflicts"),
+		},
+		FanOut: views.FanOutKeyMap{
+			Up:   bind(keys.FanOut.Up, "up"),
+			Down: bind(keys.FanOut.Down, "down"),
+			Test: bind(keys.FanOut.Test, "run tests"),
+			Pick: bind(keys.FanOut.Pick, "pick winner"),
+			Back: back,
+		},
+		Create:   formKeyMap(bind, keys.Create.Next, keys.Create.Prev, keys.Create.NewLine, back),
+		HelpView: pager(keys.Help, bind(append(append([]string{}, keys.Global.Help...), keys.Global.Back...), "close help")),
+		Confirm: ConfirmKeyMap{
+			Yes: bind(keys.Confirm.Yes, "confirm"),
+			No:  bind(keys.Confirm.No, "cancel"),
+		},
 	}
 }
+
+// HelpSections lists the effective bindings of every view for the help view
+func (k KeyMap) HelpSections() []views.HelpSection {
+	d := k.Dashboard
+	return []views.HelpSection{
+		{Title: "GLOBAL", Bindings: []key.Binding{k.Help, k.Back, k.Quit}},
+		{Title: "DASHBOARD", Bindings: []key.Binding{
+			d.Up, d.Down, d.PageUp, d.PageDown, d.Top, d.Bottom,
+			d.Focus, d.QuickFocus, d.New, d.Delete, d.Diff, d.Merge, d.Logs,
+			d.SendPrompt, d.SubTerminals, d.Refresh, d.Filter, d.SwitchRepo, d.FanOut,
+		}},
+		{Title: "DIFF VIEW", Bindings: pagerBindings(k.Diff)},
+		{Title: "LOG VIEW", Bindings: append(pagerBindings(k.Log.PagerKeyMap), k.Log.ToggleHooks)},
+		{Title: "FAN-OUT VIEW", Bindings: []key.Binding{k.FanOut.Up, k.FanOut.Down, k.FanOut.Test, k.FanOut.Pick}},
+		{Title: "CREATE FORM", Bindings: formBindings(k.Create.Form)},
+		{Title: "MERGE FORM", Bindings: append(formBindings(k.Merge.Form), k.Merge.ResolveConflicts)},
+		{Title: "HELP VIEW", Bindings: append(pagerBindings(k.HelpView), k.HelpView.Back)},
+		{Title: "CONFIRMATION", Bindings: []key.Binding{k.Confirm.Yes, k.Confirm.No}},
+	}
+}
+
+func pagerBindings(p views.PagerKeyMap) []key.Binding {
+	return []key.Binding{p.Up, p.Down, p.PageUp, p.PageDown, p.Top, p.Bottom}
+}
+
+func formBindings(form *huh.KeyMap) []key.Binding {
+	return []key.Binding{form.Input.Next, form.Input.Prev, form.Text.NewLine}
+}
+
+// formKeyMap applies the configured field navigation to huh's default keys
+func formKeyMap(bind func([]string, string) key.Binding, next, prev, newLine []string, back key.Binding) views.FormKeyMap {
+	form := huh.NewDefaultKeyMap()
+	nextBinding := bind(next, "next field")
+	prevBinding := bind(prev, "previous field")
+	submit := bind(next, "submit")
+
+	form.Input.Next, form.Input.Prev, form.Input.Submit = nextBinding, prevBinding, submit
+	form.Select.Next, form.Select.Prev, form.Select.Submit = nextBinding, prevBinding, submit
+	form.Text.Next, form.Text.Prev, form.Text.Submit = nextBinding, prevBinding, submit
+	form.Text.NewLine = bind(newLine, "new line")
+
+	return views.FormKeyMap{Form: form, Back: back}
+}
+
+// binding creates a binding for keys, labeled with symbols for named keys. An
+// empty list yields a disabled binding.
+func binding(keys []string, desc string, symbols map[string]string) key.Binding {
+	b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys, symbols), desc))
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// keyLabel renders keys for display, e.g. "↑/k" for up and k
+func keyLabel(keys []string, symbols map[string]string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = symbols[k]
+		if labels[i] == "" {
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// rangeLabel shortens a run of consecutive keys such as 1 to 9 to "1-9"
+func rangeLabel(keys []string, symbols map[string]string) string {
+	if len(keys) < 3 {
+		return keyLabel(keys, symbols)
+	}
+	for i, k := range keys {
+		if len(k) != 1 || k[0] != keys[0][0]+byte(i) {
+			return keyLabel(keys, symbols)
+		}
+	}
+	return keys[0] + "-" + keys[len(keys)-1]
+}
+
+// keySymbols label named keys with arrows unless plain ASCII is wanted
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
@@ -1,14 +1,16 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/tui/views"
+)
 
 // Styles holds all lipgloss styles for the TUI
 type Styles struct {
-	// Status icons
-	StatusRunning string
-	StatusStopped string
-	StatusError   string
-	StatusPending string
+	// Icons and markers, Unicode or plain ASCII
+	Symbols views.Symbols
 
 	// Status colors
 	StatusActiveStyle lipgloss.Style
@@ -16,9 +18,12 @@ type Styles struct {
 	StatusPausedStyle lipgloss.Style
 	StatusErrorStyle  lipgloss.Style
 	StatusMergedStyle lipgloss.Style
-	StatusDoneStyle   lipgloss.Style
+	StatusPROpenStyle lipgloss.Style
 	ConflictWarning   lipgloss.Style
 
+	// Changed files by git status
+	Files views.FileStyles
+
 	// Colors
 	FocusedBorder  lipgloss.Style
 	BlurredBorder  lipgloss.Style
@@ -26,95 +31,192 @@ type Styles struct {
 	UnselectedItem lipgloss.Style
 	Header         lipgloss.Style
 	Footer         lipgloss.Style
+	Summary        lipgloss.Style
+	Preview        lipgloss.Style
 	ErrorText      lipgloss.Style
 	SuccessText    lipgloss.Style
 	WarningText    lipgloss.Style
 	InfoText       lipgloss.Style
+
+	// Form is the theme of the create and merge forms
+	Form *huh.Theme
 }
 
 // DefaultStyles returns the default style configuration
 func DefaultStyles() Styles {
-	return Styles{
-		// Status icons
-		StatusRunning: "●",
-		StatusStopped: "○",
-		StatusError:   "✗",
-		StatusPending: "◐",
-
-		// Status colors (green/yellow/gray/red/blue/cyan)
-		StatusActiveStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("46")). // green
-			Bold(true),
-
-		StatusIdleStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")). // yellow
-			Bold(true),
-
-		StatusPausedStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")). // gray
-			Bold(true),
-
-		StatusErrorStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")). // red
-			Bold(true),
-
-		StatusMergedStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("33")). // blue
-			Bold(true),
-
-		StatusDoneStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("51")). // cyan
-			Bold(true),
-
-		ConflictWarning: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")). // yellow
-			Bold(true),
-
-		// Focused border
-		FocusedBorder: lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")),
+	return NewStyles(config.DefaultConfig().UI, false)
+}
 
-		// Blurred border
-		BlurredBorder: lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")),
+// NewStyles builds the styles from the [ui] config section. With noColor
+// set, as for NO_COLOR, the selected entry is shown in reverse video since
+// its colors are not drawn.
+func NewStyles(ui config.UIConfig, noColor bool) Styles {
+	p := ui.Theme.Palette()
+	color := func(c string) lipgloss.Color { return lipgloss.Color(c) }
 
-		// Selected item
-		SelectedItem: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
-			Bold(true),
+	symbols := views.UnicodeSymbols()
+	if ui.ASCII {
+		symbols = views.ASCIISymbols()
+	}
 
-		// Unselected item
-		UnselectedItem: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("250")),
+	selected := lipgloss.NewStyle().
+		Foreground(color(p.SelectionText)).
+		Background(color(p.Selection)).
+		Bold(true)
+	if noColor {
+		selected = lipgloss.NewStyle().Reverse(true).Bold(true)
+	}
 
-		// Header
+	form := huh.ThemeCatppuccin()
+	if ui.Theme.Name == "high-contrast" {
+		form = huh.ThemeBase16()
+	}
+	if ui.ASCII {
+		bar := lipgloss.Border{Left: "|"}
+		form.Focused.Base = form.Focused.Base.BorderStyle(bar)
+		form.Blurred.Base = form.Blurred.Base.BorderStyle(bar)
+	}
+
+	return Styles{
+		Symbols: symbols,
+
+		StatusActiveStyle: lipgloss.NewStyle().Foreground(color(p.Success)).Bold(true),
+		StatusIdleStyle:   lipgloss.NewStyle().Foreground(color(p.Warning)).Bold(true),
+		StatusPausedStyle: lipgloss.NewStyle().Foreground(color(p.Muted)).Bold(true),
+		StatusErrorStyle:  lipgloss.NewStyle().Foreground(color(p.Error)).Bold(true),
+		StatusMergedStyle: lipgloss.NewStyle().Foreground(color(p.Info)).Bold(true),
+		StatusPROpenStyle: lipgloss.NewStyle().Foreground(color(p.PROpen)).Bold(true),
+		ConflictWarning:   lipgloss.NewStyle().Foreground(color(p.Warning)).Bold(true),
+
+		Files: views.FileStyles{
+			Added:    lipgloss.NewStyle().Foreground(color(p.Success)),
+			Modified: lipgloss.NewStyle().Foreground(color(p.Warning)),
+			Deleted:  lipgloss.NewStyle().Foreground(color(p.Error)),
+			Renamed:  lipgloss.NewStyle().Foreground(color(p.Info)),
+		},
+
+		FocusedBorder: lipgloss.NewStyle().
+			BorderStyle(symbols.Border).
+			BorderForeground(color(p.Accent)),
+		BlurredBorder: lipgloss.NewStyle().
+			BorderStyle(symbols.Border).
+			BorderForeground(color(p.Muted)),
+		SelectedItem:   selected,
+		UnselectedItem: lipgloss.NewStyle().Foreground(color(p.Text)),
 		Header: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("62")).
+			Foreground(color(p.Accent)).
 			Bold(true).
 			Padding(0, 1),
-
-		// Footer
 		Footer: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
+			Foreground(color(p.Muted)).
+			Padding(0, 1),
+		Summary: lipgloss.NewStyle().
+			Foreground(color(p.Muted)).
 			Padding(0, 1),
+		Preview: lipgloss.NewStyle().
+			Foreground(color(p.Muted)).
+			Padding(0, 1).
+			Border(symbols.Border).
+			BorderForeground(color(p.Muted)),
+		ErrorText:   lipgloss.NewStyle().Foreground(color(p.Error)),
+		SuccessText: lipgloss.NewStyle().Foreground(color(p.Success)),
+		WarningText: lipgloss.NewStyle().Foreground(color(p.Warning)),
+		InfoText:    lipgloss.NewStyle().Foreground(color(p.Info)),
+
+		Form: form,
+	}
+}
 
-		// Error text
-		ErrorText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")),
+// Dashboard returns the styles of the dashboard view
+func (s Styles) Dashboard() views.DashboardStyles {
+	return views.DashboardStyles{
+		Status: views.StatusStyles{
+			Active:   s.StatusActiveStyle,
+			Idle:     s.StatusIdleStyle,
+			Paused:   s.StatusPausedStyle,
+			Error:    s.StatusErrorStyle,
+			Merged:   s.StatusMergedStyle,
+			PROpen:   s.StatusPROpenStyle,
+			Conflict: s.ConflictWarning,
+		},
+		Title:   s.SelectedItem.Padding(0, 1),
+		Header:  s.Header,
+		Footer:  s.Footer,
+		Preview: s.Preview,
+		Symbols: s.Symbols,
+	}
+}
 
-		// Success text
-		SuccessText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("46")),
+// Create returns the styles of the create view
+func (s Styles) Create() views.CreateStyles {
+	return views.CreateStyles{
+		Title:      s.Header,
+		Error:      s.ErrorText,
+		Help:       s.Footer,
+		FormBorder: s.FocusedBorder,
+		Form:       s.Form,
+		Symbols:    s.Symbols,
+	}
+}
+
+// Merge returns the styles of the merge view
+func (s Styles) Merge() views.MergeStyles {
+	return views.MergeStyles{
+		Title:      s.Header,
+		Error:      s.ErrorText,
+		Success:    s.StatusMergedStyle,
+		Warning:    s.ConflictWarning,
+		Help:       s.Footer,
+		FormBorder: s.FocusedBorder,
+		Summary:    s.Summary,
+		Files:      s.Files,
+		Form:       s.Form,
+		Symbols:    s.Symbols,
+	}
+}
 
-		// Warning text
-		WarningText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")),
+// Diff returns the styles of the diff view
+func (s Styles) Diff() views.DiffStyles {
+	return views.DiffStyles{
+		Header:  s.Header,
+		Summary: s.Summary,
+		Footer:  s.Footer,
+		Files:   s.Files,
+		Symbols: s.Symbols,
+	}
+}
+
+// Log returns the styles of the log view
+func (s Styles) Log() views.LogStyles {
+	return views.LogStyles{
+		Header:  s.Header,
+		Footer:  s.Footer,
+		Symbols: s.Symbols,
+	}
+}
+
+// FanOut returns the styles of the fan-out view
+func (s Styles) FanOut() views.FanOutStyles {
+	return views.FanOutStyles{
+		Header:   s.Header,
+		Summary:  s.Summary,
+		Footer:   s.Footer,
+		Selected: s.SelectedItem,
+		Fail:     s.ErrorText,
+		Warning:  s.ConflictWarning,
+		Symbols:  s.Symbols,
+	}
+}
 
-		// Info text
-		InfoText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("33")),
+// Help returns the styles of the help view
+func (s Styles) Help() views.HelpStyles {
+	return views.HelpStyles{
+		Title:     s.Header,
+		Header:    s.SelectedItem,
+		Border:    s.FocusedBorder,
+		Text:      lipgloss.NewStyle(),
+		Highlight: s.SelectedItem,
+		Footer:    s.Footer,
+		Symbols:   s.Symbols,
 	}
 }
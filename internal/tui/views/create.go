@@ -3,11 +3,14 @@ package views
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tommyzliu/ocw/internal/config"
 	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/workspace"
 )
@@ -21,8 +24,18 @@ type CreateMsg struct {
 // Create is the view for creating new instances
 type Create struct {
 	form          *huh.Form
+	templates     map[string]config.Template
+	template      string
+	vars          map[string]map[string]*string // template -> variable -> value
+	agents        []string
+	defaultAgent  string
+	agent         string
 	branchName    string
 	baseBranch    string
+	tags          string
+	metadata      string
+	task          string
+	prompt        string
 	manager       *workspace.Manager
 	defaultBase   string
 	width         int
@@ -30,6 +43,7 @@ type Create struct {
 	creating      bool
 	creationError string
 	styles        CreateStyles
+	keys          FormKeyMap
 }
 
 // CreateStyles holds styling for the create view
@@ -38,16 +52,25 @@ type CreateStyles struct {
 	Error      lipgloss.Style
 	Help       lipgloss.Style
 	FormBorder lipgloss.Style
+	Form       *huh.Theme
+	Symbols    Symbols
 }
 
 // NewCreate creates a new Create view
-func NewCreate(manager *workspace.Manager, defaultBase string, styles CreateStyles) *Create {
+func NewCreate(manager *workspace.Manager, defaultBase string, styles CreateStyles, keys FormKeyMap) *Create {
 	c := &Create{
 		manager:     manager,
 		defaultBase: defaultBase,
 		width:       80,
 		height:      24,
 		styles:      styles,
+		keys:        keys,
+		vars:        map[string]map[string]*string{},
+	}
+	if manager != nil && manager.Config() != nil {
+		c.templates = manager.Config().Workspace.Templates
+		c.agents = manager.Config().AgentNames()
+		c.defaultAgent = manager.Config().Workspace.Agent
 	}
 
 	c.buildForm()
@@ -59,30 +82,174 @@ func (c *Create) Init() tea.Cmd {
 	return nil
 }
 
-// buildForm constructs the huh form
+// buildForm constructs the huh form. With templates configured it starts
+// with a template picker followed by the chosen template's variables.
 func (c *Create) buildForm() {
-	c.form = huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("Branch Name").
-				Placeholder("feature/my-feature").
-				Value(&c.branchName).
-				Validate(c.validateBranchName),
-			huh.NewInput().
-				Title("Base Branch").
-				Placeholder(c.defaultBase).
-				Value(&c.baseBranch).
-				Validate(c.validateBaseBranch),
-		),
-	).
-		WithTheme(huh.ThemeCatppuccin()).
+	var groups []*huh.Group
+	if names := sortedTemplateNames(c.templates); len(names) > 0 {
+		options := []huh.Option[string]{huh.NewOption("none", "")}
+		for _, name := range names {
+			options = append(options, huh.NewOption(name, name))
+		}
+		groups = append(groups, huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Template").
+				Options(options...).
+				Value(&c.template),
+		))
+
+		for _, name := range names {
+			if group := c.varsGroup(name, c.templates[name]); group != nil {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Branch Name").
+			DescriptionFunc(c.branchDescription, &c.template).
+			Placeholder("feature/my-feature").
+			Value(&c.branchName).
+			Validate(c.validateBranchName),
+		huh.NewInput().
+			Title("Base Branch").
+			Placeholder(c.defaultBase).
+			Value(&c.baseBranch).
+			Validate(c.validateBaseBranch),
+	}
+	if len(c.agents) > 1 {
+		options := []huh.Option[string]{huh.NewOption("default", "")}
+		for _, name := range c.agents {
+			options = append(options, huh.NewOption(name, name))
+		}
+		fields = append(fields, huh.NewSelect[string]().
+			Title("Agent").
+			DescriptionFunc(c.agentDescription, &c.template).
+			Options(options...).
+			Value(&c.agent))
+	}
+	fields = append(fields,
+		huh.NewInput().
+			Title("Tags").
+			Description("Optional, comma-separated").
+			Placeholder("urgent, experiment").
+			Value(&c.tags).
+			Validate(c.validateTags),
+		huh.NewInput().
+			Title("Metadata").
+			Description("Optional, comma-separated key=value pairs").
+			Placeholder("team=infra, ticket=OCW-12").
+			Value(&c.metadata).
+			Validate(c.validateMetadata),
+		huh.NewText().
+			Title("Task").
+			Description("Optional, what the agent should work on").
+			Placeholder("Add rate limiting to the login endpoint...").
+			Lines(4).
+			Value(&c.task),
+		huh.NewText().
+			Title("Prompt").
+			DescriptionFunc(c.promptDescription, &c.template).
+			Placeholder("Add rate limiting to the login endpoint and cover it with tests").
+			Lines(4).
+			Value(&c.prompt),
+	)
+	groups = append(groups, huh.NewGroup(fields...))
+
+	c.form = huh.NewForm(groups...).
+		WithTheme(c.styles.Form).
+		WithKeyMap(c.keys.Form).
 		WithShowHelp(true).
 		WithShowErrors(true)
 }
 
+// varsGroup builds the inputs for a template's variables, shown only while
+// that template is selected
+func (c *Create) varsGroup(name string, tmpl config.Template) *huh.Group {
+	if len(tmpl.Vars) == 0 {
+		return nil
+	}
+
+	values := map[string]*string{}
+	c.vars[name] = values
+
+	fields := make([]huh.Field, 0, len(tmpl.Vars))
+	for _, v := range tmpl.Vars {
+		v := v
+		value := new(string)
+		values[v.Name] = value
+
+		description := v.Description
+		if v.Required && v.Default == "" {
+			description = strings.TrimSpace(description + " (required)")
+		}
+		fields = append(fields, huh.NewInput().
+			Title(v.Name).
+			Description(description).
+			Placeholder(v.Default).
+			Value(value).
+			Validate(func(s string) error { return validateVar(v, s) }))
+	}
+
+	return huh.NewGroup(fields...).
+		WithHideFunc(func() bool { return c.template != name })
+}
+
+// validateVar checks a variable value as ocw new --var would
+func validateVar(v config.Var, s string) error {
+	_, err := config.Template{Vars: []config.Var{v}}.ResolveVars(map[string]string{v.Name: s})
+	return err
+}
+
+// branchDescription explains what the branch input is used for with the
+// selected template
+func (c *Create) branchDescription() string {
+	tmpl, ok := c.templates[c.template]
+	if !ok || tmpl.BranchPattern == "" {
+		return ""
+	}
+	if tmpl.UsesVar(config.NameVar) {
+		return fmt.Sprintf("Fills {name} in %s", tmpl.BranchPattern)
+	}
+	return fmt.Sprintf("Optional, the template names the branch %s", tmpl.BranchPattern)
+}
+
+// agentDescription names the agent profile run by default with the selected
+// template
+func (c *Create) agentDescription() string {
+	if tmpl, ok := c.templates[c.template]; ok && tmpl.Agent != "" {
+		return fmt.Sprintf("Default: %s, from the template", tmpl.Agent)
+	}
+	return fmt.Sprintf("Default: %s", c.defaultAgent)
+}
+
+// promptDescription explains when the prompt is sent and what an empty one
+// falls back to
+func (c *Create) promptDescription() string {
+	if tmpl, ok := c.templates[c.template]; ok && tmpl.Prompt != "" {
+		return "Optional, sent once the agent is ready; empty uses the template's prompt"
+	}
+	return "Optional, sent once the agent is ready"
+}
+
+// sortedTemplateNames returns the template names in alphabetical order
+func sortedTemplateNames(templates map[string]config.Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // validateBranchName validates the branch name input
 func (c *Create) validateBranchName(s string) error {
 	if strings.TrimSpace(s) == "" {
+		// A template whose branch pattern does not use {name} names the branch itself
+		if tmpl, ok := c.templates[c.template]; ok && tmpl.BranchPattern != "" && !tmpl.UsesVar(config.NameVar) {
+			return nil
+		}
 		return fmt.Errorf("branch name cannot be empty")
 	}
 
@@ -110,6 +277,10 @@ func (c *Create) validateBranchName(s string) error {
 // validateBaseBranch validates the base branch input
 func (c *Create) validateBaseBranch(s string) error {
 	if strings.TrimSpace(s) == "" {
+		// The template's base branch, or the default, is used instead
+		if c.template != "" {
+			return nil
+		}
 		return fmt.Errorf("base branch cannot be empty")
 	}
 
@@ -121,12 +292,38 @@ func (c *Create) validateBaseBranch(s string) error {
 	return nil
 }
 
+// validateTags validates the comma-separated tags input
+func (c *Create) validateTags(s string) error {
+	for _, tag := range splitList(s) {
+		if err := state.ValidateTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMetadata validates the comma-separated key=value input
+func (c *Create) validateMetadata(s string) error {
+	_, err := state.ParseMetadata(splitList(s))
+	return err
+}
+
+// splitList splits a comma-separated input, dropping blank entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Update handles messages
 func (c *Create) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		if key.Matches(msg, c.keys.Back) {
 			// Cancel and return to dashboard
 			return c, nil
 		}
@@ -151,9 +348,10 @@ func (c *Create) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			c.creating = true
 			c.creationError = ""
 
-			// Use default base branch if not provided
+			// Use default base branch if not provided, unless the
+			// template sets one
 			baseBranch := c.baseBranch
-			if strings.TrimSpace(baseBranch) == "" {
+			if strings.TrimSpace(baseBranch) == "" && c.template == "" {
 				baseBranch = c.defaultBase
 			}
 
@@ -172,10 +370,30 @@ func (c *Create) createInstanceCmd(branchName, baseBranch string) tea.Cmd {
 			return CreateMsg{Error: fmt.Errorf("manager not available")}
 		}
 
+		meta, err := state.ParseMetadata(splitList(c.metadata))
+		if err != nil {
+			return CreateMsg{Error: err}
+		}
+
 		opts := workspace.CreateOpts{
-			Name:       branchName,
-			Branch:     branchName,
-			BaseBranch: baseBranch,
+			Branch:     strings.TrimSpace(branchName),
+			BaseBranch: strings.TrimSpace(baseBranch),
+			Tags:       splitList(c.tags),
+			Metadata:   meta,
+			Task:       c.task,
+			Prompt:     strings.TrimSpace(c.prompt),
+			Template:   c.template,
+			Agent:      c.agent,
+		}
+		if c.template == "" {
+			opts.Name = opts.Branch
+		} else {
+			opts.Vars = map[string]string{}
+			for name, value := range c.vars[c.template] {
+				if *value != "" {
+					opts.Vars[name] = *value
+				}
+			}
 		}
 
 		instance, err := c.manager.CreateInstance(opts)
@@ -194,7 +412,7 @@ func (c *Create) View() string {
 	}
 
 	title := c.styles.Title.Render("Create New Instance")
-	help := c.styles.Help.Render("Press esc to cancel")
+	help := c.styles.Help.Render(fmt.Sprintf("Press %s to cancel", c.keys.Back.Help().Key))
 
 	formView := c.form.View()
 
@@ -210,7 +428,7 @@ func (c *Create) View() string {
 
 // renderSpinner renders a loading spinner during creation
 func (c *Create) renderSpinner() string {
-	spinner := "⠋ Creating instance..."
+	spinner := c.styles.Symbols.Spinner + " Creating instance..."
 	title := c.styles.Title.Render("Create New Instance")
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -224,7 +442,7 @@ func (c *Create) renderSpinner() string {
 func (c *Create) renderError() string {
 	title := c.styles.Title.Render("Create New Instance")
 	errorMsg := c.styles.Error.Render(fmt.Sprintf("Error: %s", c.creationError))
-	help := c.styles.Help.Render("Press esc to go back")
+	help := c.styles.Help.Render(fmt.Sprintf("Press %s to go back", c.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"strings"
@@ -21,7 +22,7 @@ type InstanceItem struct {
 }
 
 func (i InstanceItem) FilterValue() string {
-	return i.instance.Name
+	return strings.TrimSpace(i.instance.Name + " " + i.instance.Labels())
 }
 
 func (i InstanceItem) Title() string {
@@ -40,17 +41,28 @@ type StatusStyles struct {
 	Paused   lipgloss.Style
 	Error    lipgloss.Style
 	Merged   lipgloss.Style
-	Done     lipgloss.Style
+	PROpen   lipgloss.Style
 	Conflict lipgloss.Style
 }
 
+// DashboardStyles holds styling for the dashboard view
+type DashboardStyles struct {
+	Status  StatusStyles
+	Title   lipgloss.Style
+	Header  lipgloss.Style
+	Footer  lipgloss.Style
+	Preview lipgloss.Style
+	Symbols Symbols
+}
+
 type CustomDelegate struct {
 	statusStyles StatusStyles
+	symbols      Symbols
 	allInstances []state.Instance
 }
 
-func NewCustomDelegate(statusStyles StatusStyles, allInstances []state.Instance) *CustomDelegate {
-	return &CustomDelegate{statusStyles: statusStyles, allInstances: allInstances}
+func NewCustomDelegate(statusStyles StatusStyles, symbols Symbols, allInstances []state.Instance) *CustomDelegate {
+	return &CustomDelegate{statusStyles: statusStyles, symbols: symbols, allInstances: allInstances}
 }
 
 // Height returns the height of a list item
@@ -76,7 +88,7 @@ func (d *CustomDelegate) Render(w io.Writer, m list.Model, index int, item list.
 
 	inst := i.instance
 
-	statusIcon := d.getStatusIcon(inst.Status)
+	statusIcon := d.symbols.StatusIcon(inst.Status)
 	statusStyle := d.getStatusStyle(inst.Status)
 
 	elapsed := time.Since(inst.CreatedAt)
@@ -98,7 +110,7 @@ func (d *CustomDelegate) Render(w io.Writer, m list.Model, index int, item list.
 
 	conflictStr := ""
 	if len(inst.ConflictsWith) > 0 {
-		conflictStr = " ⚠"
+		conflictStr = " " + d.symbols.Conflict
 	}
 
 	depStr := ""
@@ -114,7 +126,7 @@ func (d *CustomDelegate) Render(w io.Writer, m list.Model, index int, item list.
 			}
 		}
 		if len(depNames) > 0 {
-			depStr = fmt.Sprintf(" → depends on %s", strings.Join(depNames, ", "))
+			depStr = fmt.Sprintf(" %s depends on %s", d.symbols.Arrow, strings.Join(depNames, ", "))
 		}
 	}
 
@@ -128,48 +140,55 @@ func (d *CustomDelegate) Render(w io.Writer, m list.Model, index int, item list.
 		depStr,
 	)
 
-	secondLine := fmt.Sprintf("   Branch: %s → %s",
+	secondLine := fmt.Sprintf("   Branch: %s %s %s",
 		inst.Branch,
+		d.symbols.Arrow,
 		inst.BaseBranch,
 	)
+	if ports := inst.PortList(); ports != "" {
+		secondLine += fmt.Sprintf(" | Ports: %s", ports)
+	}
+	if inst.FanOut != "" {
+		secondLine += fmt.Sprintf(" | Fan-out: %s", inst.FanOut)
+		if inst.Variant != "" {
+			secondLine += fmt.Sprintf(" (%s)", inst.Variant)
+		}
+	}
+	if inst.ForkedFrom != "" {
+		secondLine += fmt.Sprintf(" | Forked from: %s", d.instanceName(inst.ForkedFrom))
+	}
+	if labels := inst.Labels(); labels != "" {
+		secondLine += fmt.Sprintf(" | %s", labels)
+	}
 
 	fmt.Fprintf(w, "%s\n%s", firstLine, secondLine)
 }
 
-// getStatusIcon returns the icon for a given status
-func (d *CustomDelegate) getStatusIcon(status string) string {
-	switch status {
-	case "running":
-		return "●"
-	case "idle":
-		return "○"
-	case "paused":
-		return "⏸"
-	case "error":
-		return "✗"
-	case "merged":
-		return "✓"
-	case "done":
-		return "✔"
-	default:
-		return "?"
+// instanceName returns the name of the instance with the given ID, or the ID
+// if it is no longer listed, e.g. because it was deleted
+func (d *CustomDelegate) instanceName(id string) string {
+	for _, inst := range d.allInstances {
+		if inst.ID == id {
+			return inst.Name
+		}
 	}
+	return id
 }
 
-func (d *CustomDelegate) getStatusStyle(status string) lipgloss.Style {
+func (d *CustomDelegate) getStatusStyle(status state.Status) lipgloss.Style {
 	switch status {
-	case "running":
+	case state.StatusRunning:
 		return d.statusStyles.Active
-	case "idle":
+	case state.StatusCreating, state.StatusIdle:
 		return d.statusStyles.Idle
-	case "paused":
+	case state.StatusPaused, state.StatusExited, state.StatusAbandoned:
 		return d.statusStyles.Paused
-	case "error":
+	case state.StatusError:
 		return d.statusStyles.Error
-	case "merged":
+	case state.StatusPROpen:
+		return d.statusStyles.PROpen
+	case state.StatusMerged:
 		return d.statusStyles.Merged
-	case "done":
-		return d.statusStyles.Done
 	default:
 		return d.statusStyles.Idle
 	}
@@ -194,7 +213,7 @@ type Dashboard struct {
 	instances      []state.Instance
 	width          int
 	height         int
-	statusStyles   StatusStyles
+	styles         DashboardStyles
 	lastRefresh    time.Time
 	lastConflict   time.Time
 	selectedIndex  int
@@ -203,14 +222,16 @@ type Dashboard struct {
 	manager        *workspace.Manager
 	previewContent string
 	lastPreviewIdx int
+	selector       string
+	hints          []key.Binding
 }
 
-func NewDashboard(instances []state.Instance, statusStyles StatusStyles, manager *workspace.Manager) *Dashboard {
+func NewDashboard(instances []state.Instance, styles DashboardStyles, manager *workspace.Manager, keys ListKeyMap) *Dashboard {
 	d := &Dashboard{
 		instances:      instances,
 		width:          80,
 		height:         24,
-		statusStyles:   statusStyles,
+		styles:         styles,
 		lastRefresh:    time.Now(),
 		lastConflict:   time.Now(),
 		selectedIndex:  0,
@@ -224,16 +245,44 @@ func NewDashboard(instances []state.Instance, statusStyles StatusStyles, manager
 		items[i] = InstanceItem{instance: inst}
 	}
 
-	delegate := NewCustomDelegate(statusStyles, instances)
+	delegate := NewCustomDelegate(styles.Status, styles.Symbols, instances)
 	l := list.New(items, delegate, 80, 20)
 	l.Title = "OCW Instances"
+	l.Styles.Title = styles.Title
+	l.Paginator.ActiveDot = styles.Header.Render(styles.Symbols.Paginator[0])
+	l.Paginator.InactiveDot = styles.Footer.Render(styles.Symbols.Paginator[1])
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(false)
+	l.DisableQuitKeybindings()
+	// Only the configured navigation applies; actions are handled by the app
+	l.KeyMap = list.KeyMap{
+		CursorUp:   keys.Up,
+		CursorDown: keys.Down,
+		PrevPage:   keys.PageUp,
+		NextPage:   keys.PageDown,
+		GoToStart:  keys.Top,
+		GoToEnd:    keys.Bottom,
+	}
 	d.list = l
 
 	return d
 }
 
+// SetHints sets the bindings named in the footer
+func (d *Dashboard) SetHints(bindings ...key.Binding) {
+	d.hints = bindings
+}
+
+// SetSelector records the selector the instances were filtered with, shown in the title
+func (d *Dashboard) SetSelector(selector string) {
+	d.selector = selector
+	if selector == "" {
+		d.list.Title = "OCW Instances"
+	} else {
+		d.list.Title = fmt.Sprintf("OCW Instances [%s]", selector)
+	}
+}
+
 func (d *Dashboard) SetSize(width, height int) {
 	d.width = width
 	d.height = height
@@ -285,22 +334,15 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		d.lastConflict = time.Now()
 		return d, d.tickConflict()
 
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
-			d.list.CursorUp()
-			d.updatePreview()
-		case "down", "j":
-			d.list.CursorDown()
-			d.updatePreview()
-		}
-
 	case tea.WindowSizeMsg:
 		d.SetSize(msg.Width, msg.Height)
 	}
 
 	var cmd tea.Cmd
 	d.list, cmd = d.list.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		d.updatePreview()
+	}
 	return d, cmd
 }
 
@@ -337,46 +379,71 @@ func (d *Dashboard) updatePreview() {
 	d.previewContent = strings.Join(lines, "\n")
 }
 
-func (d *Dashboard) View() string {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("62")).
-		Bold(true).
-		Padding(0, 1)
+// renderTaskPreview renders the start of an instance's task and its latest notes
+func renderTaskPreview(inst state.Instance, symbols Symbols) string {
+	const maxTaskLines = 4
+	const maxNotes = 3
 
-	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Padding(0, 1)
+	var lines []string
+	if task := strings.TrimSpace(inst.Task); task != "" {
+		taskLines := strings.Split(task, "\n")
+		if len(taskLines) > maxTaskLines {
+			taskLines = append(taskLines[:maxTaskLines], symbols.Ellipsis)
+		}
+		lines = append(lines, "Task:")
+		for _, line := range taskLines {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	if len(inst.Notes) > 0 {
+		notes := inst.Notes
+		if len(notes) > maxNotes {
+			notes = notes[len(notes)-maxNotes:]
+		}
+		lines = append(lines, fmt.Sprintf("Notes (%d):", len(inst.Notes)))
+		for _, note := range notes {
+			lines = append(lines, fmt.Sprintf("  %s  %s", note.Time.Format("Jan 2 15:04"), note.Text))
+		}
+	}
 
-	previewStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Padding(0, 1).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240"))
+	return strings.Join(lines, "\n")
+}
 
-	header := headerStyle.Render("OCW - Open Code Workspace")
+func (d *Dashboard) View() string {
+	header := d.styles.Header.Render("OCW - Open Code Workspace")
 
 	listView := d.list.View()
 
 	d.updatePreview()
 
 	var previewSection string
-	if d.previewContent != "" {
-		selectedIdx := d.list.Index()
-		if selectedIdx >= 0 && selectedIdx < len(d.instances) {
-			selectedName := d.instances[selectedIdx].Name
-			previewHeader := fmt.Sprintf("Preview: %s", selectedName)
-			previewBox := previewStyle.Render(d.previewContent)
-			previewSection = lipgloss.JoinVertical(
-				lipgloss.Left,
-				previewHeader,
-				previewBox,
-			)
+	selectedIdx := d.list.Index()
+	if selectedIdx >= 0 && selectedIdx < len(d.instances) {
+		selected := d.instances[selectedIdx]
+		taskPreview := renderTaskPreview(selected, d.styles.Symbols)
+
+		if d.previewContent != "" || taskPreview != "" {
+			parts := []string{fmt.Sprintf("Preview: %s", selected.Name)}
+			if taskPreview != "" {
+				parts = append(parts, taskPreview)
+			}
+			if d.previewContent != "" {
+				parts = append(parts, d.styles.Preview.Render(d.previewContent))
+			}
+			previewSection = lipgloss.JoinVertical(lipgloss.Left, parts...)
 		}
 	}
 
-	footer := footerStyle.Render(
-		fmt.Sprintf("Total instances: %d | Press ? for help | Press q to quit", len(d.instances)),
-	)
+	total := fmt.Sprintf("Total instances: %d", len(d.instances))
+	if d.selector != "" {
+		total = fmt.Sprintf("Matching instances: %d", len(d.instances))
+	}
+	footerText := total
+	if h := hints(d.hints...); h != "" {
+		footerText += " | " + h
+	}
+	footer := d.styles.Footer.Render(footerText)
 
 	if previewSection != "" {
 		return lipgloss.JoinVertical(
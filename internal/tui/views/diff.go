@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,6 +12,15 @@ import (
 	"github.com/tommyzliu/ocw/internal/state"
 )
 
+// DiffStyles holds styling for the diff view
+type DiffStyles struct {
+	Header  lipgloss.Style
+	Summary lipgloss.Style
+	Footer  lipgloss.Style
+	Files   FileStyles
+	Symbols Symbols
+}
+
 // Diff is the view for displaying git diff statistics
 type Diff struct {
 	instance   state.Instance
@@ -20,15 +30,19 @@ type Diff struct {
 	diffStat   git.DiffStat
 	diffFiles  []git.DiffFile
 	gitManager *git.Git
+	keys       PagerKeyMap
+	styles     DiffStyles
 	loading    bool
 	err        error
 }
 
 // NewDiff creates a new Diff view
-func NewDiff(instance state.Instance, gitManager *git.Git) *Diff {
+func NewDiff(instance state.Instance, gitManager *git.Git, keys PagerKeyMap, styles DiffStyles) *Diff {
 	d := &Diff{
 		instance:   instance,
 		gitManager: gitManager,
+		keys:       keys,
+		styles:     styles,
 		width:      80,
 		height:     24,
 		loading:    true,
@@ -102,20 +116,20 @@ func (d *Diff) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return d, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, d.keys.Back):
 			return d, nil // Signal to return to dashboard
-		case "up", "k":
+		case key.Matches(msg, d.keys.Up):
 			d.viewport.LineUp(1)
-		case "down", "j":
+		case key.Matches(msg, d.keys.Down):
 			d.viewport.LineDown(1)
-		case "pgup":
+		case key.Matches(msg, d.keys.PageUp):
 			d.viewport.PageUp()
-		case "pgdn":
+		case key.Matches(msg, d.keys.PageDown):
 			d.viewport.PageDown()
-		case "home":
+		case key.Matches(msg, d.keys.Top):
 			d.viewport.GotoTop()
-		case "end":
+		case key.Matches(msg, d.keys.Bottom):
 			d.viewport.GotoBottom()
 		}
 
@@ -147,72 +161,25 @@ func (d *Diff) renderContent() string {
 
 	// Render file list with status icons and colors
 	for _, file := range d.diffFiles {
-		icon := d.getStatusIcon(file.Status)
-		color := d.getStatusColor(file.Status)
-		styledIcon := color.Render(icon)
+		styledIcon := d.styles.Files.For(file.Status).Render(d.styles.Symbols.FileIcon(file.Status))
 		sb.WriteString(fmt.Sprintf("%s %s\n", styledIcon, file.Path))
 	}
 
 	return sb.String()
 }
 
-// getStatusIcon returns the icon for a file status
-func (d *Diff) getStatusIcon(status string) string {
-	switch status {
-	case "M":
-		return "●"
-	case "A":
-		return "+"
-	case "D":
-		return "✕"
-	case "R":
-		return "→"
-	default:
-		return "?"
-	}
-}
-
-// getStatusColor returns the color style for a file status
-func (d *Diff) getStatusColor(status string) lipgloss.Style {
-	switch status {
-	case "M":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow
-	case "A":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Green
-	case "D":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
-	case "R":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("4")) // Blue
-	default:
-		return lipgloss.NewStyle()
-	}
-}
-
 // View renders the diff view
 func (d *Diff) View() string {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("62")).
-		Bold(true).
-		Padding(0, 1)
-
-	summaryStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
-		Padding(0, 1)
-
-	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Padding(0, 1)
-
-	header := headerStyle.Render(fmt.Sprintf("Diff: %s → %s", d.instance.Branch, d.instance.BaseBranch))
+	header := d.styles.Header.Render(fmt.Sprintf("Diff: %s %s %s", d.instance.Branch, d.styles.Symbols.Arrow, d.instance.BaseBranch))
 
 	summary := ""
 	if !d.loading && d.err == nil {
-		summary = summaryStyle.Render(d.diffStat.Summary)
+		summary = d.styles.Summary.Render(d.diffStat.Summary)
 	}
 
 	viewportView := d.viewport.View()
 
-	footer := footerStyle.Render("↑/k: up | ↓/j: down | PgUp/PgDn: page | Home/End: jump | ESC: back")
+	footer := d.styles.Footer.Render(hints(d.keys.Up, d.keys.Down, d.keys.PageUp, d.keys.PageDown, d.keys.Top, d.keys.Bottom, d.keys.Back))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -0,0 +1,271 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tommyzliu/ocw/internal/workspace"
+)
+
+// FanOutStyles holds styling for the fan-out view
+type FanOutStyles struct {
+	Header   lipgloss.Style
+	Summary  lipgloss.Style
+	Footer   lipgloss.Style
+	Selected lipgloss.Style
+	Fail     lipgloss.Style
+	Warning  lipgloss.Style
+	Symbols  Symbols
+}
+
+// FanOut is the view comparing the siblings of a fan-out side by side: their
+// changes, merge conflicts and test results
+type FanOut struct {
+	id          string
+	manager     *workspace.Manager
+	comparisons []workspace.FanOutComparison
+	cursor      int
+	keys        FanOutKeyMap
+	styles      FanOutStyles
+	loading     bool
+	testing     bool
+	err         error
+	width       int
+	height      int
+}
+
+// NewFanOut creates a new FanOut view for the fan-out with the given ID
+func NewFanOut(id string, manager *workspace.Manager, keys FanOutKeyMap, styles FanOutStyles) *FanOut {
+	return &FanOut{
+		id:      id,
+		manager: manager,
+		keys:    keys,
+		styles:  styles,
+		loading: true,
+		width:   80,
+		height:  24,
+	}
+}
+
+// FanOutLoadedMsg is sent when the siblings of a fan-out have been compared
+type FanOutLoadedMsg struct {
+	Comparisons []workspace.FanOutComparison
+	Error       error
+}
+
+// PickWinnerRequestMsg asks the app to confirm keeping a sibling and
+// archiving the others
+type PickWinnerRequestMsg struct {
+	InstanceID string
+	Name       string
+	Others     int
+}
+
+// Init compares the siblings without running their tests
+func (f *FanOut) Init() tea.Cmd {
+	return f.load(false)
+}
+
+// load compares the siblings, running their tests if test is set
+func (f *FanOut) load(test bool) tea.Cmd {
+	return func() tea.Msg {
+		if f.manager == nil {
+			return FanOutLoadedMsg{Error: fmt.Errorf("manager not available")}
+		}
+		comparisons, err := f.manager.CompareFanOut(f.id, test)
+		return FanOutLoadedMsg{Comparisons: comparisons, Error: err}
+	}
+}
+
+// SetSize sets the size of the fan-out view
+func (f *FanOut) SetSize(width, height int) {
+	f.width = width
+	f.height = height
+}
+
+// Update handles messages
+func (f *FanOut) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case FanOutLoadedMsg:
+		f.loading = false
+		f.testing = false
+		f.err = msg.Error
+		if msg.Error == nil {
+			f.comparisons = msg.Comparisons
+			if f.cursor >= len(f.comparisons) {
+				f.cursor = len(f.comparisons) - 1
+			}
+		}
+		return f, nil
+
+	case tea.KeyMsg:
+		if f.loading || f.testing {
+			return f, nil
+		}
+		switch {
+		case key.Matches(msg, f.keys.Up):
+			if f.cursor > 0 {
+				f.cursor--
+			}
+		case key.Matches(msg, f.keys.Down):
+			if f.cursor < len(f.comparisons)-1 {
+				f.cursor++
+			}
+		case key.Matches(msg, f.keys.Test):
+			f.testing = true
+			f.err = nil
+			return f, f.load(true)
+		case key.Matches(msg, f.keys.Pick):
+			if f.cursor >= 0 && f.cursor < len(f.comparisons) {
+				inst := f.comparisons[f.cursor].Instance
+				return f, func() tea.Msg {
+					return PickWinnerRequestMsg{InstanceID: inst.ID, Name: inst.Name, Others: len(f.comparisons) - 1}
+				}
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		f.SetSize(msg.Width, msg.Height)
+	}
+
+	return f, nil
+}
+
+// View renders the fan-out view
+func (f *FanOut) View() string {
+	header := f.styles.Header.Render("Fan-out: " + f.id)
+
+	var content string
+	switch {
+	case f.loading:
+		content = "Comparing siblings..."
+	case f.err != nil:
+		content = fmt.Sprintf("Error: %v", f.err)
+	default:
+		content = f.renderTable() + "\n\n" + f.renderDetails()
+	}
+	if f.testing {
+		content += "\n\n" + f.styles.Summary.Render(f.styles.Symbols.Spinner+" Running tests in every sibling...")
+	}
+
+	k := f.keys
+	footer := f.styles.Footer.Render(hints(k.Up, k.Down, k.Test, k.Pick, k.Back))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		content,
+		"",
+		footer,
+	)
+}
+
+// renderTable lists the siblings, one row each, with the selected one marked
+func (f *FanOut) renderTable() string {
+	rows := [][]string{{"NAME", "VARIANT", "STATUS", "CHANGES", "CONFLICTS", "TESTS"}}
+	for _, c := range f.comparisons {
+		rows = append(rows, []string{
+			c.Instance.Name,
+			c.Instance.Variant,
+			string(c.Instance.Status),
+			fanOutChanges(c),
+			fanOutConflicts(c),
+			f.fanOutTests(c.Test),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = padRight(cell, widths[i])
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+
+		switch {
+		case r == 0:
+			lines[r] = "  " + f.styles.Summary.Render(line)
+		case r-1 == f.cursor:
+			lines[r] = f.styles.Selected.Render(f.styles.Symbols.Pointer + " " + line)
+		default:
+			lines[r] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderDetails shows why the selected sibling could not be compared, its
+// conflicting files and the output of its failed tests
+func (f *FanOut) renderDetails() string {
+	if f.cursor < 0 || f.cursor >= len(f.comparisons) {
+		return ""
+	}
+	c := f.comparisons[f.cursor]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s\n", c.Instance.Branch, c.Instance.WorktreePath)
+	if c.Err != nil {
+		b.WriteString(f.styles.Fail.Render(fmt.Sprintf("Could not compare: %v", c.Err)) + "\n")
+	}
+	if len(c.Conflicts) > 0 {
+		b.WriteString(f.styles.Warning.Render(f.styles.Symbols.Conflict+" Conflicts with "+c.Instance.BaseBranch+":") + "\n")
+		for _, file := range c.Conflicts {
+			fmt.Fprintf(&b, "  %s %s\n", f.styles.Symbols.Bullet, file)
+		}
+	}
+	if c.Test != nil && !c.Test.Passed && c.Test.Output != "" {
+		b.WriteString(f.styles.Fail.Render("Test output:") + "\n")
+		for _, line := range strings.Split(c.Test.Output, "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// fanOutChanges renders a sibling's changes against its base branch
+func fanOutChanges(c workspace.FanOutComparison) string {
+	if c.Err != nil {
+		return "?"
+	}
+	if c.Diff.FilesChanged == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files +%d -%d", c.Diff.FilesChanged, c.Diff.Insertions, c.Diff.Deletions)
+}
+
+// fanOutConflicts renders the number of files conflicting with the base branch
+func fanOutConflicts(c workspace.FanOutComparison) string {
+	if c.Err != nil {
+		return "?"
+	}
+	if len(c.Conflicts) == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files", len(c.Conflicts))
+}
+
+// fanOutTests renders a sibling's test run; "-" if not tested
+func (f *FanOut) fanOutTests(r *workspace.TestResult) string {
+	switch {
+	case r == nil:
+		return "-"
+	case r.Passed:
+		return fmt.Sprintf("%s passed (%s)", f.styles.Symbols.OK, r.Duration.Round(time.Second))
+	case r.Error != "":
+		return f.styles.Symbols.Failed + " " + r.Error
+	default:
+		return fmt.Sprintf("%s failed (%s)", f.styles.Symbols.Failed, r.Duration.Round(time.Second))
+	}
+}
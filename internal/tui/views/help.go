@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Help represents the help view
+// Help represents the help view. Its tables are generated from the
+// effective key bindings.
 type Help struct {
-	width  int
-	height int
-	styles HelpStyles
-	scroll int
+	width    int
+	height   int
+	styles   HelpStyles
+	keys     PagerKeyMap
+	sections []HelpSection
+	scroll   int
 }
 
 // HelpStyles holds styles for the help view
@@ -24,15 +28,18 @@ type HelpStyles struct {
 	Text      lipgloss.Style
 	Highlight lipgloss.Style
 	Footer    lipgloss.Style
+	Symbols   Symbols
 }
 
-// NewHelp creates a new help view
-func NewHelp(styles HelpStyles) *Help {
+// NewHelp creates a new help view listing the bindings of sections
+func NewHelp(styles HelpStyles, keys PagerKeyMap, sections []HelpSection) *Help {
 	return &Help{
-		width:  80,
-		height: 24,
-		styles: styles,
-		scroll: 0,
+		width:    80,
+		height:   24,
+		styles:   styles,
+		keys:     keys,
+		sections: sections,
+		scroll:   0,
 	}
 }
 
@@ -51,19 +58,24 @@ func (h *Help) Init() tea.Cmd {
 func (h *Help) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc", "?":
+		page := max(h.height-4, 1)
+		switch {
+		case key.Matches(msg, h.keys.Back):
 			// Return to dashboard
 			return h, nil
-		case "j", "down":
+		case key.Matches(msg, h.keys.Down):
 			h.scroll++
-		case "k", "up":
+		case key.Matches(msg, h.keys.Up):
 			if h.scroll > 0 {
 				h.scroll--
 			}
-		case "g":
+		case key.Matches(msg, h.keys.PageDown):
+			h.scroll += page
+		case key.Matches(msg, h.keys.PageUp):
+			h.scroll = max(h.scroll-page, 0)
+		case key.Matches(msg, h.keys.Top):
 			h.scroll = 0
-		case "G":
+		case key.Matches(msg, h.keys.Bottom):
 			h.scroll = 999 // Will be clamped by View()
 		}
 	}
@@ -93,77 +105,49 @@ func (h *Help) View() string {
 
 	// Build output
 	output := h.styles.Title.Render("OCW - Open Code Workspace Help") + "\n"
-	output += strings.Repeat("─", h.width) + "\n\n"
+	output += strings.Repeat(h.styles.Symbols.Rule, h.width) + "\n\n"
 	output += strings.Join(visibleLines, "\n")
 
 	// Add footer
-	footer := fmt.Sprintf("  [j/k or ↑/↓] scroll  [g/G] top/bottom  [?/esc] close  [%d/%d]",
-		h.scroll+1, len(lines))
+	footer := fmt.Sprintf("  %s  [%d/%d]",
+		hints(h.keys.Up, h.keys.Down, h.keys.Top, h.keys.Bottom, h.keys.Back), h.scroll+1, len(lines))
 	output += "\n\n" + h.styles.Footer.Render(footer)
 
 	return output
 }
 
-// buildContent builds the help content
+// buildContent builds the help content, one table per section. Disabled
+// bindings are left out.
 func (h *Help) buildContent() string {
 	var sb strings.Builder
 
-	// Dashboard hotkeys
-	sb.WriteString(h.styles.Header.Render("DASHBOARD HOTKEYS") + "\n")
-	sb.WriteString(h.buildTable([][]string{
-		{"Key", "Action"},
-		{"n", "Create new instance"},
-		{"d", "Delete selected instance"},
-		{"f", "Show diff for selected instance"},
-		{"m", "Merge selected instance"},
-		{"t", "Show sub-terminals for selected instance"},
-		{"r", "Refresh instances"},
-		{"enter", "Focus on selected instance"},
-		{"1-9", "Quick focus on instance 1-9"},
-	}))
-	sb.WriteString("\n")
-
-	// Navigation hotkeys
-	sb.WriteString(h.styles.Header.Render("NAVIGATION HOTKEYS") + "\n")
-	sb.WriteString(h.buildTable([][]string{
-		{"Key", "Action"},
-		{"↑/k", "Move up"},
-		{"↓/j", "Move down"},
-		{"←/h", "Move left"},
-		{"→/l", "Move right"},
-		{"esc", "Return to previous view"},
-	}))
-	sb.WriteString("\n")
-
-	// Global hotkeys
-	sb.WriteString(h.styles.Header.Render("GLOBAL HOTKEYS") + "\n")
-	sb.WriteString(h.buildTable([][]string{
-		{"Key", "Action"},
-		{"?", "Toggle help"},
-		{"q", "Quit"},
-		{"ctrl+c", "Quit"},
-	}))
-	sb.WriteString("\n")
-
-	// Help view hotkeys
-	sb.WriteString(h.styles.Header.Render("HELP VIEW HOTKEYS") + "\n")
-	sb.WriteString(h.buildTable([][]string{
-		{"Key", "Action"},
-		{"j/↓", "Scroll down"},
-		{"k/↑", "Scroll up"},
-		{"g", "Jump to top"},
-		{"G", "Jump to bottom"},
-		{"?/esc", "Close help"},
-	}))
-	sb.WriteString("\n")
+	for _, section := range h.sections {
+		rows := [][]string{{"Key", "Action"}}
+		for _, b := range section.Bindings {
+			if b.Enabled() {
+				rows = append(rows, []string{b.Help().Key, b.Help().Desc})
+			}
+		}
+		if len(rows) == 1 {
+			continue
+		}
+		sb.WriteString(h.styles.Header.Render(section.Title+" HOTKEYS") + "\n")
+		sb.WriteString(h.buildTable(rows))
+		sb.WriteString("\n")
+	}
 
 	// Tips
 	sb.WriteString(h.styles.Header.Render("TIPS") + "\n")
-	sb.WriteString("• Use keyboard shortcuts to navigate quickly\n")
-	sb.WriteString("• Press 'enter' to focus on a workspace and attach to its tmux window\n")
-	sb.WriteString("• Use 'f' to view changes before merging with 'm'\n")
-	sb.WriteString("• Create sub-terminals within a workspace for running tests/servers\n")
-	sb.WriteString("• OCW automatically detects conflicts between workspace changes\n")
+	for _, tip := range []string{
+		"Key bindings can be changed in the [keys] section of the config",
+		"Colors can be changed in the [ui.theme] section of the config",
+		"Focus a workspace to attach to its tmux window",
+		"Review the diff of a workspace before merging it",
+		"Create sub-terminals within a workspace for running tests/servers",
+		"OCW automatically detects conflicts between workspace changes",
+	} {
+		sb.WriteString(h.styles.Symbols.Bullet + " " + tip + "\n")
+	}
 
 	return sb.String()
 }
@@ -178,8 +162,8 @@ func (h *Help) buildTable(rows [][]string) string {
 	colWidths := make([]int, len(rows[0]))
 	for _, row := range rows {
 		for i, cell := range row {
-			if len(cell) > colWidths[i] {
-				colWidths[i] = len(cell)
+			if lipgloss.Width(cell) > colWidths[i] {
+				colWidths[i] = lipgloss.Width(cell)
 			}
 		}
 	}
@@ -205,7 +189,7 @@ func (h *Help) buildTable(rows [][]string) string {
 			totalWidth += 2
 		}
 	}
-	sb.WriteString(strings.Repeat("─", totalWidth))
+	sb.WriteString(strings.Repeat(h.styles.Symbols.Rule, totalWidth))
 	sb.WriteString("\n")
 
 	// Data rows
@@ -225,8 +209,8 @@ func (h *Help) buildTable(rows [][]string) string {
 
 // padRight pads a string to the right
 func padRight(s string, width int) string {
-	if len(s) >= width {
+	if lipgloss.Width(s) >= width {
 		return s
 	}
-	return s + strings.Repeat(" ", width-len(s))
+	return s + strings.Repeat(" ", width-lipgloss.Width(s))
 }
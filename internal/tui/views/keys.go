@@ -0,0 +1,74 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/huh"
+)
+
+// ListKeyMap moves through the dashboard's instance list
+type ListKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+}
+
+// PagerKeyMap scrolls the diff and help views
+type PagerKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Back     key.Binding
+}
+
+// LogKeyMap scrolls the log view and switches between pane and hook output
+type LogKeyMap struct {
+	PagerKeyMap
+	ToggleHooks key.Binding
+}
+
+// FormKeyMap drives the huh forms of the create and merge views
+type FormKeyMap struct {
+	Form *huh.KeyMap
+	Back key.Binding
+}
+
+// MergeKeyMap adds conflict resolution to the merge form
+type MergeKeyMap struct {
+	FormKeyMap
+	ResolveConflicts key.Binding
+}
+
+// FanOutKeyMap moves through the siblings of a fan-out, tests them and picks the winner
+type FanOutKeyMap struct {
+	Up   key.Binding
+	Down key.Binding
+	Test key.Binding
+	Pick key.Binding
+	Back key.Binding
+}
+
+// HelpSection is a titled group of bindings listed by the help view
+type HelpSection struct {
+	Title    string
+	Bindings []key.Binding
+}
+
+// hints renders bindings as a footer line, e.g. "↑/k: up | esc: back".
+// Disabled bindings are left out.
+func hints(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+": "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
@@ -2,7 +2,10 @@ package views
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -10,22 +13,37 @@ import (
 	"github.com/tommyzliu/ocw/internal/tmux"
 )
 
-// Log is the view for displaying pane scrollback history
+// LogStyles holds styling for the log view
+type LogStyles struct {
+	Header  lipgloss.Style
+	Footer  lipgloss.Style
+	Symbols Symbols
+}
+
+// Log is the view for displaying pane scrollback history, or the output of
+// the instance's lifecycle hooks
 type Log struct {
-	instance state.Instance
-	viewport viewport.Model
-	width    int
-	height   int
-	tmux     *tmux.Tmux
-	loading  bool
-	err      error
+	instance  state.Instance
+	viewport  viewport.Model
+	width     int
+	height    int
+	tmux      *tmux.Tmux
+	store     *state.Store
+	keys      LogKeyMap
+	styles    LogStyles
+	showHooks bool
+	loading   bool
+	err       error
 }
 
 // NewLog creates a new Log view
-func NewLog(instance state.Instance, tmuxClient *tmux.Tmux) *Log {
+func NewLog(instance state.Instance, tmuxClient *tmux.Tmux, store *state.Store, keys LogKeyMap, styles LogStyles) *Log {
 	l := &Log{
 		instance: instance,
 		tmux:     tmuxClient,
+		store:    store,
+		keys:     keys,
+		styles:   styles,
 		width:    80,
 		height:   24,
 		loading:  true,
@@ -62,6 +80,49 @@ func (l *Log) loadLogs() tea.Cmd {
 	}
 }
 
+// loadHookRuns loads the output of the instance's hook runs
+func (l *Log) loadHookRuns() tea.Cmd {
+	return func() tea.Msg {
+		if l.store == nil {
+			return LogLoadedMsg{Error: fmt.Errorf("state store not available")}
+		}
+
+		runs, err := l.store.ReadHookRuns(l.instance.ID)
+		if err != nil {
+			return LogLoadedMsg{Error: err}
+		}
+
+		return LogLoadedMsg{
+			Content: formatHookRuns(runs, l.styles.Symbols),
+		}
+	}
+}
+
+// formatHookRuns renders hook runs oldest first, each with its output indented
+func formatHookRuns(runs []state.HookRun, symbols Symbols) string {
+	if len(runs) == 0 {
+		return "No hooks have run for this instance."
+	}
+
+	var b strings.Builder
+	for _, run := range runs {
+		result := symbols.OK
+		if run.Error != "" {
+			result = symbols.Failed + " " + run.Error
+		} else if run.ExitCode != 0 {
+			result = fmt.Sprintf("%s exit %d", symbols.Failed, run.ExitCode)
+		}
+		duration := (time.Duration(run.DurationMS) * time.Millisecond).Round(time.Millisecond)
+		fmt.Fprintf(&b, "%s  %s  %s  %s (%s)\n", run.Time.Format("2006-01-02 15:04:05"), run.Hook, run.Command, result, duration)
+		for _, line := range strings.Split(strings.TrimRight(run.Output, "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
 // LogLoadedMsg is sent when log data is loaded
 type LogLoadedMsg struct {
 	Content string
@@ -91,20 +152,28 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return l, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, l.keys.Back):
 			return l, nil // Signal to return to dashboard
-		case "up", "k":
+		case key.Matches(msg, l.keys.ToggleHooks):
+			l.showHooks = !l.showHooks
+			l.loading = true
+			l.err = nil
+			if l.showHooks {
+				return l, l.loadHookRuns()
+			}
+			return l, l.loadLogs()
+		case key.Matches(msg, l.keys.Up):
 			l.viewport.LineUp(1)
-		case "down", "j":
+		case key.Matches(msg, l.keys.Down):
 			l.viewport.LineDown(1)
-		case "pgup":
+		case key.Matches(msg, l.keys.PageUp):
 			l.viewport.PageUp()
-		case "pgdn":
+		case key.Matches(msg, l.keys.PageDown):
 			l.viewport.PageDown()
-		case "home":
+		case key.Matches(msg, l.keys.Top):
 			l.viewport.GotoTop()
-		case "end":
+		case key.Matches(msg, l.keys.Bottom):
 			l.viewport.GotoBottom()
 		}
 
@@ -117,16 +186,11 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the log view
 func (l *Log) View() string {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("62")).
-		Bold(true).
-		Padding(0, 1)
-
-	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Padding(0, 1)
-
-	header := headerStyle.Render(fmt.Sprintf("Logs: %s", l.instance.Name))
+	title := "Logs"
+	if l.showHooks {
+		title = "Hook output"
+	}
+	header := l.styles.Header.Render(fmt.Sprintf("%s: %s", title, l.instance.Name))
 
 	var content string
 	if l.loading {
@@ -137,7 +201,8 @@ func (l *Log) View() string {
 		content = l.viewport.View()
 	}
 
-	footer := footerStyle.Render("↑/k: up | ↓/j: down | PgUp/PgDn: page | Home/End: jump | ESC: back")
+	k := l.keys
+	footer := l.styles.Footer.Render(hints(k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom, k.ToggleHooks, k.Back))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -62,6 +63,7 @@ type Merge struct {
 	prURL             string
 	allInstances      []state.Instance
 	styles            MergeStyles
+	keys              MergeKeyMap
 }
 
 // MergeStyles holds styling for the merge view
@@ -72,10 +74,14 @@ type MergeStyles struct {
 	Warning    lipgloss.Style
 	Help       lipgloss.Style
 	FormBorder lipgloss.Style
+	Summary    lipgloss.Style
+	Files      FileStyles
+	Form       *huh.Theme
+	Symbols    Symbols
 }
 
 // NewMerge creates a new Merge view
-func NewMerge(instance state.Instance, manager *workspace.Manager, gitManager *git.Git, tmuxClient *tmux.Tmux, allInstances []state.Instance, styles MergeStyles) *Merge {
+func NewMerge(instance state.Instance, manager *workspace.Manager, gitManager *git.Git, tmuxClient *tmux.Tmux, allInstances []state.Instance, styles MergeStyles, keys MergeKeyMap) *Merge {
 	m := &Merge{
 		instance:          instance,
 		manager:           manager,
@@ -86,12 +92,21 @@ func NewMerge(instance state.Instance, manager *workspace.Manager, gitManager *g
 		width:             80,
 		height:            24,
 		styles:            styles,
+		keys:              keys,
 		conflictCheckDone: false,
 		depCheckDone:      false,
 		merging:           false,
-		// Default PR title: format branch name
-		prTitle: formatBranchNameForPR(instance.Branch),
-		prBody:  generatePRDescriptionFromActivity(instance, tmuxClient),
+	}
+
+	// Seed the PR from the task and notes; fall back to the branch name and
+	// the agent's scrollback for instances created without a task
+	m.prTitle = workspace.PRTitleFromTask(instance)
+	if m.prTitle == "" {
+		m.prTitle = formatBranchNameForPR(instance.Branch)
+	}
+	m.prBody = workspace.PRBodyFromTask(instance)
+	if m.prBody == "" {
+		m.prBody = generatePRDescriptionFromActivity(instance, tmuxClient)
 	}
 
 	m.buildForm()
@@ -328,7 +343,8 @@ func (m *Merge) buildForm() {
 				CharLimit(5000),
 		),
 	).
-		WithTheme(huh.ThemeCatppuccin()).
+		WithTheme(m.styles.Form).
+		WithKeyMap(m.keys.Form).
 		WithShowHelp(true).
 		WithShowErrors(true)
 }
@@ -387,10 +403,10 @@ func (m *Merge) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			return m, nil
-		case "c":
+		case key.Matches(msg, m.keys.ResolveConflicts):
 			if m.hasConflicts {
 				return m, func() tea.Msg {
 					return ResolveConflictsRequestMsg{InstanceID: m.instance.ID}
@@ -471,8 +487,8 @@ func (m *Merge) View() string {
 
 // renderLoading renders a loading state
 func (m *Merge) renderLoading() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
-	spinner := "⠋ Checking conflicts and dependencies..."
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
+	spinner := m.styles.Symbols.Spinner + " Checking conflicts and dependencies..."
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
@@ -483,17 +499,17 @@ func (m *Merge) renderLoading() string {
 
 // renderConflicts renders the conflict warning
 func (m *Merge) renderConflicts() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
 
-	warning := m.styles.Warning.Render("⚠ Cannot merge: conflicts detected")
+	warning := m.styles.Warning.Render(m.styles.Symbols.Conflict + " Cannot merge: conflicts detected")
 
 	var conflictList strings.Builder
 	conflictList.WriteString("\nConflicting files:\n")
 	for _, file := range m.conflictFiles {
-		conflictList.WriteString(fmt.Sprintf("  • %s\n", file))
+		conflictList.WriteString(fmt.Sprintf("  %s %s\n", m.styles.Symbols.Bullet, file))
 	}
 
-	help := m.styles.Help.Render("Press 'c' to resolve conflicts | ESC to go back")
+	help := m.styles.Help.Render(fmt.Sprintf("Press %s to resolve conflicts | %s to go back", m.keys.ResolveConflicts.Help().Key, m.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -507,9 +523,9 @@ func (m *Merge) renderConflicts() string {
 }
 
 func (m *Merge) renderUnmergedDeps() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
 
-	warning := m.styles.Warning.Render("⚠ Cannot merge: unmerged dependencies")
+	warning := m.styles.Warning.Render(m.styles.Symbols.Conflict + " Cannot merge: unmerged dependencies")
 
 	nameMap := make(map[string]string)
 	for _, inst := range m.allInstances {
@@ -523,10 +539,10 @@ func (m *Merge) renderUnmergedDeps() string {
 		if n, ok := nameMap[depID]; ok {
 			name = n
 		}
-		depList.WriteString(fmt.Sprintf("  • %s (%s)\n", name, depID))
+		depList.WriteString(fmt.Sprintf("  %s %s (%s)\n", m.styles.Symbols.Bullet, name, depID))
 	}
 
-	help := m.styles.Help.Render("ESC to go back")
+	help := m.styles.Help.Render(fmt.Sprintf("%s to go back", m.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -540,13 +556,10 @@ func (m *Merge) renderUnmergedDeps() string {
 }
 
 func (m *Merge) renderForm() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
 
 	// Diff summary
-	summaryStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
-		Padding(0, 1)
-	summary := summaryStyle.Render(m.diffStat.Summary)
+	summary := m.styles.Summary.Render(m.diffStat.Summary)
 
 	// File list (first 10 files)
 	var fileList strings.Builder
@@ -557,19 +570,17 @@ func (m *Merge) renderForm() string {
 			fileList.WriteString(fmt.Sprintf("  ... and %d more files\n", remaining))
 			break
 		}
-		icon := getStatusIcon(file.Status)
-		color := getStatusColor(file.Status)
-		styledIcon := color.Render(icon)
+		styledIcon := m.styles.Files.For(file.Status).Render(m.styles.Symbols.FileIcon(file.Status))
 		fileList.WriteString(fmt.Sprintf("  %s %s\n", styledIcon, file.Path))
 	}
 
 	// Conflict status
-	conflictStatus := m.styles.Success.Render("✓ No conflicts")
-	depStatus := m.styles.Success.Render("✓ Dependencies satisfied")
+	conflictStatus := m.styles.Success.Render(m.styles.Symbols.OK + " No conflicts")
+	depStatus := m.styles.Success.Render(m.styles.Symbols.OK + " Dependencies satisfied")
 
 	formView := m.form.View()
 
-	help := m.styles.Help.Render("Press Enter to push & create PR | ESC to cancel")
+	help := m.styles.Help.Render(fmt.Sprintf("Press %s to push & create PR | %s to cancel", m.keys.Form.Input.Submit.Help().Key, m.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -589,8 +600,8 @@ func (m *Merge) renderForm() string {
 
 // renderMerging renders the merging state
 func (m *Merge) renderMerging() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
-	spinner := "⠋ Pushing branch and creating PR..."
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
+	spinner := m.styles.Symbols.Spinner + " Pushing branch and creating PR..."
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
@@ -601,13 +612,13 @@ func (m *Merge) renderMerging() string {
 
 // renderSuccess renders the success state
 func (m *Merge) renderSuccess() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
 
-	success := m.styles.Success.Render("✓ Pull request created successfully!")
+	success := m.styles.Success.Render(m.styles.Symbols.OK + " Pull request created successfully!")
 
 	prURLText := fmt.Sprintf("PR URL: %s", m.prURL)
 
-	help := m.styles.Help.Render("Press ESC to return to dashboard")
+	help := m.styles.Help.Render(fmt.Sprintf("Press %s to return to dashboard", m.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -623,9 +634,9 @@ func (m *Merge) renderSuccess() string {
 
 // renderError renders the error state
 func (m *Merge) renderError() string {
-	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s → %s", m.instance.Branch, m.instance.BaseBranch))
+	title := m.styles.Title.Render(fmt.Sprintf("Merge: %s %s %s", m.instance.Branch, m.styles.Symbols.Arrow, m.instance.BaseBranch))
 	errorMsg := m.styles.Error.Render(fmt.Sprintf("Error: %s", m.mergeError))
-	help := m.styles.Help.Render("Press ESC to go back")
+	help := m.styles.Help.Render(fmt.Sprintf("Press %s to go back", m.keys.Back.Help().Key))
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -636,35 +647,3 @@ func (m *Merge) renderError() string {
 		help,
 	)
 }
-
-// getStatusIcon returns the icon for a file status
-func getStatusIcon(status string) string {
-	switch status {
-	case "M":
-		return "●"
-	case "A":
-		return "+"
-	case "D":
-		return "✕"
-	case "R":
-		return "→"
-	default:
-		return "?"
-	}
-}
-
-// getStatusColor returns the color style for a file status
-func getStatusColor(status string) lipgloss.Style {
-	switch status {
-	case "M":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow
-	case "A":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Green
-	case "D":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
-	case "R":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("4")) // Blue
-	default:
-		return lipgloss.NewStyle()
-	}
-}
@@ -0,0 +1,128 @@
+package views
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// Symbols are the icons and markers drawn by the views. ASCIISymbols
+// replaces them for terminals and screen readers that cannot render Unicode.
+type Symbols struct {
+	Status    map[state.Status]string
+	Unknown   string            // status or file change without an icon
+	File      map[string]string // by git status letter: M, A, D or R
+	Conflict  string
+	Arrow     string
+	Bullet    string
+	Ellipsis  string
+	Pointer   string // marks the selected entry of a list
+	Cursor    string // end of a text input
+	Spinner   string
+	OK        string
+	Failed    string
+	Rule      string // repeated to draw horizontal lines
+	Border    lipgloss.Border
+	Paginator [2]string // active and inactive page dots
+}
+
+// UnicodeSymbols returns the default symbols
+func UnicodeSymbols() Symbols {
+	return Symbols{
+		Status: map[state.Status]string{
+			state.StatusCreating:  "◐",
+			state.StatusRunning:   "●",
+			state.StatusIdle:      "○",
+			state.StatusPaused:    "⏸",
+			state.StatusExited:    "■",
+			state.StatusError:     "✗",
+			state.StatusPROpen:    "⇡",
+			state.StatusMerged:    "✓",
+			state.StatusAbandoned: "⊘",
+		},
+		Unknown:   "?",
+		File:      map[string]string{"M": "●", "A": "+", "D": "✕", "R": "→"},
+		Conflict:  "⚠",
+		Arrow:     "→",
+		Bullet:    "•",
+		Ellipsis:  "…",
+		Pointer:   "▸",
+		Cursor:    "█",
+		Spinner:   "⠋",
+		OK:        "✓",
+		Failed:    "✗",
+		Rule:      "─",
+		Border:    lipgloss.RoundedBorder(),
+		Paginator: [2]string{"•", "○"},
+	}
+}
+
+// ASCIISymbols returns symbols made of plain ASCII characters
+func ASCIISymbols() Symbols {
+	return Symbols{
+		Status: map[state.Status]string{
+			state.StatusCreating:  "~",
+			state.StatusRunning:   "*",
+			state.StatusIdle:      "o",
+			state.StatusPaused:    "=",
+			state.StatusExited:    "#",
+			state.StatusError:     "x",
+			state.StatusPROpen:    "^",
+			state.StatusMerged:    "+",
+			state.StatusAbandoned: "-",
+		},
+		Unknown:   "?",
+		File:      map[string]string{"M": "M", "A": "A", "D": "D", "R": "R"},
+		Conflict:  "!",
+		Arrow:     "->",
+		Bullet:    "-",
+		Ellipsis:  "...",
+		Pointer:   ">",
+		Cursor:    "_",
+		Spinner:   "*",
+		OK:        "OK",
+		Failed:    "FAILED",
+		Rule:      "-",
+		Border:    lipgloss.ASCIIBorder(),
+		Paginator: [2]string{"*", "."},
+	}
+}
+
+// StatusIcon returns the icon for an instance status
+func (s Symbols) StatusIcon(status state.Status) string {
+	if icon, ok := s.Status[status]; ok {
+		return icon
+	}
+	return s.Unknown
+}
+
+// FileIcon returns the icon for a git file status
+func (s Symbols) FileIcon(status string) string {
+	if icon, ok := s.File[status]; ok {
+		return icon
+	}
+	return s.Unknown
+}
+
+// FileStyles color changed files by their git status
+type FileStyles struct {
+	Added    lipgloss.Style
+	Modified lipgloss.Style
+	Deleted  lipgloss.Style
+	Renamed  lipgloss.Style
+}
+
+// For returns the style of a git file status
+func (f FileStyles) For(status string) lipgloss.Style {
+	switch status {
+	case "M":
+		return f.Modified
+	case "A":
+		return f.Added
+	case "D":
+		return f.Deleted
+	case "R":
+		return f.Renamed
+	default:
+		return lipgloss.NewStyle()
+	}
+}
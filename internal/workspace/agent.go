@@ -0,0 +1,331 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
+)
+
+// agentReadyTimeout bounds the wait for an agent to accept input
+const agentReadyTimeout = 30 * time.Second
+
+// agentSettleTime is how long the pane output of an agent without a
+// ready_pattern must stay unchanged before it is taken as ready
+const agentSettleTime = time.Second
+
+// agentProfile resolves the agent profile of a new instance: the one asked
+// for, else the template's, else workspace.agent. The template's command,
+// args and model replace the profile's.
+func (m *Manager) agentProfile(name string, tmpl config.Template) (string, config.AgentConfig, error) {
+	if name == "" {
+		name = tmpl.Agent
+	}
+	if name == "" {
+		name = m.config.Workspace.Agent
+	}
+	agent, err := m.config.Agent(name)
+	if err != nil {
+		return "", agent, err
+	}
+
+	if tmpl.Command != "" {
+		agent.Command = tmpl.Command
+	}
+	if tmpl.Args != nil {
+		agent.Args = tmpl.Args
+	}
+	if tmpl.Model != "" {
+		agent.Model = tmpl.Model
+	}
+	return name, agent, nil
+}
+
+// instanceAgent returns the agent profile an instance runs
+func (m *Manager) instanceAgent(inst state.Instance) (config.AgentConfig, error) {
+	name := inst.Agent
+	if name == "" {
+		name = config.OpenCodeAgent
+	}
+	return m.config.Agent(name)
+}
+
+// agentCommand builds the command line launching an agent in an instance.
+// It is empty for a profile without a command.
+func agentCommand(agent config.AgentConfig, inst state.Instance) string {
+	if strings.TrimSpace(agent.Command) == "" {
+		return ""
+	}
+
+	vars := map[string]string{
+		"id":       inst.ID,
+		"name":     inst.Name,
+		"branch":   inst.Branch,
+		"model":    agent.Model,
+		"provider": agent.Provider,
+	}
+	parts := []string{config.Expand(agent.Command, vars)}
+	parts = append(parts, agent.Args...)
+
+	if agent.Model != "" && agent.ModelFlag != "" {
+		parts = append(parts, agent.ModelFlag, agent.Model)
+	}
+	if agent.Provider != "" && agent.ProviderFlag != "" {
+		parts = append(parts, agent.ProviderFlag, agent.Provider)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// launchCommand is what the primary pane runs: the init command, if any, then
+// the agent, which replaces the shell so that it is the pane's process and
+// its PID the pane's
+func launchCommand(command, initCommand string) string {
+	if initCommand == "" {
+		return "exec " + command
+	}
+	return initCommand + "; exec " + command
+}
+
+// commandName is the program a command line runs, as tmux reports it in
+// pane_current_command
+func commandName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// launchAgent runs the agent as the process of an instance's primary pane,
+// after the init command, and waits until it has started. It returns the
+// agent's PID.
+func (m *Manager) launchAgent(inst state.Instance, agent config.AgentConfig, command, initCommand string) (int, error) {
+	if err := m.tmux.RespawnPane(inst.PrimaryPane, inst.WorktreePath, launchCommand(command, initCommand), instanceEnv(inst)...); err != nil {
+		return 0, err
+	}
+
+	pane, err := m.waitStarted(inst, agent, command, initCommand)
+	if err != nil {
+		return 0, err
+	}
+	return pane.PID, nil
+}
+
+// waitStarted waits until the agent in an instance's primary pane has
+// started: until its ready_pattern shows or, for agents without one, until
+// the pane's foreground command is the agent. Time spent in the init command
+// does not count against agentReadyTimeout. An agent that exits or does not
+// start in time is reported with the pane's output.
+func (m *Manager) waitStarted(inst state.Instance, agent config.AgentConfig, command, initCommand string) (tmux.PaneInfo, error) {
+	var ready *regexp.Regexp
+	if agent.ReadyPattern != "" {
+		var err error
+		if ready, err = regexp.Compile(agent.ReadyPattern); err != nil {
+			return tmux.PaneInfo{}, fmt.Errorf("invalid ready_pattern: %w", err)
+		}
+	}
+	name, initName := commandName(command), commandName(initCommand)
+
+	deadline := time.Now().Add(agentReadyTimeout)
+	for {
+		pane, err := m.paneInfo(inst.TmuxWindow, inst.PrimaryPane)
+		if err != nil {
+			return pane, err
+		}
+		content, _ := m.tmux.CapturePaneText(inst.PrimaryPane)
+
+		switch {
+		case pane.Dead:
+			reason := name + " exited"
+			if pane.ExitStatus != 0 {
+				reason += fmt.Sprintf(" with status %d", pane.ExitStatus)
+			}
+			return pane, agentStartError(reason, content)
+		case ready != nil && ready.MatchString(paneText(content)):
+			return pane, nil
+		case ready == nil && pane.Command == name:
+			return pane, nil
+		case initName != "" && initName != name && pane.Command == initName:
+			deadline = time.Now().Add(agentReadyTimeout)
+		}
+
+		if time.Now().After(deadline) {
+			reason := fmt.Sprintf("%s is not running after %s (the pane runs %s)", name, agentReadyTimeout, pane.Command)
+			if ready != nil {
+				reason = fmt.Sprintf("ready_pattern %q did not match within %s", agent.ReadyPattern, agentReadyTimeout)
+			}
+			return pane, agentStartError(reason, content)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// paneInfo returns the tmux details of a pane in window
+func (m *Manager) paneInfo(window, pane string) (tmux.PaneInfo, error) {
+	panes, err := m.tmux.ListPanes(window)
+	if err != nil {
+		return tmux.PaneInfo{}, err
+	}
+	for _, p := range panes {
+		if p.ID == pane {
+			return p, nil
+		}
+	}
+	return tmux.PaneInfo{}, fmt.Errorf("pane %s not found in window %s", pane, window)
+}
+
+// agentStartError reports an agent that failed to start with the last lines
+// of its pane
+func agentStartError(reason, content string) error {
+	msg := "agent failed to start: " + reason
+	if output := lastLines(paneText(content), 20); output != "" {
+		msg += "\n\nPane output:\n" + output
+	}
+	return fmt.Errorf("%s\n\nTo fix:\n  1. Run the agent's command in the worktree to see why it fails\n  2. Check the profile's command and args: ocw config get agents\n  3. If the agent runs under another name (e.g. node) or starts slowly, set or fix ready_pattern on its profile", msg)
+}
+
+// SendPrompt submits a prompt to an instance's agent the way its profile
+// expects.
+func (m *Manager) SendPrompt(id, prompt string) error {
+	inst, err := m.GetInstance(id)
+	if err != nil {
+		return err
+	}
+
+	agent, err := m.instanceAgent(*inst)
+	if err != nil {
+		return err
+	}
+
+	return m.sendPrompt(inst.PrimaryPane, agent, prompt)
+}
+
+// sendPrompt types or pastes a prompt into pane and submits it
+func (m *Manager) sendPrompt(pane string, agent config.AgentConfig, prompt string) error {
+	var err error
+	if agent.PromptMode() == config.PromptPaste {
+		err = m.tmux.PasteText(pane, prompt)
+	} else {
+		err = m.tmux.SendText(pane, prompt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send prompt: %w", err)
+	}
+
+	if err := m.tmux.SendKey(pane, agent.Submit()); err != nil {
+		return fmt.Errorf("failed to submit prompt: %w", err)
+	}
+	return nil
+}
+
+// deliverPrompt submits the initial prompt of a new instance once its agent
+// is ready and records it on the instance
+func (m *Manager) deliverPrompt(inst state.Instance, agent config.AgentConfig, prompt string) error {
+	if err := m.waitReady(inst.PrimaryPane, agent); err != nil {
+		return err
+	}
+	if err := m.sendPrompt(inst.PrimaryPane, agent, prompt); err != nil {
+		return err
+	}
+
+	if err := m.store.UpdateInstance(inst.ID, func(i *state.Instance) {
+		i.Prompt = prompt
+	}); err != nil {
+		return fmt.Errorf("failed to record prompt: %w", err)
+	}
+
+	ev := newEvent(state.EventPromptSent, inst)
+	ev.Reason = TaskSummary(prompt)
+	m.recordEvent(ev)
+	return nil
+}
+
+// waitReady waits until the agent in pane accepts input: until its
+// ready_pattern shows or, for agents without one, until its output has
+// settled.
+func (m *Manager) waitReady(pane string, agent config.AgentConfig) error {
+	var ready *regexp.Regexp
+	if agent.ReadyPattern != "" {
+		var err error
+		if ready, err = regexp.Compile(agent.ReadyPattern); err != nil {
+			return fmt.Errorf("invalid ready_pattern: %w", err)
+		}
+	}
+
+	deadline := time.Now().Add(agentReadyTimeout)
+	var last string
+	var lastChange time.Time
+	for {
+		content, err := m.tmux.CapturePaneText(pane)
+		if err != nil {
+			return err
+		}
+		text := paneText(content)
+
+		if ready != nil {
+			if ready.MatchString(text) {
+				return nil
+			}
+		} else if text != last || lastChange.IsZero() {
+			last, lastChange = text, time.Now()
+		} else if text != "" && time.Since(lastChange) >= agentSettleTime {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if ready != nil {
+				return fmt.Errorf("agent did not become ready within %s (ready_pattern %q never matched)", agentReadyTimeout, agent.ReadyPattern)
+			}
+			return fmt.Errorf("agent output did not settle within %s\n\nTo fix:\n  1. Set a ready_pattern on the agent profile so readiness can be told from its output", agentReadyTimeout)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// exitStatus returns the status of an instance whose agent exited with the
+// given exit status, and why
+func exitStatus(agent config.AgentConfig, code int) (state.Status, string) {
+	switch agent.ExitMode() {
+	case config.ExitError:
+		return state.StatusError, fmt.Sprintf("agent exited with status %d", code)
+	case config.ExitStatus:
+		if code != 0 {
+			return state.StatusError, fmt.Sprintf("agent failed with exit status %d", code)
+		}
+	}
+	return state.StatusExited, fmt.Sprintf("agent exited with status %d", code)
+}
+
+// paneText strips trailing whitespace from every line of captured pane
+// content and drops the blank lines below the last output, so that patterns
+// can anchor on line ends
+func paneText(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// activityStatus tells from an agent's visible pane output whether it is
+// working or waiting for input. ok is false for agents without an
+// idle_pattern, whose activity cannot be told.
+func activityStatus(agent config.AgentConfig, content string) (status state.Status, ok bool) {
+	if agent.IdlePattern == "" {
+		return "", false
+	}
+	idle, err := regexp.Compile(agent.IdlePattern)
+	if err != nil {
+		return "", false
+	}
+	if idle.MatchString(paneText(content)) {
+		return state.StatusIdle, true
+	}
+	return state.StatusRunning, true
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestAgentProfile(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OpenCode.Args = []string{"--verbose"}
+	cfg.Agents["aider"] = config.AgentConfig{Command: "aider", Model: "sonnet", ModelFlag: "--model", Prompt: config.PromptPaste}
+	m := &Manager{config: cfg}
+	inst := state.Instance{ID: "abc123", Name: "login", Branch: "feature/login"}
+
+	name, agent, err := m.agentProfile("", config.Template{})
+	require.NoError(t, err)
+	assert.Equal(t, "opencode", name)
+	assert.Equal(t, "opencode --verbose --model claude-sonnet-4-5 --provider Sisyphus", agentCommand(agent, inst))
+
+	// The template's command, args and model replace the profile's
+	_, agent, err = m.agentProfile("", config.Template{Command: "aider", Args: []string{}, Model: "gpt-5"})
+	require.NoError(t, err)
+	assert.Equal(t, "aider --model gpt-5 --provider Sisyphus", agentCommand(agent, inst))
+
+	name, agent, err = m.agentProfile("", config.Template{Agent: "aider"})
+	require.NoError(t, err)
+	assert.Equal(t, "aider", name)
+	assert.Equal(t, "aider --model sonnet", agentCommand(agent, inst))
+
+	// An explicit profile wins over the template's
+	name, agent, err = m.agentProfile("shell", config.Template{Agent: "aider"})
+	require.NoError(t, err)
+	assert.Equal(t, "shell", name)
+	assert.Empty(t, agentCommand(agent, inst))
+
+	_, _, err = m.agentProfile("codex", config.Template{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `agent profile "codex" not found (available: aider, opencode, shell)`)
+}
+
+func TestAgentCommandPlaceholders(t *testing.T) {
+	agent := config.AgentConfig{
+		Command:      "claude --session-name {name} --model {model}",
+		Model:        "opus",
+		Provider:     "anthropic",
+		ProviderFlag: "--provider",
+		Args:         []string{"--verbose"},
+	}
+	inst := state.Instance{ID: "abc123", Name: "login", Branch: "feature/login"}
+
+	assert.Equal(t, "claude --session-name login --model opus --verbose --provider anthropic", agentCommand(agent, inst))
+}
+
+func TestLaunchCommand(t *testing.T) {
+	assert.Equal(t, "exec aider --yes", launchCommand("aider --yes", ""))
+	assert.Equal(t, "make deps; exec aider --yes", launchCommand("aider --yes", "make deps"))
+
+	assert.Equal(t, "aider", commandName("aider --yes"))
+	assert.Equal(t, "opencode", commandName("/usr/local/bin/opencode"))
+	assert.Equal(t, "", commandName("  "))
+}
+
+func TestAgentStartError(t *testing.T) {
+	err := agentStartError("aider exited with status 1", "$ aider\nerror: no API key   \n\n")
+	assert.Contains(t, err.Error(), "agent failed to start: aider exited with status 1\n\nPane output:\n$ aider\nerror: no API key\n\nTo fix:")
+
+	err = agentStartError("aider exited", "\n")
+	assert.NotContains(t, err.Error(), "Pane output")
+}
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		onExit string
+		code   int
+		want   state.Status
+	}{
+		{"", 1, state.StatusExited},
+		{config.ExitExited, 0, state.StatusExited},
+		{config.ExitStatus, 0, state.StatusExited},
+		{config.ExitStatus, 2, state.StatusError},
+		{config.ExitError, 0, state.StatusError},
+	}
+
+	for _, tt := range tests {
+		status, reason := exitStatus(config.AgentConfig{OnExit: tt.onExit}, tt.code)
+		assert.Equal(t, tt.want, status, "on_exit %q, exit status %d", tt.onExit, tt.code)
+		assert.NotEmpty(t, reason)
+	}
+}
+
+func TestActivityStatus(t *testing.T) {
+	_, ok := activityStatus(config.AgentConfig{}, "> ")
+	assert.False(t, ok, "activity is unknown without an idle_pattern")
+
+	agent := config.AgentConfig{IdlePattern: `>$`}
+	status, ok := activityStatus(agent, "Done.\n> \n\n")
+	require.True(t, ok)
+	assert.Equal(t, state.StatusIdle, status)
+
+	status, ok = activityStatus(agent, "> fix the tests\nThinking...\n")
+	require.True(t, ok)
+	assert.Equal(t, state.StatusRunning, status)
+}
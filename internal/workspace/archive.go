@@ -0,0 +1,263 @@
+package workspace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+const (
+	// archiveRefPrefix holds the branch tip of each archived instance
+	archiveRefPrefix = "refs/ocw/archive/"
+	// archiveStashRefPrefix holds stashed uncommitted changes of archived instances
+	archiveStashRefPrefix = "refs/ocw/archive-stash/"
+)
+
+// archiveRefs backs up the branch tip of an instance under refs/ocw/archive/<id>
+// and, if stash is set, its uncommitted changes under refs/ocw/archive-stash/<id>.
+// The returned entry is filled in as far as the backup got, even on error.
+func (m *Manager) archiveRefs(inst state.Instance, stash bool) (state.ArchivedInstance, error) {
+	archived := state.ArchivedInstance{
+		Instance:   inst,
+		ArchivedAt: time.Now(),
+	}
+
+	sha, err := m.instanceHead(inst)
+	if err != nil {
+		return archived, err
+	}
+
+	headRef := archiveRefPrefix + inst.ID
+	if err := m.git.UpdateRef(headRef, sha); err != nil {
+		return archived, err
+	}
+	archived.HeadRef = headRef
+	archived.HeadSHA = sha
+
+	if !stash {
+		return archived, nil
+	}
+
+	wt := git.NewGit(inst.WorktreePath)
+	dirty, err := wt.HasUncommittedChanges()
+	if err != nil || !dirty {
+		// A missing worktree has nothing to stash
+		return archived, nil
+	}
+
+	stashSHA, err := wt.StashSnapshot("ocw archive " + inst.ID)
+	if err != nil {
+		return archived, err
+	}
+
+	stashRef := archiveStashRefPrefix + inst.ID
+	if err := m.git.UpdateRef(stashRef, stashSHA); err != nil {
+		return archived, err
+	}
+	archived.StashRef = stashRef
+
+	return archived, nil
+}
+
+// instanceHead returns the commit checked out in the instance's worktree,
+// falling back to the branch tip if the worktree is gone.
+func (m *Manager) instanceHead(inst state.Instance) (string, error) {
+	if worktrees, err := m.git.WorktreeList(); err == nil {
+		for _, wt := range worktrees {
+			if wt.Path == inst.WorktreePath && wt.Head != "" {
+				return wt.Head, nil
+			}
+		}
+	}
+
+	sha, err := m.git.RevParse("refs/heads/" + inst.Branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the commit of branch %q: %w", inst.Branch, err)
+	}
+	return sha, nil
+}
+
+// ListArchive returns the archived instances, oldest first.
+func (m *Manager) ListArchive() ([]state.ArchivedInstance, error) {
+	st, err := m.store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	return st.Archive, nil
+}
+
+// RestoreInstance recreates an archived instance under its original ID: the branch
+// is recreated from the archive ref if it was deleted, then the worktree is created
+// again with its stashed changes and template files, followed by the tmux window and
+// sub-terminals.
+func (m *Manager) RestoreInstance(id string) (*state.Instance, error) {
+	st, err := m.store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	for _, inst := range st.Instances {
+		if inst.ID == id {
+			return nil, fmt.Errorf("instance %q is already active", id)
+		}
+	}
+
+	var entry *state.ArchivedInstance
+	for i := range st.Archive {
+		if st.Archive[i].Instance.ID == id {
+			entry = &st.Archive[i]
+			break
+		}
+	}
+
+	if entry == nil {
+		return nil, fmt.Errorf("archived instance %q not found\n\nTo fix:\n  1. List archived instances: ocw archive list\n  2. Use the ID shown there", id)
+	}
+
+	archived := entry.Instance
+
+	if !m.git.BranchExists(archived.Branch) {
+		if err := m.git.CreateBranch(archived.Branch, entry.HeadRef); err != nil {
+			return nil, fmt.Errorf("failed to recreate branch %q from %s: %w", archived.Branch, entry.HeadRef, err)
+		}
+	}
+
+	restored, err := m.CreateInstance(CreateOpts{
+		ID:         archived.ID,
+		Name:       archived.Name,
+		Branch:     archived.Branch,
+		BaseBranch: archived.BaseBranch,
+		Tags:       archived.Tags,
+		Metadata:   archived.Metadata,
+		Task:       archived.Task,
+		Notes:      archived.Notes,
+		Env:        archived.Env,
+		Agent:      archived.Agent,
+		Template:   archived.Template,
+		Restore:    true,
+		Stash:      entry.StashRef, // applied before the template's files are copied
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to recreate instance: %w", err)
+	}
+
+	var restoreErrs []error
+
+	for _, sub := range archived.SubTerminals {
+		if _, err := m.CreateSubTerminal(restored.ID, sub.Label); err != nil {
+			restoreErrs = append(restoreErrs, fmt.Errorf("failed to recreate sub-terminal %q: %w", sub.Label, err))
+		}
+	}
+
+	// Drop the archive entry and bring back dependencies on instances that still exist
+	err = m.store.Transact(func(st *state.State) error {
+		live := make(map[string]bool, len(st.Instances))
+		for _, inst := range st.Instances {
+			live[inst.ID] = true
+		}
+
+		for i := range st.Instances {
+			if st.Instances[i].ID != restored.ID {
+				continue
+			}
+			for _, dep := range archived.DependsOn {
+				if live[dep] && dep != restored.ID {
+					st.Instances[i].DependsOn = append(st.Instances[i].DependsOn, dep)
+				}
+			}
+			*restored = st.Instances[i]
+		}
+
+		kept := make([]state.ArchivedInstance, 0, len(st.Archive))
+		for _, a := range st.Archive {
+			if a.Instance.ID != id {
+				kept = append(kept, a)
+			}
+		}
+		st.Archive = kept
+		return nil
+	})
+	if err != nil {
+		return restored, fmt.Errorf("instance restored but failed to update the archive: %w", err)
+	}
+
+	// The branch and worktree hold the work again
+	_ = m.git.DeleteRef(entry.HeadRef)
+	if entry.StashRef != "" {
+		_ = m.git.DeleteRef(entry.StashRef)
+	}
+
+	ev := newEvent(state.EventRestored, *restored)
+	ev.To = string(restored.Status)
+	ev.Details = map[string]string{"archived_at": entry.ArchivedAt.Format(time.RFC3339)}
+	m.recordEvent(ev)
+
+	if len(restoreErrs) > 0 {
+		return restored, fmt.Errorf("instance restored with problems: %w", restoreErrs[0])
+	}
+
+	return restored, nil
+}
+
+// PurgeArchive permanently removes archived instances and their backup refs.
+// If ids is non-empty only those entries are purged; otherwise every entry
+// archived before olderThan is purged (all entries if olderThan is zero).
+func (m *Manager) PurgeArchive(ids []string, olderThan time.Time) ([]state.ArchivedInstance, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var purged []state.ArchivedInstance
+	err := m.store.Transact(func(st *state.State) error {
+		purged = nil
+
+		found := make(map[string]bool)
+		kept := make([]state.ArchivedInstance, 0, len(st.Archive))
+		for _, a := range st.Archive {
+			var purge bool
+			if len(ids) > 0 {
+				purge = wanted[a.Instance.ID]
+			} else {
+				purge = olderThan.IsZero() || a.ArchivedAt.Before(olderThan)
+			}
+
+			if purge {
+				purged = append(purged, a)
+				found[a.Instance.ID] = true
+			} else {
+				kept = append(kept, a)
+			}
+		}
+
+		for _, id := range ids {
+			if !found[id] {
+				return fmt.Errorf("archived instance %q not found", id)
+			}
+		}
+
+		st.Archive = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range purged {
+		if a.HeadRef != "" {
+			_ = m.git.DeleteRef(a.HeadRef)
+		}
+		if a.StashRef != "" {
+			_ = m.git.DeleteRef(a.StashRef)
+		}
+
+		ev := newEvent(state.EventArchivePurged, a.Instance)
+		ev.Details = map[string]string{"archived_at": a.ArchivedAt.Format(time.RFC3339)}
+		m.recordEvent(ev)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,181 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
+)
+
+// newTestRepo creates a git repository with one commit and a worktree on branch feature
+func newTestRepo(t *testing.T) (repo string, worktree string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	// Stashing creates commits, which needs an identity
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo = t.TempDir()
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	runGit(repo, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "README.md"), []byte("hello\n"), 0644))
+	runGit(repo, "add", ".")
+	runGit(repo, "commit", "-q", "-m", "initial")
+
+	worktree = filepath.Join(repo, ".worktrees", "feature")
+	runGit(repo, "worktree", "add", "-q", "-b", "feature", worktree, "main")
+
+	return repo, worktree
+}
+
+func newTestManager(repo string) *Manager {
+	return &Manager{
+		git:      git.NewGit(repo),
+		store:    state.NewStore(repo),
+		config:   config.DefaultConfig(),
+		repoRoot: repo,
+	}
+}
+
+func TestArchiveRefsStashesChanges(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	// Tracked modification and an untracked file
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "README.md"), []byte("changed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "notes.txt"), []byte("wip\n"), 0644))
+
+	inst := state.Instance{ID: "abc123", Branch: "feature", WorktreePath: worktree}
+
+	archived, err := m.archiveRefs(inst, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "refs/ocw/archive/abc123", archived.HeadRef)
+	assert.Equal(t, "refs/ocw/archive-stash/abc123", archived.StashRef)
+	assert.True(t, m.git.RefExists(archived.HeadRef))
+	assert.True(t, m.git.RefExists(archived.StashRef))
+
+	head, err := m.git.RevParse("refs/heads/feature")
+	require.NoError(t, err)
+	assert.Equal(t, head, archived.HeadSHA)
+
+	// Worktree is clean and the stash list is left untouched
+	dirty, err := git.NewGit(worktree).HasUncommittedChanges()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.False(t, m.git.RefExists("refs/stash"))
+
+	// Changes come back when the stash is applied
+	require.NoError(t, git.NewGit(worktree).StashApply(archived.StashRef))
+	data, err := os.ReadFile(filepath.Join(worktree, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "wip\n", string(data))
+}
+
+func TestArchiveRefsWithoutStash(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "notes.txt"), []byte("wip\n"), 0644))
+
+	archived, err := m.archiveRefs(state.Instance{ID: "abc123", Branch: "feature", WorktreePath: worktree}, false)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, archived.HeadRef)
+	assert.Empty(t, archived.StashRef)
+
+	dirty, err := git.NewGit(worktree).HasUncommittedChanges()
+	require.NoError(t, err)
+	assert.True(t, dirty, "changes must stay in the worktree when not stashing")
+}
+
+func TestPurgeArchive(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	old, err := m.archiveRefs(state.Instance{ID: "old111", Branch: "feature", WorktreePath: worktree}, false)
+	require.NoError(t, err)
+	old.ArchivedAt = time.Now().Add(-48 * time.Hour)
+
+	recent, err := m.archiveRefs(state.Instance{ID: "new222", Branch: "feature", WorktreePath: worktree}, false)
+	require.NoError(t, err)
+
+	require.NoError(t, m.store.Transact(func(st *state.State) error {
+		st.Archive = append(st.Archive, old, recent)
+		return nil
+	}))
+
+	purged, err := m.PurgeArchive(nil, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(purged))
+	assert.Equal(t, "old111", purged[0].Instance.ID)
+	assert.False(t, m.git.RefExists(old.HeadRef))
+	assert.True(t, m.git.RefExists(recent.HeadRef))
+
+	_, err = m.PurgeArchive([]string{"missing"}, time.Time{})
+	assert.Error(t, err)
+
+	purged, err = m.PurgeArchive([]string{"new222"}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(purged))
+
+	archived, err := m.ListArchive()
+	require.NoError(t, err)
+	assert.Empty(t, archived)
+}
+
+func TestRestoreInstanceKeepsTemplate(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-server").Run() })
+
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+	m.tmux = tmux.NewTmux()
+	m.config.Workspace.Agent = "shell"
+	m.config.Workspace.Templates["web"] = config.Template{Files: []string{".env.local"}}
+
+	// The template's file was changed in the instance before it was deleted
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".env.local"), []byte("PORT=1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".env.local"), []byte("PORT=2\n"), 0644))
+	inst := state.Instance{ID: "abc123", Name: "feature", Branch: "feature", BaseBranch: "main", WorktreePath: worktree, Template: "web"}
+	archived, err := m.archiveRefs(inst, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, archived.StashRef)
+	require.NoError(t, m.git.WorktreeRemove(worktree, true))
+	require.NoError(t, m.store.Transact(func(st *state.State) error {
+		st.Archive = append(st.Archive, archived)
+		return nil
+	}))
+
+	restored, err := m.RestoreInstance("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "web", restored.Template)
+
+	data, err := os.ReadFile(filepath.Join(restored.WorktreePath, ".env.local"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=2\n", string(data), "the stashed version wins over the template's")
+	assert.False(t, m.git.RefExists(archived.StashRef))
+}
@@ -0,0 +1,314 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// BootstrapEntry is a file or link handled while bootstrapping a worktree
+type BootstrapEntry struct {
+	Path    string // relative to the repository root
+	Mode    string // how it was brought over; a reflink that fell back to copying is "copy"
+	Size    int64  // bytes copied or cloned
+	Skipped string // why it was left out; empty if it was brought over
+}
+
+// BootstrapReport lists what bootstrapping a worktree did
+type BootstrapReport struct {
+	Entries []BootstrapEntry
+}
+
+// Count returns the number of entries brought over with the given mode
+func (r BootstrapReport) Count(mode string) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Skipped == "" && e.Mode == mode {
+			n++
+		}
+	}
+	return n
+}
+
+// Skipped returns the entries that were left out
+func (r BootstrapReport) Skipped() []BootstrapEntry {
+	var skipped []BootstrapEntry
+	for _, e := range r.Entries {
+		if e.Skipped != "" {
+			skipped = append(skipped, e)
+		}
+	}
+	return skipped
+}
+
+// Summary describes the report in one line, e.g. "2 copied, 1 symlinked, 1 skipped"
+func (r BootstrapReport) Summary() string {
+	var parts []string
+	for _, mode := range []struct{ mode, verb string }{
+		{config.BootstrapCopy, "copied"},
+		{config.BootstrapReflink, "reflinked"},
+		{config.BootstrapSymlink, "symlinked"},
+	} {
+		if n := r.Count(mode.mode); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, mode.verb))
+		}
+	}
+	if n := len(r.Skipped()); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", n))
+	}
+	if len(parts) == 0 {
+		return "nothing to bootstrap"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Bootstrap brings the workspace.bootstrap files from the main checkout into
+// an instance's worktree again, replacing the copies there.
+func (m *Manager) Bootstrap(id string) (BootstrapReport, error) {
+	inst, err := m.GetInstance(id)
+	if err != nil {
+		return BootstrapReport{}, err
+	}
+	if _, err := os.Stat(inst.WorktreePath); err != nil {
+		return BootstrapReport{}, fmt.Errorf("worktree of %s not found at %s\n\nTo fix:\n  1. Reconcile the state: ocw status\n  2. Recreate the worktree: git worktree repair", inst.Name, inst.WorktreePath)
+	}
+
+	report, err := m.bootstrap(inst.WorktreePath)
+	if err != nil {
+		return report, err
+	}
+	m.recordBootstrap(*inst, report)
+	return report, nil
+}
+
+// recordBootstrap records what bootstrapping an instance's worktree did.
+// Nothing is recorded when no bootstrap files are configured.
+func (m *Manager) recordBootstrap(inst state.Instance, report BootstrapReport) {
+	if len(report.Entries) == 0 {
+		return
+	}
+
+	ev := newEvent(state.EventBootstrapped, inst)
+	ev.Reason = report.Summary()
+	if skipped := report.Skipped(); len(skipped) > 0 {
+		paths := make([]string, len(skipped))
+		for i, e := range skipped {
+			paths[i] = e.Path
+		}
+		ev.Details = map[string]string{"skipped": strings.Join(paths, ", ")}
+	}
+	m.recordEvent(ev)
+}
+
+// bootstrap brings the files matching workspace.bootstrap from the main
+// checkout into worktreePath. Files tracked by git, the .git and .ocw
+// directories and the worktrees themselves are never brought over. Problems
+// with single files are reported as skipped entries rather than errors.
+func (m *Manager) bootstrap(worktreePath string) (BootstrapReport, error) {
+	var report BootstrapReport
+	cfg := m.config.Workspace.Bootstrap
+
+	for _, file := range cfg.Files {
+		matches, err := filepath.Glob(filepath.Join(m.repoRoot, file.Pattern))
+		if err != nil {
+			return report, fmt.Errorf("invalid bootstrap pattern %q: %w", file.Pattern, err)
+		}
+
+		var paths []string
+		for _, src := range matches {
+			rel, err := filepath.Rel(m.repoRoot, src)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				return report, fmt.Errorf("file %q is outside the repository", src)
+			}
+			if !m.bootstrapManaged(rel) {
+				paths = append(paths, rel)
+			}
+		}
+
+		tracked, err := m.git.TrackedFiles(paths...)
+		if err != nil {
+			return report, fmt.Errorf("failed to check bootstrap files against git: %w", err)
+		}
+		t := newTrackedSet(tracked)
+
+		for _, rel := range paths {
+			entries := m.bootstrapPath(worktreePath, rel, file, t)
+			report.Entries = append(report.Entries, entries...)
+		}
+	}
+	return report, nil
+}
+
+// bootstrapPath brings one matched file or directory into worktreePath
+func (m *Manager) bootstrapPath(worktreePath, rel string, file config.BootstrapFile, tracked trackedSet) []BootstrapEntry {
+	src := filepath.Join(m.repoRoot, rel)
+	dst := filepath.Join(worktreePath, rel)
+	mode := file.ModeOrDefault()
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return []BootstrapEntry{{Path: rel, Mode: mode, Skipped: err.Error()}}
+	}
+
+	if mode == config.BootstrapSymlink {
+		entry := BootstrapEntry{Path: rel, Mode: mode}
+		switch {
+		case tracked[rel]:
+			entry.Skipped = "tracked by git"
+		case tracked.contains(rel):
+			entry.Skipped = "contains files tracked by git"
+		case m.bootstrapContainsWorktrees(rel):
+			entry.Skipped = "contains the worktrees"
+		default:
+			if err := linkPath(src, dst); err != nil {
+				entry.Skipped = err.Error()
+			}
+		}
+		return []BootstrapEntry{entry}
+	}
+
+	limit := file.SizeLimit(m.config.Workspace.Bootstrap.MaxSizeKB)
+	copyOne := func(rel string, info fs.FileInfo) BootstrapEntry {
+		entry := BootstrapEntry{Path: rel, Mode: mode}
+		switch {
+		case tracked[rel]:
+			entry.Skipped = "tracked by git"
+		case !info.Mode().IsRegular():
+			entry.Skipped = "not a regular file"
+		case limit > 0 && info.Size() > limit:
+			entry.Skipped = fmt.Sprintf("larger than %d KB", limit/1024)
+		default:
+			done, err := cloneFile(filepath.Join(m.repoRoot, rel), filepath.Join(worktreePath, rel), mode == config.BootstrapReflink)
+			if err != nil {
+				entry.Skipped = err.Error()
+			} else {
+				entry.Mode = done
+				entry.Size = info.Size()
+			}
+		}
+		return entry
+	}
+
+	if !info.IsDir() {
+		return []BootstrapEntry{copyOne(rel, info)}
+	}
+
+	var entries []BootstrapEntry
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fileRel, err := filepath.Rel(m.repoRoot, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if fileRel != rel && m.bootstrapManaged(fileRel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, copyOne(fileRel, info))
+		return nil
+	})
+	if err != nil {
+		entries = append(entries, BootstrapEntry{Path: rel, Mode: mode, Skipped: err.Error()})
+	}
+	return entries
+}
+
+// bootstrapManaged reports whether a path belongs to git or ocw rather than
+// the project: .git, .ocw and the worktree directory
+func (m *Manager) bootstrapManaged(rel string) bool {
+	worktrees := filepath.Clean(m.config.Workspace.WorktreeDir)
+	for _, dir := range []string{".git", ".ocw", worktrees} {
+		if rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// bootstrapContainsWorktrees reports whether the worktree directory lies
+// below rel, so that linking rel would link the worktrees into themselves
+func (m *Manager) bootstrapContainsWorktrees(rel string) bool {
+	worktrees := filepath.Clean(m.config.Workspace.WorktreeDir)
+	return rel == "." || strings.HasPrefix(worktrees, rel+string(filepath.Separator))
+}
+
+// trackedSet holds files tracked by git, relative to the repository root
+type trackedSet map[string]bool
+
+func newTrackedSet(files []string) trackedSet {
+	t := make(trackedSet, len(files))
+	for _, file := range files {
+		t[filepath.FromSlash(file)] = true
+	}
+	return t
+}
+
+// contains reports whether rel is a tracked file or a directory holding one
+func (t trackedSet) contains(rel string) bool {
+	if t[rel] {
+		return true
+	}
+	prefix := rel + string(filepath.Separator)
+	for file := range t {
+		if strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkPath points dst at src with a symlink, replacing a file or link at dst
+func linkPath(src, dst string) error {
+	if target, err := os.Readlink(dst); err == nil && target == src {
+		return nil
+	}
+	if info, err := os.Lstat(dst); err == nil {
+		if info.IsDir() {
+			return errors.New("a directory is in the way")
+		}
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(src, dst)
+}
+
+// cloneFile copies src to dst, or reflinks it if asked and the filesystem
+// supports it. It returns the mode that was used.
+func cloneFile(src, dst string, reflink bool) (string, error) {
+	// A link left by an earlier symlink bootstrap would be written through
+	if info, err := os.Lstat(dst); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(dst); err != nil {
+			return "", err
+		}
+	}
+	// dst may still be src itself, reached through a linked directory
+	if srcInfo, err := os.Stat(src); err == nil {
+		if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+			return "", errors.New("already shared with the main checkout")
+		}
+	}
+
+	if reflink && reflinkFile(src, dst) == nil {
+		return config.BootstrapReflink, nil
+	}
+	return config.BootstrapCopy, copyFile(src, dst)
+}
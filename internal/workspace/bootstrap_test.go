@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestBootstrap(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	write(".env", "SECRET=1\n")
+	write(".env.local", strings.Repeat("x", 2048))
+	write("certs/dev.pem", "cert")
+	write("node_modules/left-pad/index.js", "module.exports = 1\n")
+
+	m.config.Workspace.Bootstrap = config.BootstrapConfig{
+		MaxSizeKB: 1,
+		Files: []config.BootstrapFile{
+			{Pattern: ".env*"},
+			{Pattern: "README.md"},
+			{Pattern: "certs", Mode: config.BootstrapReflink},
+			{Pattern: "node_modules", Mode: config.BootstrapSymlink},
+			{Pattern: ".worktrees"},
+			{Pattern: "missing.txt"},
+		},
+	}
+
+	report, err := m.bootstrap(worktree)
+	require.NoError(t, err)
+
+	byPath := map[string]BootstrapEntry{}
+	for _, e := range report.Entries {
+		byPath[e.Path] = e
+	}
+	assert.Len(t, byPath, 5, "managed and missing paths are not reported")
+
+	assert.Equal(t, BootstrapEntry{Path: ".env", Mode: config.BootstrapCopy, Size: 9}, byPath[".env"])
+	assert.Equal(t, "larger than 1 KB", byPath[".env.local"].Skipped)
+	assert.Equal(t, "tracked by git", byPath["README.md"].Skipped)
+	assert.Contains(t, []string{config.BootstrapCopy, config.BootstrapReflink}, byPath[filepath.Join("certs", "dev.pem")].Mode)
+	assert.Empty(t, byPath["node_modules"].Skipped)
+
+	data, err := os.ReadFile(filepath.Join(worktree, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET=1\n", string(data))
+
+	_, err = os.Stat(filepath.Join(worktree, "certs", "dev.pem"))
+	assert.NoError(t, err)
+
+	target, err := os.Readlink(filepath.Join(worktree, "node_modules"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo, "node_modules"), target)
+
+	assert.Equal(t, 2, report.Count(config.BootstrapCopy)+report.Count(config.BootstrapReflink))
+	assert.Equal(t, 1, report.Count(config.BootstrapSymlink))
+	assert.Len(t, report.Skipped(), 2)
+}
+
+func TestBootstrapResync(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+	m.config.Workspace.Bootstrap.Files = []config.BootstrapFile{
+		{Pattern: ".env"},
+		{Pattern: "cache", Mode: config.BootstrapSymlink},
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".env"), []byte("A=1\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "cache"), 0755))
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "abc123", Name: "feature", Branch: "feature", WorktreePath: worktree, Status: state.StatusRunning}))
+
+	_, err := m.Bootstrap("abc123")
+	require.NoError(t, err)
+
+	// Edits in the main checkout are brought over again; links are kept
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".env"), []byte("A=2\n"), 0600))
+	report, err := m.Bootstrap("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "1 copied, 1 symlinked", report.Summary())
+
+	data, err := os.ReadFile(filepath.Join(worktree, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "A=2\n", string(data))
+
+	events, err := m.History(state.EventFilter{Instance: "abc123", Types: []state.EventType{state.EventBootstrapped}})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "1 copied, 1 symlinked", events[1].Reason)
+}
+
+func TestBootstrapDoesNotWriteThroughLinks(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "config", "local.yml"), []byte("a: 1\n"), 0644))
+
+	// A directory linked by an earlier bootstrap, then switched to copying
+	m.config.Workspace.Bootstrap.Files = []config.BootstrapFile{{Pattern: "config", Mode: config.BootstrapSymlink}}
+	_, err := m.bootstrap(worktree)
+	require.NoError(t, err)
+
+	m.config.Workspace.Bootstrap.Files = []config.BootstrapFile{{Pattern: "config"}}
+	report, err := m.bootstrap(worktree)
+	require.NoError(t, err)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "already shared with the main checkout", report.Entries[0].Skipped)
+
+	data, err := os.ReadFile(filepath.Join(repo, "config", "local.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1\n", string(data), "the source is left intact")
+}
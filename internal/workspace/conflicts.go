@@ -106,14 +106,21 @@ func (cd *ConflictDetector) UpdateInstanceConflicts(store *state.Store, instance
 		return fmt.Errorf("failed to detect conflicts: %w", err)
 	}
 
-	// Update each instance with its conflicts
+	// Update each instance with its conflicts in one transaction
+	checked := make(map[string]bool)
 	for _, inst := range instances {
-		conflictingIDs := conflicts[inst.ID]
-		if err := store.UpdateInstance(inst.ID, func(i *state.Instance) {
-			i.ConflictsWith = conflictingIDs
-		}); err != nil {
-			return fmt.Errorf("failed to update instance %s: %w", inst.ID, err)
+		checked[inst.ID] = true
+	}
+
+	if err := store.Transact(func(st *state.State) error {
+		for i := range st.Instances {
+			if checked[st.Instances[i].ID] {
+				st.Instances[i].ConflictsWith = conflicts[st.Instances[i].ID]
+			}
 		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update instance conflicts: %w", err)
 	}
 
 	return nil
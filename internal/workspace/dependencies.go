@@ -14,50 +14,55 @@ func (m *Manager) AddDependency(instanceID, dependsOnID string) error {
 		return fmt.Errorf("an instance cannot depend on itself")
 	}
 
-	st, err := m.store.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
-	}
+	var instance state.Instance
+	err := m.store.Transact(func(st *state.State) error {
+		var target *state.Instance
+		var dependsOnExists bool
+		for i := range st.Instances {
+			if st.Instances[i].ID == instanceID {
+				target = &st.Instances[i]
+			}
+			if st.Instances[i].ID == dependsOnID {
+				dependsOnExists = true
+			}
+		}
 
-	var instance *state.Instance
-	var dependsOnExists bool
-	for i := range st.Instances {
-		if st.Instances[i].ID == instanceID {
-			instance = &st.Instances[i]
+		if target == nil {
+			return fmt.Errorf("instance %q not found", instanceID)
 		}
-		if st.Instances[i].ID == dependsOnID {
-			dependsOnExists = true
+		if !dependsOnExists {
+			return fmt.Errorf("dependency instance %q not found", dependsOnID)
 		}
-	}
 
-	if instance == nil {
-		return fmt.Errorf("instance %q not found", instanceID)
-	}
-	if !dependsOnExists {
-		return fmt.Errorf("dependency instance %q not found", dependsOnID)
-	}
+		for _, dep := range target.DependsOn {
+			if dep == dependsOnID {
+				return fmt.Errorf("dependency already exists: %s depends on %s", instanceID, dependsOnID)
+			}
+		}
 
-	for _, dep := range instance.DependsOn {
-		if dep == dependsOnID {
-			return fmt.Errorf("dependency already exists: %s depends on %s", instanceID, dependsOnID)
+		target.DependsOn = append(target.DependsOn, dependsOnID)
+		if hasCycle(st.Instances) {
+			return fmt.Errorf("adding this dependency would create a circular dependency")
 		}
-	}
 
-	instance.DependsOn = append(instance.DependsOn, dependsOnID)
-	if hasCycle(st.Instances) {
-		return fmt.Errorf("adding this dependency would create a circular dependency")
+		instance = *target
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := m.store.Save(st); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
-	}
+	added := newEvent(state.EventDependencyAdded, instance)
+	added.Details = map[string]string{"depends_on": dependsOnID}
+	m.recordEvent(added)
 
 	return nil
 }
 
 // RemoveDependency removes a dependency from one instance to another.
 func (m *Manager) RemoveDependency(instanceID, dependsOnID string) error {
-	return m.store.UpdateInstance(instanceID, func(inst *state.Instance) {
+	var updated state.Instance
+	err := m.store.UpdateInstance(instanceID, func(inst *state.Instance) {
 		filtered := make([]string, 0, len(inst.DependsOn))
 		for _, dep := range inst.DependsOn {
 			if dep != dependsOnID {
@@ -65,11 +70,22 @@ func (m *Manager) RemoveDependency(instanceID, dependsOnID string) error {
 			}
 		}
 		inst.DependsOn = filtered
+		updated = *inst
 	})
+	if err != nil {
+		return err
+	}
+
+	removed := newEvent(state.EventDependencyRemoved, updated)
+	removed.Details = map[string]string{"depends_on": dependsOnID}
+	m.recordEvent(removed)
+
+	return nil
 }
 
 // CheckDependenciesMerged verifies that all dependencies of an instance have been merged.
-// Returns the list of unmerged dependency instance IDs if any exist.
+// Dependencies with an open pull request are refreshed from gh/glab first, since
+// pr-open only means the PR exists. Returns the list of unmerged dependency instance IDs if any exist.
 func (m *Manager) CheckDependenciesMerged(instanceID string) ([]string, error) {
 	st, err := m.store.Load()
 	if err != nil {
@@ -92,7 +108,13 @@ func (m *Manager) CheckDependenciesMerged(instanceID string) ([]string, error) {
 		if !exists {
 			continue
 		}
-		if dep.Status != "merged" && dep.Status != "done" {
+		status := dep.Status
+		if status == state.StatusPROpen {
+			if refreshed, err := m.RefreshPRStatus(depID); err == nil {
+				status = refreshed
+			}
+		}
+		if status != state.StatusMerged {
 			unmerged = append(unmerged, depID)
 		}
 	}
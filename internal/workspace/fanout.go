@@ -0,0 +1,305 @@
+package workspace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// MatrixAxis is one dimension of a fan-out matrix, e.g. model=opus,sonnet.
+// The agent, model and provider keys pick the agent profile and its model;
+// any other key is exported into the siblings' panes as an environment
+// variable.
+type MatrixAxis struct {
+	Key    string
+	Values []string
+}
+
+var matrixKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseMatrixAxis parses an axis written as key=value,value
+func ParseMatrixAxis(s string) (MatrixAxis, error) {
+	key, list, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return MatrixAxis{}, fmt.Errorf("invalid matrix %q (expected key=value,value, e.g. model=opus,sonnet)", s)
+	}
+	if !matrixKeyPattern.MatchString(key) {
+		return MatrixAxis{}, fmt.Errorf("invalid matrix key %q (use letters, digits and underscores)", key)
+	}
+
+	axis := MatrixAxis{Key: key}
+	seen := map[string]bool{}
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		axis.Values = append(axis.Values, value)
+	}
+	if len(axis.Values) == 0 {
+		return MatrixAxis{}, fmt.Errorf("matrix key %q has no values", key)
+	}
+	return axis, nil
+}
+
+// matrixCell is one combination of matrix values, in axis order
+type matrixCell [][2]string
+
+// String renders a cell as space-separated key=value pairs, e.g. "agent=aider model=opus"
+func (c matrixCell) String() string {
+	parts := make([]string, len(c))
+	for i, kv := range c {
+		parts[i] = kv[0] + "=" + kv[1]
+	}
+	return strings.Join(parts, " ")
+}
+
+// matrixCells returns every combination of the axes' values, the first axis
+// varying slowest. Without axes there is a single empty cell.
+func matrixCells(axes []MatrixAxis) []matrixCell {
+	cells := []matrixCell{{}}
+	for _, axis := range axes {
+		next := make([]matrixCell, 0, len(cells)*len(axis.Values))
+		for _, cell := range cells {
+			for _, value := range axis.Values {
+				combined := append(append(matrixCell{}, cell...), [2]string{axis.Key, value})
+				next = append(next, combined)
+			}
+		}
+		cells = next
+	}
+	return cells
+}
+
+// applyCell sets the values of a matrix cell on the options of a sibling
+func applyCell(opts CreateOpts, cell matrixCell) CreateOpts {
+	env := make(map[string]string, len(opts.Env)+len(cell))
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+	for _, kv := range cell {
+		switch kv[0] {
+		case "agent":
+			opts.Agent = kv[1]
+		case "model":
+			opts.Model = kv[1]
+		case "provider":
+			opts.Provider = kv[1]
+		default:
+			env[kv[0]] = kv[1]
+		}
+	}
+	if len(env) > 0 {
+		opts.Env = env
+	}
+	return opts
+}
+
+// FanOutOpts describes a fan-out: the same task run by several sibling
+// instances, one per matrix cell and repeat
+type FanOutOpts struct {
+	// CreateOpts are shared by all siblings. Branch is the stem of their
+	// branches, suffixed -1, -2, ...; fanout/<id> if empty.
+	CreateOpts
+	Matrix []MatrixAxis
+	Count  int // siblings per matrix cell, at least 1
+}
+
+// FanOut creates the sibling instances of a new fan-out and returns its ID
+// and the siblings. If one of them cannot be created, the ones created
+// before it are deleted again.
+func (m *Manager) FanOut(opts FanOutOpts) (string, []state.Instance, error) {
+	count := opts.Count
+	if count < 1 {
+		count = 1
+	}
+	seen := map[string]bool{}
+	for _, axis := range opts.Matrix {
+		if seen[axis.Key] {
+			return "", nil, fmt.Errorf("matrix key %q is given more than once", axis.Key)
+		}
+		seen[axis.Key] = true
+	}
+	cells := matrixCells(opts.Matrix)
+	if len(cells)*count < 2 {
+		return "", nil, fmt.Errorf("a fan-out needs at least two siblings\n\nTo fix:\n  1. Give a matrix with several values: --matrix model=opus,sonnet\n  2. Or run each cell more than once: --count 3")
+	}
+
+	id, err := state.GenerateID()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate fan-out ID: %w", err)
+	}
+	stem := opts.Branch
+	if stem == "" {
+		stem = "fanout/" + id
+	}
+
+	var siblings []state.Instance
+	n := 0
+	for _, cell := range cells {
+		for run := 1; run <= count; run++ {
+			n++
+			sibling := applyCell(opts.CreateOpts, cell)
+			sibling.Branch = fmt.Sprintf("%s-%d", stem, n)
+			if opts.Name != "" {
+				sibling.Name = fmt.Sprintf("%s-%d", opts.Name, n)
+			}
+			sibling.FanOut = id
+			sibling.Variant = cell.String()
+			if count > 1 {
+				sibling.Variant = strings.TrimSpace(fmt.Sprintf("%s #%d", sibling.Variant, run))
+			}
+
+			inst, err := m.CreateInstance(sibling)
+			if err != nil {
+				for _, created := range siblings {
+					_ = m.DeleteInstance(created.ID, DeleteOpts{Force: true, DeleteBranch: true})
+				}
+				return "", nil, fmt.Errorf("failed to create sibling %s (%s): %w", sibling.Branch, sibling.Variant, err)
+			}
+			siblings = append(siblings, *inst)
+		}
+	}
+	return id, siblings, nil
+}
+
+// FanOutSiblings returns the instances of a fan-out in the order they were created
+func (m *Manager) FanOutSiblings(id string) ([]state.Instance, error) {
+	instances, err := m.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	var siblings []state.Instance
+	for _, inst := range instances {
+		if inst.FanOut == id {
+			siblings = append(siblings, inst)
+		}
+	}
+	if len(siblings) == 0 {
+		return nil, fmt.Errorf("fan-out %q not found\n\nTo fix:\n  1. List instances with their fan-out: ocw list\n  2. A fan-out whose winner was picked no longer exists", id)
+	}
+	return siblings, nil
+}
+
+// TestResult is the outcome of running fanout.test_command in a sibling's worktree
+type TestResult struct {
+	Passed   bool
+	Output   string // last lines of the combined output
+	Error    string // why the command could not run to completion, e.g. a timeout
+	Duration time.Duration
+}
+
+// FanOutComparison is where a sibling stands: its changes against the base
+// branch, whether they merge cleanly and, if tested, whether its tests pass
+type FanOutComparison struct {
+	Instance  state.Instance
+	Diff      git.DiffStat // committed and uncommitted changes against the base branch
+	Conflicts []string     // files that conflict with the base branch
+	Test      *TestResult  // nil if not tested
+	Err       error        // set if the sibling could not be compared
+}
+
+// CompareFanOut compares the siblings of a fan-out. With test set, the
+// fanout.test_command runs in each sibling's worktree, one after another.
+func (m *Manager) CompareFanOut(id string, test bool) ([]FanOutComparison, error) {
+	siblings, err := m.FanOutSiblings(id)
+	if err != nil {
+		return nil, err
+	}
+	if test && m.config.FanOut.TestCommand == "" {
+		return nil, fmt.Errorf("no test command configured\n\nTo fix:\n  1. Set one: ocw config set fanout.test_command \"go test ./...\"")
+	}
+
+	comparisons := make([]FanOutComparison, len(siblings))
+	for i, inst := range siblings {
+		c := FanOutComparison{Instance: inst}
+		c.Diff, c.Err = git.NewGit(inst.WorktreePath).DiffStat(inst.BaseBranch)
+		if c.Err == nil {
+			_, c.Conflicts, c.Err = NewConflictDetector(m.git).CheckMergeConflicts(inst)
+		}
+		if test {
+			result := m.runTests(inst)
+			c.Test = &result
+		}
+		comparisons[i] = c
+	}
+	return comparisons, nil
+}
+
+// runTests runs fanout.test_command in an instance's worktree
+func (m *Manager) runTests(inst state.Instance) TestResult {
+	timeout := time.Duration(m.config.FanOut.TestTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	run := m.runHook("test", m.config.FanOut.TestCommand, inst, inst.WorktreePath, timeout)
+	return TestResult{
+		Passed:   !run.Failed(),
+		Output:   lastLines(run.Output, 20),
+		Error:    run.Error,
+		Duration: time.Duration(run.DurationMS) * time.Millisecond,
+	}
+}
+
+// PickWinner keeps one sibling of a fan-out and deletes the others, which
+// are archived with their branches and uncommitted changes so they can still
+// be restored. The winner leaves the fan-out. It returns the archived siblings.
+func (m *Manager) PickWinner(id string) ([]state.Instance, error) {
+	winner, err := m.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+	if winner.FanOut == "" {
+		return nil, fmt.Errorf("instance %s is not part of a fan-out", winner.Name)
+	}
+
+	siblings, err := m.FanOutSiblings(winner.FanOut)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []state.Instance
+	for _, sibling := range siblings {
+		if sibling.ID == winner.ID {
+			continue
+		}
+		// A sibling's unfinished work usually leaves its worktree dirty; it
+		// is stashed into the archive, so the worktree can go regardless
+		if err := m.DeleteInstance(sibling.ID, DeleteOpts{
+			DeleteBranch:    true,
+			StashChanges:    true,
+			DiscardArchived: true,
+		}); err != nil {
+			return archived, fmt.Errorf("failed to archive sibling %s: %w", sibling.Name, err)
+		}
+		archived = append(archived, sibling)
+	}
+
+	if err := m.store.UpdateInstance(winner.ID, func(inst *state.Instance) {
+		inst.FanOut = ""
+	}); err != nil {
+		return archived, fmt.Errorf("failed to update instance: %w", err)
+	}
+
+	ids := make([]string, len(archived))
+	for i, sibling := range archived {
+		ids[i] = sibling.ID
+	}
+	picked := newEvent(state.EventFanOutPicked, *winner)
+	picked.Reason = winner.Variant
+	picked.Details = map[string]string{
+		"fanout":   winner.FanOut,
+		"archived": strings.Join(ids, ", "),
+	}
+	m.recordEvent(picked)
+
+	return archived, nil
+}
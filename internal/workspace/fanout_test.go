@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
+)
+
+func TestParseMatrixAxis(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MatrixAxis
+		wantErr string
+	}{
+		{"values", "model=opus,sonnet", MatrixAxis{Key: "model", Values: []string{"opus", "sonnet"}}, ""},
+		{"spaces and duplicates", " agent = aider, opencode,,aider ", MatrixAxis{Key: "agent", Values: []string{"aider", "opencode"}}, ""},
+		{"env variable", "GOFLAGS=-race", MatrixAxis{Key: "GOFLAGS", Values: []string{"-race"}}, ""},
+		{"no equals sign", "model", MatrixAxis{}, "expected key=value,value"},
+		{"no key", "=opus", MatrixAxis{}, "expected key=value,value"},
+		{"invalid key", "my-model=opus", MatrixAxis{}, `invalid matrix key "my-model"`},
+		{"no values", "model= , ", MatrixAxis{}, `matrix key "model" has no values`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			axis, err := ParseMatrixAxis(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, axis)
+		})
+	}
+}
+
+func TestMatrixCells(t *testing.T) {
+	assert.Equal(t, []matrixCell{{}}, matrixCells(nil))
+
+	cells := matrixCells([]MatrixAxis{
+		{Key: "agent", Values: []string{"aider", "opencode"}},
+		{Key: "model", Values: []string{"opus", "sonnet"}},
+	})
+	names := make([]string, len(cells))
+	for i, cell := range cells {
+		names[i] = cell.String()
+	}
+	assert.Equal(t, []string{
+		"agent=aider model=opus",
+		"agent=aider model=sonnet",
+		"agent=opencode model=opus",
+		"agent=opencode model=sonnet",
+	}, names)
+}
+
+func TestApplyCell(t *testing.T) {
+	base := CreateOpts{Branch: "feature/login", Agent: "opencode", Env: map[string]string{"DEBUG": "1"}}
+
+	opts := applyCell(base, matrixCell{{"agent", "aider"}, {"model", "opus"}, {"provider", "anthropic"}, {"GOFLAGS", "-race"}})
+	assert.Equal(t, "aider", opts.Agent)
+	assert.Equal(t, "opus", opts.Model)
+	assert.Equal(t, "anthropic", opts.Provider)
+	assert.Equal(t, map[string]string{"DEBUG": "1", "GOFLAGS": "-race"}, opts.Env)
+
+	// The shared options are left alone
+	assert.Equal(t, "opencode", base.Agent)
+	assert.Equal(t, map[string]string{"DEBUG": "1"}, base.Env)
+
+	opts = applyCell(CreateOpts{}, matrixCell{})
+	assert.Nil(t, opts.Env)
+}
+
+func TestFanOutNeedsTwoSiblings(t *testing.T) {
+	m := &Manager{}
+
+	_, _, err := m.FanOut(FanOutOpts{Matrix: []MatrixAxis{{Key: "model", Values: []string{"opus"}}}, Count: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least two siblings")
+
+	_, _, err = m.FanOut(FanOutOpts{Matrix: []MatrixAxis{
+		{Key: "model", Values: []string{"opus"}},
+		{Key: "model", Values: []string{"sonnet"}},
+	}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `matrix key "model" is given more than once`)
+}
+
+func TestPickWinnerArchivesDirtySiblings(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+	m.tmux = tmux.NewTmux()
+	m.config.Workspace.StashOnDelete = false
+
+	// A private tmux server holding the siblings' windows
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	out, err := exec.Command("tmux", "new-session", "-d", "-s", "ocw-test").CombinedOutput()
+	require.NoError(t, err, string(out))
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-server").Run() })
+	window := func(name string) string {
+		id, err := m.tmux.NewWindow("ocw-test", name, repo)
+		require.NoError(t, err)
+		return id
+	}
+
+	loser := filepath.Join(repo, ".worktrees", "feature-2")
+	out, err = exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "feature-2", loser, "main").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	// The losing agent left a modified and an untracked file behind
+	require.NoError(t, os.WriteFile(filepath.Join(loser, "README.md"), []byte("changed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(loser, "wip.txt"), []byte("wip\n"), 0644))
+
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "abc123", Name: "feature-1", Branch: "feature", BaseBranch: "main", WorktreePath: worktree, TmuxWindow: window("feature-1"), FanOut: "fan1"}))
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "def456", Name: "feature-2", Branch: "feature-2", BaseBranch: "main", WorktreePath: loser, TmuxWindow: window("feature-2"), FanOut: "fan1"}))
+
+	archived, err := m.PickWinner("abc123")
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, "def456", archived[0].ID)
+
+	assert.NoDirExists(t, loser)
+	assert.False(t, m.git.BranchExists("feature-2"))
+	assert.True(t, m.git.RefExists("refs/ocw/archive-stash/def456"), "the uncommitted work is kept")
+
+	winner, err := m.GetInstance("abc123")
+	require.NoError(t, err)
+	assert.Empty(t, winner.FanOut)
+	_, err = m.GetInstance("def456")
+	assert.Error(t, err)
+}
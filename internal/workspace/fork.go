@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// forkStashRefPrefix keeps the uncommitted changes of a forked instance
+// reachable until they are applied in the fork's worktree
+const forkStashRefPrefix = "refs/ocw/fork-stash/"
+
+// ForkOpts contains options for forking an instance.
+type ForkOpts struct {
+	Branch string // Branch to create for the fork
+	Name   string // Display name; the branch if empty
+	Prompt string // Sent to the fork's agent once it is ready
+}
+
+// ForkInstance creates a new instance on a new branch from the current HEAD
+// of an existing one, with its staged, unstaged and untracked changes
+// carried over. The fork keeps the source's labels, task, template, env,
+// agent and sub-terminal layout, and records the source in ForkedFrom. The
+// source's worktree is left untouched.
+func (m *Manager) ForkInstance(id string, opts ForkOpts) (*state.Instance, error) {
+	src, err := m.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Branch == "" {
+		return nil, fmt.Errorf("branch name cannot be empty")
+	}
+	if m.git.BranchExists(opts.Branch) {
+		return nil, fmt.Errorf("branch %q already exists\n\nTo fix:\n  1. Fork onto a new branch name\n  2. Or create an instance on the existing branch: ocw new %s", opts.Branch, opts.Branch)
+	}
+
+	head, err := m.instanceHead(*src)
+	if err != nil {
+		return nil, err
+	}
+
+	forkID, err := state.GenerateID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate instance ID: %w", err)
+	}
+
+	stash, err := git.NewGit(src.WorktreePath).StashCreate("ocw fork " + src.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record uncommitted changes of %s: %w", src.Name, err)
+	}
+	stashRef := ""
+	if stash != "" {
+		stashRef = forkStashRefPrefix + forkID
+		if err := m.git.UpdateRef(stashRef, stash); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.git.CreateBranch(opts.Branch, head); err != nil {
+		if stashRef != "" {
+			_ = m.git.DeleteRef(stashRef)
+		}
+		return nil, err
+	}
+
+	fork, err := m.CreateInstance(CreateOpts{
+		ID:         forkID,
+		Name:       opts.Name,
+		Branch:     opts.Branch,
+		BaseBranch: src.BaseBranch,
+		Tags:       src.Tags,
+		Metadata:   src.Metadata,
+		Task:       src.Task,
+		Prompt:     opts.Prompt,
+		Template:   src.Template,
+		Agent:      src.Agent,
+		Env:        src.Env,
+		ForkedFrom: src.ID,
+		Stash:      stash,
+	})
+	if stashRef != "" {
+		_ = m.git.DeleteRef(stashRef)
+	}
+	if err != nil {
+		_ = m.git.BranchDelete(opts.Branch, true)
+		return nil, fmt.Errorf("failed to create fork: %w", err)
+	}
+
+	var layoutErrs []error
+	for _, sub := range src.SubTerminals {
+		if _, err := m.CreateSubTerminal(fork.ID, sub.Label); err != nil {
+			layoutErrs = append(layoutErrs, fmt.Errorf("failed to recreate sub-terminal %q: %w", sub.Label, err))
+		}
+	}
+	if len(src.SubTerminals) > 0 {
+		if inst, err := m.GetInstance(fork.ID); err == nil {
+			fork = inst
+		}
+	}
+	if len(layoutErrs) > 0 {
+		return fork, fmt.Errorf("instance forked with problems: %w", layoutErrs[0])
+	}
+
+	return fork, nil
+}
@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
+)
+
+func TestStashCreateCarriesOverChanges(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	runGit := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+
+	wt := git.NewGit(worktree)
+	sha, err := wt.StashCreate("ocw fork abc123")
+	require.NoError(t, err)
+	assert.Empty(t, sha, "a clean worktree has nothing to carry over")
+
+	// A staged file, an unstaged change on top of it, an untracked file in a
+	// new directory and a deleted file
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "staged.txt"), []byte("staged\n"), 0644))
+	runGit(worktree, "add", "staged.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "staged.txt"), []byte("staged\nunstaged\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(worktree, "notes"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "notes", "wip [draft].txt"), []byte("wip\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(worktree, "README.md")))
+	status := runGit(worktree, "status", "--porcelain", "--untracked-files=all")
+
+	sha, err = wt.StashCreate("ocw fork abc123")
+	require.NoError(t, err)
+	require.NotEmpty(t, sha)
+
+	// The source is left as it was and the stash list untouched
+	assert.Equal(t, status, runGit(worktree, "status", "--porcelain", "--untracked-files=all"))
+	assert.False(t, git.NewGit(repo).RefExists("refs/stash"))
+
+	// Applied in a new worktree at the same commit, the changes come back as
+	// staged, unstaged and untracked as they were
+	fork := filepath.Join(repo, ".worktrees", "fork")
+	runGit(repo, "worktree", "add", "-q", "-b", "fork", fork, "feature")
+	require.NoError(t, git.NewGit(fork).StashApplyIndex(sha))
+
+	assert.Equal(t, status, runGit(fork, "status", "--porcelain", "--untracked-files=all"))
+	data, err := os.ReadFile(filepath.Join(fork, "staged.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "staged\nunstaged\n", string(data))
+	data, err = os.ReadFile(filepath.Join(fork, "notes", "wip [draft].txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "wip\n", string(data))
+}
+
+func TestForkInstanceWithTemplateFiles(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-server").Run() })
+
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+	m.tmux = tmux.NewTmux()
+	m.config.Workspace.Agent = "shell"
+	m.config.Workspace.Templates["web"] = config.Template{Files: []string{".env.local"}}
+
+	// The template's file is untracked in the main checkout and in the
+	// source, where the agent changed it
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".env.local"), []byte("PORT=1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".env.local"), []byte("PORT=2\n"), 0644))
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "abc123", Name: "feature", Branch: "feature", BaseBranch: "main", WorktreePath: worktree, Template: "web", Status: state.StatusRunning}))
+
+	fork, err := m.ForkInstance("abc123", ForkOpts{Branch: "feature-2"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(fork.WorktreePath, ".env.local"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=2\n", string(data), "the source's version is carried over")
+	assert.Equal(t, "abc123", fork.ForkedFrom)
+	assert.Equal(t, "web", fork.Template)
+}
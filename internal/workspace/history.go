@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// History returns lifecycle events from the journal matching the filter.
+func (m *Manager) History(filter state.EventFilter) ([]state.Event, error) {
+	events, err := m.store.ReadEvents(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event journal: %w", err)
+	}
+	return events, nil
+}
+
+// newEvent builds a journal event describing the given instance.
+func newEvent(evType state.EventType, inst state.Instance) state.Event {
+	return state.Event{
+		Type:       evType,
+		InstanceID: inst.ID,
+		Instance:   inst.Name,
+		Branch:     inst.Branch,
+	}
+}
+
+// recordEvent appends an event to the journal.
+// The journal is diagnostic only, so a failed write never fails the operation being recorded.
+func (m *Manager) recordEvent(ev state.Event) {
+	_ = m.store.AppendEvent(ev)
+}
+
+// recordStatusChange records a status-changed event if the status actually changed.
+func (m *Manager) recordStatusChange(inst state.Instance, from, to state.Status, reason string) {
+	if from == to {
+		return
+	}
+	ev := newEvent(state.EventStatusChanged, inst)
+	ev.From = string(from)
+	ev.To = string(to)
+	ev.Reason = reason
+	m.recordEvent(ev)
+}
+
+// transition moves an instance to a new status through the state machine and
+// records the change in the journal. It returns the instance as it was before.
+func (m *Manager) transition(id string, to state.Status, reason string, fn func(*state.Instance)) (state.Instance, error) {
+	before, err := m.store.TransitionInstance(id, to, reason, fn)
+	if err != nil {
+		return before, err
+	}
+	m.recordStatusChange(before, before.Status, to, reason)
+	return before, nil
+}
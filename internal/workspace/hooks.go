@@ -0,0 +1,192 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// Lifecycle hooks, named as in the [hooks] config table
+const (
+	HookPreCreate  = "pre_create"
+	HookPostCreate = "post_create"
+	HookPreDelete  = "pre_delete"
+	HookPostDelete = "post_delete"
+	HookPrePause   = "pre_pause"
+	HookPostPause  = "post_pause"
+	HookPrePush    = "pre_push"
+	HookPostPush   = "post_push"
+	HookPreMerge   = "pre_merge"
+	HookPostMerge  = "post_merge"
+)
+
+// maxHookOutput bounds the output kept per hook run; the tail is kept
+const maxHookOutput = 64 * 1024
+
+// HookError is returned when a hook command fails. A failing pre_* hook
+// aborts the operation it guards.
+type HookError struct {
+	Run state.HookRun
+}
+
+func (e *HookError) Error() string {
+	reason := fmt.Sprintf("exited with code %d", e.Run.ExitCode)
+	if e.Run.Error != "" {
+		reason = e.Run.Error
+	}
+
+	msg := fmt.Sprintf("%s hook %q %s", e.Run.Hook, e.Run.Command, reason)
+	if output := lastLines(e.Run.Output, 10); output != "" {
+		msg += "\n\n" + output
+	}
+	return msg + fmt.Sprintf("\n\nTo fix:\n  1. Fix the command in [hooks] %s of the config: ocw config get hooks.%s\n  2. See the full output in the TUI log view (l, then h) or .ocw/hooks.jsonl", e.Run.Hook, e.Run.Hook)
+}
+
+// hookPayload is written as JSON to the stdin of every hook command
+type hookPayload struct {
+	Hook     string         `json:"hook"`
+	RepoRoot string         `json:"repo_root"`
+	Instance state.Instance `json:"instance"`
+}
+
+// runHooks runs the commands configured for a hook one after another and logs
+// each run to the hook log. It stops at the first failing command and returns
+// a *HookError for it. Callers abort on errors from pre_* hooks; post_* hooks
+// cannot undo the operation, so their failures are only logged and journaled.
+func (m *Manager) runHooks(hook string, inst state.Instance) error {
+	commands := m.config.Hooks.Commands(hook)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	// Hooks run in the worktree while it exists
+	dir := m.repoRoot
+	if inst.WorktreePath != "" {
+		if info, err := os.Stat(inst.WorktreePath); err == nil && info.IsDir() {
+			dir = inst.WorktreePath
+		}
+	}
+
+	timeout := time.Duration(m.config.Hooks.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	for _, command := range commands {
+		run := m.runHook(hook, command, inst, dir, timeout)
+		// The hook log is diagnostic only, like the journal
+		_ = m.store.AppendHookRun(run)
+
+		if run.Failed() {
+			failed := newEvent(state.EventHookFailed, inst)
+			failed.Reason = run.Error
+			failed.Details = map[string]string{
+				"hook":      hook,
+				"command":   command,
+				"exit_code": strconv.Itoa(run.ExitCode),
+			}
+			m.recordEvent(failed)
+			return &HookError{Run: run}
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook command with sh -c, passing the instance as
+// OCW_* environment variables and as JSON on stdin
+func (m *Manager) runHook(hook, command string, inst state.Instance, dir string, timeout time.Duration) state.HookRun {
+	run := state.HookRun{
+		Time:       time.Now(),
+		Hook:       hook,
+		InstanceID: inst.ID,
+		Instance:   inst.Name,
+		Command:    command,
+	}
+
+	payload, err := json.Marshal(hookPayload{Hook: hook, RepoRoot: m.repoRoot, Instance: inst})
+	if err != nil {
+		run.ExitCode = -1
+		run.Error = fmt.Sprintf("failed to encode instance: %v", err)
+		return run
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), hookEnv(hook, m.repoRoot, inst)...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Run in its own process group so a timeout also kills the command's children
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	run.DurationMS = time.Since(run.Time).Milliseconds()
+	run.Output = tail(output.String(), maxHookOutput)
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		run.ExitCode = -1
+		run.Error = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		run.ExitCode = -1
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// hookEnv describes an instance as OCW_* environment variables, followed by
+// the instance's own environment
+func hookEnv(hook, repoRoot string, inst state.Instance) []string {
+	env := []string{
+		"OCW_HOOK=" + hook,
+		"OCW_REPO_ROOT=" + repoRoot,
+		"OCW_INSTANCE_ID=" + inst.ID,
+		"OCW_INSTANCE_NAME=" + inst.Name,
+		"OCW_BRANCH=" + inst.Branch,
+		"OCW_BASE_BRANCH=" + inst.BaseBranch,
+		"OCW_WORKTREE=" + inst.WorktreePath,
+		"OCW_STATUS=" + string(inst.Status),
+		"OCW_TEMPLATE=" + inst.Template,
+		"OCW_PR_URL=" + inst.PRUrl,
+	}
+	env = append(env, portVars(inst)...)
+	return append(env, envPairs(inst.Env)...)
+}
+
+// tail returns at most the last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
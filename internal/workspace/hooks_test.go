@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func newHookManager(t *testing.T, hooks config.HooksConfig) *Manager {
+	t.Helper()
+	repo := t.TempDir()
+	cfg := config.DefaultConfig()
+	if hooks.TimeoutSeconds == 0 {
+		hooks.TimeoutSeconds = cfg.Hooks.TimeoutSeconds
+	}
+	cfg.Hooks = hooks
+	return &Manager{config: cfg, store: state.NewStore(repo), repoRoot: repo}
+}
+
+func TestRunHooks(t *testing.T) {
+	m := newHookManager(t, config.HooksConfig{
+		PostCreate: []string{
+			`echo "$OCW_HOOK $OCW_INSTANCE_ID $OCW_BRANCH $STAGE"`,
+			`grep -o '"branch":"[^"]*"'`,
+			`pwd`,
+		},
+	})
+	worktree := t.TempDir()
+	inst := state.Instance{ID: "abc123", Name: "login", Branch: "feature/login", WorktreePath: worktree, Env: map[string]string{"STAGE": "dev"}}
+
+	require.NoError(t, m.runHooks(HookPostCreate, inst))
+
+	runs, err := m.store.ReadHookRuns("abc123")
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "post_create abc123 feature/login dev\n", runs[0].Output)
+	assert.Equal(t, `"branch":"feature/login"`+"\n", runs[1].Output)
+	resolved, err := filepath.EvalSymlinks(worktree)
+	require.NoError(t, err)
+	assert.Contains(t, []string{worktree + "\n", resolved + "\n"}, runs[2].Output, "hooks run in the worktree")
+
+	// Hooks without commands do nothing
+	require.NoError(t, m.runHooks(HookPreDelete, inst))
+}
+
+func TestRunHooksFailure(t *testing.T) {
+	m := newHookManager(t, config.HooksConfig{
+		PreCreate: []string{"echo checking licenses; exit 3", "echo never runs"},
+	})
+	inst := state.Instance{ID: "abc123", WorktreePath: filepath.Join(os.TempDir(), "ocw-missing-worktree")}
+
+	err := m.runHooks(HookPreCreate, inst)
+	var hookErr *HookError
+	require.True(t, errors.As(err, &hookErr))
+	assert.Equal(t, 3, hookErr.Run.ExitCode)
+	assert.Contains(t, err.Error(), `pre_create hook "echo checking licenses; exit 3" exited with code 3`)
+	assert.Contains(t, err.Error(), "checking licenses")
+
+	runs, err := m.store.ReadHookRuns("abc123")
+	require.NoError(t, err)
+	assert.Len(t, runs, 1, "commands after a failing one are skipped")
+
+	events, err := m.store.ReadEvents(state.EventFilter{Types: []state.EventType{state.EventHookFailed}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "pre_create", events[0].Details["hook"])
+	assert.Equal(t, "3", events[0].Details["exit_code"])
+}
+
+func TestRunHooksTimeout(t *testing.T) {
+	m := newHookManager(t, config.HooksConfig{
+		TimeoutSeconds: 1,
+		PrePause:       []string{"sleep 30 & wait"},
+	})
+
+	err := m.runHooks(HookPrePause, state.Instance{ID: "abc123"})
+	var hookErr *HookError
+	require.True(t, errors.As(err, &hookErr))
+	assert.Equal(t, "timed out after 1s", hookErr.Run.Error)
+	assert.Less(t, hookErr.Run.DurationMS, int64(10000))
+}
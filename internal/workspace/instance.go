@@ -9,15 +9,44 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/git"
 	"github.com/tommyzliu/ocw/internal/state"
 )
 
 // CreateOpts contains options for creating a new instance.
 type CreateOpts struct {
-	Name        string // Display name for the instance
-	Branch      string // Branch name to create/use
-	BaseBranch  string // Base branch to branch from
-	InitCommand string // Command to run after creating worktree
+	ID          string            // Instance ID to use; generated if empty
+	Name        string            // Display name for the instance
+	Branch      string            // Branch name to create/use
+	BaseBranch  string            // Base branch to branch from
+	InitCommand string            // Command to run after creating worktree
+	Tags        []string          // Tags to attach to the instance
+	Metadata    map[string]string // Key/value metadata to attach to the instance
+	Task        string            // Description of the work given to the agent
+	Prompt      string            // Sent to the agent once it is ready; the template's prompt if empty
+	Notes       []state.Note      // Notes to carry over, e.g. when restoring
+	Template    string            // Template to apply to the options left empty
+	Agent       string            // Agent profile to run; the template's or workspace.agent if empty
+	Model       string            // Model instead of the agent profile's
+	Provider    string            // Provider instead of the agent profile's
+	FanOut      string            // Fan-out the instance is a sibling in
+	Variant     string            // Matrix cell of a fan-out sibling, e.g. "model=opus"
+	ForkedFrom  string            // Instance the new one is forked from; its template is not applied again
+	Restore     bool              // Recreates an archived instance; its template is not applied again
+	Stash       string            // Stash commit whose changes are applied to the new worktree
+	Env         map[string]string // Extra environment variables for the instance's panes
+	Vars        map[string]string // Values of the template's variables
+}
+
+// DeleteOpts contains options for deleting an instance.
+type DeleteOpts struct {
+	Force        bool // Delete even if the worktree is dirty or backing it up fails
+	DeleteBranch bool // Also delete the local branch
+	StashChanges bool // Stash uncommitted changes into the archive before removing the worktree
+	// Remove a dirty worktree once its changes are stashed into the archive;
+	// only applies with StashChanges
+	DiscardArchived bool
 }
 
 // InstanceStatus represents the current status of an instance.
@@ -32,29 +61,119 @@ type InstanceStatus struct {
 
 // CreateInstance creates a new OCW instance with a dedicated worktree and tmux window.
 // Steps:
-// 1. Validate branch name and check if branch exists
-// 2. Create git worktree at sanitized path
+// 1. Apply the template, validate branch name, reserve ports and run the pre_create hooks
+// 2. Create git worktree at sanitized path, bootstrap it, apply the stash, if any, and copy the template's files into it
 // 3. Create tmux window in the session
 // 4. Set remain-on-exit for the primary pane
-// 5. Launch opencode command and capture PID
-// 6. Register instance in state
+// 5. Register instance in state as "creating", in place of the placeholder holding its ports
+// 6. Launch the agent profile's command as the pane's process, wait for it to start and mark the instance "running"
+// 7. Open the template's sub-terminal layout and send the prompt once the agent is ready
+// 8. Run the post_create hooks
 func (m *Manager) CreateInstance(opts CreateOpts) (*state.Instance, error) {
+	var tmpl config.Template
+	if opts.Template != "" {
+		t, err := m.config.Template(opts.Template)
+		if err != nil && !opts.Restore {
+			return nil, err
+		}
+		// A restored instance whose template was removed since keeps its name
+		tmpl = t
+		if opts.ForkedFrom != "" || opts.Restore {
+			// A fork or restored instance takes its labels, env and layout
+			// from the instance it recreates; only the template's files and
+			// init command apply to the new worktree
+			tmpl.Layout = nil
+			if opts.InitCommand == "" {
+				opts.InitCommand = tmpl.InitCommand
+			}
+		} else if opts, err = applyTemplate(opts, tmpl); err != nil {
+			return nil, err
+		}
+	} else if len(opts.Vars) > 0 {
+		return nil, fmt.Errorf("variables can only be used with a template")
+	}
+
 	if opts.Branch == "" {
 		return nil, fmt.Errorf("branch name cannot be empty")
 	}
 
+	if opts.BaseBranch == "" {
+		opts.BaseBranch = m.config.Workspace.BaseBranch
+	}
+
 	if err := m.checkNestedWorktree(); err != nil {
 		return nil, err
 	}
 
-	id, err := state.GenerateID()
+	agentName, agent, err := m.agentProfile(opts.Agent, tmpl)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate instance ID: %w", err)
+		return nil, err
+	}
+	if opts.Model != "" {
+		agent.Model = opts.Model
+	}
+	if opts.Provider != "" {
+		agent.Provider = opts.Provider
+	}
+
+	if err := validateLabels(opts.Tags, opts.Metadata); err != nil {
+		return nil, err
+	}
+
+	id := opts.ID
+	if id == "" {
+		generated, err := state.GenerateID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate instance ID: %w", err)
+		}
+		id = generated
 	}
 
 	sanitizedBranch := sanitizeBranchName(opts.Branch)
 	worktreePath := filepath.Join(m.repoRoot, m.config.Workspace.WorktreeDir, sanitizedBranch)
 
+	windowName := opts.Name
+	if windowName == "" {
+		windowName = opts.Branch
+	}
+
+	// pre_create hooks see the instance as it is about to be created
+	pending := state.Instance{
+		ID:           id,
+		Name:         windowName,
+		Branch:       opts.Branch,
+		BaseBranch:   opts.BaseBranch,
+		WorktreePath: worktreePath,
+		Status:       state.StatusCreating,
+		Task:         strings.TrimSpace(opts.Task),
+		Metadata:     opts.Metadata,
+		Template:     opts.Template,
+		Env:          opts.Env,
+		Agent:        agentName,
+		FanOut:       opts.FanOut,
+		Variant:      opts.Variant,
+		ForkedFrom:   opts.ForkedFrom,
+	}
+	pending.AddTags(opts.Tags...)
+
+	ports, err := m.reservePorts(pending)
+	if err != nil {
+		return nil, err
+	}
+	pending.Ports = ports
+
+	// Until the instance is registered below, failing releases its ports
+	registered := false
+	defer func() {
+		if !registered {
+			m.releasePorts(id, ports)
+		}
+	}()
+
+	if err := m.runHooks(HookPreCreate, pending); err != nil {
+		return nil, err
+	}
+
 	sessionName, err := m.EnsureSession()
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure tmux session: %w", err)
@@ -68,29 +187,41 @@ func (m *Manager) CreateInstance(opts CreateOpts) (*state.Instance, error) {
 		}
 	} else {
 		baseBranch := opts.BaseBranch
-		if baseBranch == "" {
-			baseBranch = m.config.Workspace.BaseBranch
-		}
 
 		if err := m.git.WorktreeAdd(worktreePath, opts.Branch, baseBranch); err != nil {
 			return nil, fmt.Errorf("failed to create worktree with new branch %q from %q: %w\n\nTo fix:\n  1. Ensure base branch %q exists: git branch -a | grep %s\n  2. Fetch latest changes: git fetch\n  3. Check disk space: df -h", opts.Branch, baseBranch, err, baseBranch, baseBranch)
 		}
 	}
 
-	// Create tmux window for the instance
-	windowName := opts.Name
-	if windowName == "" {
-		windowName = opts.Branch
+	bootstrapped, err := m.bootstrap(worktreePath)
+	if err != nil {
+		_ = m.git.WorktreeRemove(worktreePath, true)
+		return nil, fmt.Errorf("failed to bootstrap worktree: %w\n\nTo fix:\n  1. Check workspace.bootstrap in the config: ocw config show", err)
 	}
 
-	windowID, err := m.tmux.NewWindow(sessionName, windowName, worktreePath)
+	if opts.Stash != "" {
+		if err := git.NewGit(worktreePath).StashApplyIndex(opts.Stash); err != nil {
+			_ = m.git.WorktreeRemove(worktreePath, true)
+			return nil, fmt.Errorf("failed to carry over uncommitted changes: %w", err)
+		}
+	}
+
+	// The stash already holds the source's version of any untracked file
+	// the template would copy
+	if _, err := copyTemplateFiles(m.repoRoot, worktreePath, tmpl.Files, opts.Stash != ""); err != nil {
+		_ = m.git.WorktreeRemove(worktreePath, true)
+		return nil, fmt.Errorf("failed to copy template files: %w", err)
+	}
+
+	// Create tmux window for the instance
+	windowID, err := m.tmux.NewWindow(sessionName, windowName, worktreePath, instanceEnv(pending)...)
 	if err != nil {
 		// Cleanup worktree on failure
 		_ = m.git.WorktreeRemove(worktreePath, true)
 		return nil, fmt.Errorf("failed to create tmux window: %w", err)
 	}
 
-	// Set remain-on-exit for the window so we can detect when opencode exits
+	// Set remain-on-exit for the window so we can detect when the agent exits
 	if err := m.tmux.SetRemainOnExit(windowID, true); err != nil {
 		// Cleanup on failure
 		_ = m.tmux.KillWindow(windowID)
@@ -108,77 +239,162 @@ func (m *Manager) CreateInstance(opts CreateOpts) (*state.Instance, error) {
 	}
 	primaryPaneID := panes[0].ID
 
-	// Build opencode command
-	opencodeCmd := m.buildOpencodeCommand()
+	// Register the instance while the agent is launched so it is visible and
+	// reconcilable if launching fails halfway
+	now := time.Now()
+	instance := state.Instance{
+		ID:              id,
+		Name:            windowName,
+		Branch:          opts.Branch,
+		BaseBranch:      opts.BaseBranch,
+		WorktreePath:    worktreePath,
+		TmuxWindow:      windowID,
+		PrimaryPane:     primaryPaneID,
+		SubTerminals:    []state.SubTerminal{},
+		Status:          state.StatusCreating,
+		StatusChangedAt: now,
+		CreatedAt:       now,
+		LastActivity:    now,
+		ConflictsWith:   []string{},
+		DependsOn:       []string{},
+		Task:            strings.TrimSpace(opts.Task),
+		Notes:           opts.Notes,
+		Template:        opts.Template,
+		Env:             opts.Env,
+		Agent:           agentName,
+		Ports:           ports,
+		FanOut:          opts.FanOut,
+		Variant:         opts.Variant,
+		ForkedFrom:      opts.ForkedFrom,
+	}
+	instance.AddTags(opts.Tags...)
+	if len(opts.Metadata) > 0 {
+		instance.Metadata = make(map[string]string, len(opts.Metadata))
+		for key, value := range opts.Metadata {
+			instance.Metadata[key] = value
+		}
+	}
 
-	// Launch opencode in the window
-	if err := m.tmux.SendKeys(windowID, opencodeCmd); err != nil {
+	// Replaces the placeholder holding the instance's ports
+	if err := m.store.PutInstance(instance); err != nil {
 		// Cleanup on failure
 		_ = m.tmux.KillWindow(windowID)
 		_ = m.git.WorktreeRemove(worktreePath, true)
-		return nil, fmt.Errorf("failed to launch opencode: %w", err)
+		return nil, fmt.Errorf("failed to save instance to state: %w", err)
 	}
+	registered = true
 
-	// Wait a moment for the process to start, then capture PID
-	time.Sleep(100 * time.Millisecond)
-	panes, err = m.tmux.ListPanes(windowID)
-	if err != nil || len(panes) == 0 {
-		// Cleanup on failure
+	created := newEvent(state.EventCreated, instance)
+	created.To = string(instance.Status)
+	created.Details = map[string]string{
+		"base_branch": instance.BaseBranch,
+		"worktree":    instance.WorktreePath,
+	}
+	if labels := instance.Labels(); labels != "" {
+		created.Details["labels"] = labels
+	}
+	if summary := TaskSummary(instance.Task); summary != "" {
+		created.Details["task"] = summary
+	}
+	if instance.Template != "" {
+		created.Details["template"] = instance.Template
+	}
+	created.Details["agent"] = instance.Agent
+	if len(instance.Ports) > 0 {
+		created.Details["ports"] = instance.PortList()
+	}
+	if instance.FanOut != "" {
+		created.Details["fanout"] = instance.FanOut
+		created.Details["variant"] = instance.Variant
+	}
+	if instance.ForkedFrom != "" {
+		created.Details["forked_from"] = instance.ForkedFrom
+	}
+	m.recordEvent(created)
+	m.recordBootstrap(instance, bootstrapped)
+
+	// cleanup undoes everything created so far
+	cleanup := func() {
 		_ = m.tmux.KillWindow(windowID)
 		_ = m.git.WorktreeRemove(worktreePath, true)
-		return nil, fmt.Errorf("failed to capture PID: %w", err)
+		_ = m.store.RemoveInstance(id)
 	}
-	pid := panes[0].PID
 
-	// Run init command if specified
-	if opts.InitCommand != "" {
+	// Launch the agent as the primary pane's process; a profile without a
+	// command leaves the shell, which then runs the init command
+	pid := panes[0].PID
+	if command := agentCommand(agent, instance); command != "" {
+		if pid, err = m.launchAgent(instance, agent, command, opts.InitCommand); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to launch agent %s: %w", agentName, err)
+		}
+	} else if opts.InitCommand != "" {
 		if err := m.tmux.SendKeys(windowID, opts.InitCommand); err != nil {
-			// Cleanup on failure
-			_ = m.tmux.KillWindow(windowID)
-			_ = m.git.WorktreeRemove(worktreePath, true)
+			cleanup()
 			return nil, fmt.Errorf("failed to run init command: %w", err)
 		}
 	}
 
-	// Create instance record
-	now := time.Now()
-	instance := state.Instance{
-		ID:            id,
-		Name:          windowName,
-		Branch:        opts.Branch,
-		BaseBranch:    opts.BaseBranch,
-		WorktreePath:  worktreePath,
-		TmuxWindow:    windowID,
-		PrimaryPane:   primaryPaneID,
-		SubTerminals:  []state.SubTerminal{},
-		PID:           pid,
-		Status:        "running",
-		CreatedAt:     now,
-		LastActivity:  now,
-		ConflictsWith: []string{},
-		DependsOn:     []string{},
-	}
-
-	// Save to state
-	if err := m.store.AddInstance(instance); err != nil {
-		// Cleanup on failure
-		_ = m.tmux.KillWindow(windowID)
-		_ = m.git.WorktreeRemove(worktreePath, true)
-		return nil, fmt.Errorf("failed to save instance to state: %w", err)
+	reason := agentName + " started"
+	if _, err := m.transition(id, state.StatusRunning, reason, func(inst *state.Instance) {
+		inst.PID = pid
+	}); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to update instance status: %w", err)
+	}
+
+	instance.PID = pid
+	instance.Status = state.StatusRunning
+	instance.StatusReason = reason
+
+	for _, pane := range tmpl.Layout {
+		paneID, err := m.CreateSubTerminal(id, pane.Label)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to open template layout: %w", err)
+		}
+		if pane.Command != "" {
+			if err := m.tmux.SendKeys(paneID, pane.Command); err != nil {
+				cleanup()
+				return nil, fmt.Errorf("failed to run layout command %q: %w", pane.Command, err)
+			}
+		}
 	}
 
+	if opts.Prompt != "" {
+		if err := m.deliverPrompt(instance, agent, opts.Prompt); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to send prompt: %w", err)
+		}
+		instance.Prompt = opts.Prompt
+	}
+
+	if len(tmpl.Layout) > 0 {
+		if inst, err := m.GetInstance(id); err == nil {
+			instance.SubTerminals = inst.SubTerminals
+		}
+	}
+
+	// The instance is usable even if a post_create hook fails; failures are
+	// in the hook log and the journal
+	_ = m.runHooks(HookPostCreate, instance)
+
 	return &instance, nil
 }
 
 // DeleteInstance removes an instance and cleans up all associated resources.
+// The instance record is moved to the archive and its branch tip is kept under
+// refs/ocw/archive/<id>, so it can be brought back with RestoreInstance.
 // Steps:
-// 1. Load instance from state
-// 2. Kill sub-terminals
-// 3. Kill tmux window
-// 4. Remove worktree
-// 5. Optionally delete branch
-// 6. Remove from state
-func (m *Manager) DeleteInstance(id string, force bool, deleteBranch bool) error {
+// 1. Load instance from state and run the pre_delete hooks
+// 2. Back up the branch tip (and optionally stash uncommitted changes)
+// 3. Kill sub-terminals
+// 4. Kill tmux window
+// 5. Remove worktree
+// 6. Optionally delete branch
+// 7. Move the record from instances to the archive
+// 8. Run the post_delete hooks
+func (m *Manager) DeleteInstance(id string, opts DeleteOpts) error {
 	// Load state
 	st, err := m.store.Load()
 	if err != nil {
@@ -198,35 +414,81 @@ func (m *Manager) DeleteInstance(id string, force bool, deleteBranch bool) error
 		return fmt.Errorf("instance %q not found", id)
 	}
 
+	if err := m.runHooks(HookPreDelete, *instance); err != nil {
+		return err
+	}
+
+	// Back up the work before anything is destroyed
+	archived, err := m.archiveRefs(*instance, opts.StashChanges)
+	forceRemove := opts.Force || (err == nil && opts.StashChanges && opts.DiscardArchived)
+	if err != nil && !opts.Force {
+		return fmt.Errorf("failed to archive instance: %w\n\nTo fix:\n  1. Commit or discard changes in %s\n  2. Or delete without a backup: ocw delete --force %s", err, instance.WorktreePath, id)
+	}
+
 	// Kill sub-terminals first
 	for _, subTerm := range instance.SubTerminals {
 		_ = m.tmux.KillPane(subTerm.PaneID)
 	}
 
 	// Kill the tmux window
-	if err := m.tmux.KillWindow(instance.TmuxWindow); err != nil && !force {
+	if err := m.tmux.KillWindow(instance.TmuxWindow); err != nil && !opts.Force {
 		return fmt.Errorf("failed to kill tmux window: %w", err)
 	}
 
 	// Remove the worktree
-	if err := m.git.WorktreeRemove(instance.WorktreePath, force); err != nil {
-		if !force {
+	if err := m.git.WorktreeRemove(instance.WorktreePath, forceRemove); err != nil {
+		if !opts.Force {
 			return fmt.Errorf("failed to remove worktree: %w", err)
 		}
 	}
 
 	// Optionally delete the branch (only if requested and not the base branch)
-	if deleteBranch && instance.Branch != instance.BaseBranch && instance.Branch != "" {
+	if opts.DeleteBranch && instance.Branch != instance.BaseBranch && instance.Branch != "" {
 		if err := m.git.BranchDelete(instance.Branch, true); err != nil {
 			return fmt.Errorf("failed to delete branch: %w", err)
 		}
+		archived.BranchDeleted = true
 	}
 
-	// Remove from state
-	if err := m.store.RemoveInstance(id); err != nil {
+	// Move from instances to the archive
+	if err := m.store.Transact(func(st *state.State) error {
+		filtered := make([]state.Instance, 0, len(st.Instances))
+		for _, inst := range st.Instances {
+			if inst.ID != id {
+				filtered = append(filtered, inst)
+			}
+		}
+		st.Instances = filtered
+
+		if archived.HeadRef != "" {
+			// The ports are freed; a restored instance is given new ones
+			archived.Instance.Ports = nil
+			st.Archive = append(st.Archive, archived)
+		}
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to remove instance from state: %w", err)
 	}
 
+	deleted := newEvent(state.EventDeleted, *instance)
+	deleted.From = string(instance.Status)
+	deleted.Details = map[string]string{}
+	if archived.HeadRef != "" {
+		deleted.Details["archive_ref"] = archived.HeadRef
+	}
+	if archived.StashRef != "" {
+		deleted.Details["stash_ref"] = archived.StashRef
+	}
+	if opts.DeleteBranch {
+		deleted.Details["branch_deleted"] = "true"
+	}
+	if len(instance.Ports) > 0 {
+		deleted.Details["ports_freed"] = instance.PortList()
+	}
+	m.recordEvent(deleted)
+
+	_ = m.runHooks(HookPostDelete, *instance)
+
 	return nil
 }
 
@@ -251,6 +513,14 @@ func (m *Manager) PauseInstance(id string) error {
 		return fmt.Errorf("instance %q not found", id)
 	}
 
+	if instance.Status == state.StatusPaused || !state.CanTransition(instance.Status, state.StatusPaused) {
+		return fmt.Errorf("cannot pause instance %q: status is %s", id, instance.Status)
+	}
+
+	if err := m.runHooks(HookPrePause, *instance); err != nil {
+		return err
+	}
+
 	// Check if process is alive
 	if !isProcessAlive(instance.PID) {
 		return fmt.Errorf("process with PID %d is not running", instance.PID)
@@ -262,12 +532,21 @@ func (m *Manager) PauseInstance(id string) error {
 	}
 
 	// Update status
-	if err := m.store.UpdateInstance(id, func(inst *state.Instance) {
-		inst.Status = "paused"
-	}); err != nil {
+	before, err := m.store.TransitionInstance(id, state.StatusPaused, "paused by user", nil)
+	if err != nil {
+		// Status changed underneath us; don't leave the process stopped
+		_ = syscall.Kill(instance.PID, syscall.SIGCONT)
 		return fmt.Errorf("failed to update instance status: %w", err)
 	}
 
+	paused := newEvent(state.EventPaused, before)
+	paused.From = string(before.Status)
+	paused.To = string(state.StatusPaused)
+	m.recordEvent(paused)
+
+	before.Status = state.StatusPaused
+	_ = m.runHooks(HookPostPause, before)
+
 	return nil
 }
 
@@ -292,19 +571,27 @@ func (m *Manager) ResumeInstance(id string) error {
 		return fmt.Errorf("instance %q not found", id)
 	}
 
+	if instance.Status != state.StatusPaused {
+		return fmt.Errorf("cannot resume instance %q: status is %s, not paused", id, instance.Status)
+	}
+
 	// Send SIGCONT
 	if err := syscall.Kill(instance.PID, syscall.SIGCONT); err != nil {
 		return fmt.Errorf("failed to resume process: %w", err)
 	}
 
 	// Update status
-	if err := m.store.UpdateInstance(id, func(inst *state.Instance) {
-		inst.Status = "running"
+	if _, err := m.store.TransitionInstance(id, state.StatusRunning, "resumed by user", func(inst *state.Instance) {
 		inst.LastActivity = time.Now()
 	}); err != nil {
 		return fmt.Errorf("failed to update instance status: %w", err)
 	}
 
+	resumed := newEvent(state.EventResumed, *instance)
+	resumed.From = string(instance.Status)
+	resumed.To = string(state.StatusRunning)
+	m.recordEvent(resumed)
+
 	return nil
 }
 
@@ -344,8 +631,8 @@ func (m *Manager) GetInstanceStatus(id string) (*InstanceStatus, error) {
 		PIDAlive:  pidAlive,
 		PaneDead:  paneDead,
 		IsRunning: isRunning,
-		CanResume: instance.Status == "paused" && pidAlive,
-		CanPause:  instance.Status == "running" && pidAlive,
+		CanResume: instance.Status == state.StatusPaused && pidAlive,
+		CanPause:  instance.Status != state.StatusPaused && state.CanTransition(instance.Status, state.StatusPaused) && pidAlive,
 	}
 
 	return status, nil
@@ -377,36 +664,159 @@ func (m *Manager) GetInstance(id string) (*state.Instance, error) {
 	return nil, fmt.Errorf("instance %q not found", id)
 }
 
-// RenameInstance updates the display name of an instance.
-func (m *Manager) RenameInstance(id, newName string) error {
-	if newName == "" {
-		return fmt.Errorf("new name cannot be empty")
-	}
+// RenameOpts contains options for renaming an instance.
+type RenameOpts struct {
+	Name   string // New display name; kept if empty, unless the name was the branch, which it then follows
+	Branch string // New branch name; kept if empty
+}
 
-	st, err := m.store.Load()
+// RenameInstance renames an instance and its tmux window. With a new branch,
+// the git branch is renamed, the worktree moved to the path derived from it,
+// instances based on the branch are based on the new name, and the head
+// branch of an open pull request is renamed on GitHub. If a step fails, the
+// steps before it are undone. If the renamed branch cannot be set to track
+// the renamed remote branch, the instance is returned with the error.
+//
+// Programs already running in the instance's panes keep the OCW_BRANCH and
+// OCW_WORKTREE they were started with; panes opened later get the new ones.
+func (m *Manager) RenameInstance(id string, opts RenameOpts) (*state.Instance, error) {
+	inst, err := m.GetInstance(id)
 	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
+		return nil, err
 	}
 
-	var instance *state.Instance
-	for i := range st.Instances {
-		if st.Instances[i].ID == id {
-			instance = &st.Instances[i]
-			break
+	name, branch, worktreePath := opts.Name, inst.Branch, inst.WorktreePath
+	if opts.Branch != "" && opts.Branch != inst.Branch {
+		if err := m.git.CheckBranchName(opts.Branch); err != nil {
+			return nil, err
+		}
+		if m.git.BranchExists(opts.Branch) {
+			return nil, fmt.Errorf("branch %q already exists\n\nTo fix:\n  1. Pick another branch name\n  2. Or delete the existing branch: git branch -D %s", opts.Branch, opts.Branch)
+		}
+		branch = opts.Branch
+		worktreePath = filepath.Join(filepath.Dir(inst.WorktreePath), sanitizeBranchName(branch))
+		if worktreePath != inst.WorktreePath {
+			if _, err := os.Stat(worktreePath); err == nil {
+				return nil, fmt.Errorf("worktree path %s already exists\n\nTo fix:\n  1. Remove stale worktrees: git worktree prune\n  2. Or pick another branch name", worktreePath)
+			}
+		}
+		if name == "" && inst.Name == inst.Branch {
+			name = branch
 		}
 	}
+	if name == "" {
+		name = inst.Name
+	}
+	if name == inst.Name && branch == inst.Branch {
+		return nil, fmt.Errorf("nothing to rename: give a new name or --branch")
+	}
 
-	if instance == nil {
-		return fmt.Errorf("instance %q not found", id)
+	// GitHub moves an open pull request along when its head branch is
+	// renamed; GitLab cannot change a merge request's source branch
+	renamePR := branch != inst.Branch && inst.Status == state.StatusPROpen && inst.PRUrl != ""
+	if renamePR {
+		tool, err := m.DetectPRTool()
+		if err != nil {
+			return nil, err
+		}
+		if tool != "gh" {
+			return nil, fmt.Errorf("the source branch of merge request %s cannot be renamed\n\nTo fix:\n  1. Rename only the instance: ocw rename %s <name>\n  2. Or close the merge request, rename the branch and open a new one with ocw merge", inst.PRUrl, inst.ID)
+		}
 	}
 
-	instance.Name = newName
+	// undo holds the inverse of each step taken, run in reverse on failure
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	if branch != inst.Branch {
+		if err := m.git.RenameBranch(inst.Branch, branch); err != nil {
+			return nil, err
+		}
+		undo = append(undo, func() { _ = m.git.RenameBranch(branch, inst.Branch) })
 
-	if err := m.store.Save(st); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
+		if worktreePath != inst.WorktreePath {
+			if err := m.git.WorktreeMove(inst.WorktreePath, worktreePath); err != nil {
+				rollback()
+				return nil, fmt.Errorf("%w\n\nTo fix:\n  1. Check that nothing holds %s open and the worktree is not locked: git worktree list\n  2. Worktrees with submodules cannot be moved; rename only the instance: ocw rename %s <name>", err, inst.WorktreePath, inst.ID)
+			}
+			undo = append(undo, func() { _ = m.git.WorktreeMove(worktreePath, inst.WorktreePath) })
+		}
 	}
 
-	return nil
+	// A window that is gone has nothing to rename
+	if name != inst.Name {
+		if _, err := m.tmux.ListPanes(inst.TmuxWindow); err == nil {
+			if err := m.tmux.RenameWindow(inst.TmuxWindow, name); err != nil {
+				rollback()
+				return nil, err
+			}
+			undo = append(undo, func() { _ = m.tmux.RenameWindow(inst.TmuxWindow, inst.Name) })
+		}
+	}
+
+	if renamePR {
+		if err := m.renameRemoteBranch(inst.Branch, branch); err != nil {
+			rollback()
+			return nil, err
+		}
+		undo = append(undo, func() { _ = m.renameRemoteBranch(branch, inst.Branch) })
+	}
+
+	var renamed state.Instance
+	var dependents []string
+	err = m.store.Transact(func(st *state.State) error {
+		dependents = nil
+		found := false
+		for i := range st.Instances {
+			switch {
+			case st.Instances[i].ID == id:
+				st.Instances[i].Name = name
+				st.Instances[i].Branch = branch
+				st.Instances[i].WorktreePath = worktreePath
+				renamed = st.Instances[i]
+				found = true
+			case branch != inst.Branch && st.Instances[i].BaseBranch == inst.Branch:
+				st.Instances[i].BaseBranch = branch
+				dependents = append(dependents, st.Instances[i].ID)
+			}
+		}
+		if !found {
+			return fmt.Errorf("instance %q not found", id)
+		}
+		return nil
+	})
+	if err != nil {
+		rollback()
+		return nil, fmt.Errorf("failed to update instance: %w", err)
+	}
+
+	ev := newEvent(state.EventRenamed, renamed)
+	ev.From = inst.Name
+	ev.To = name
+	if branch != inst.Branch {
+		ev.Details = map[string]string{
+			"old_branch": inst.Branch,
+			"branch":     branch,
+			"worktree":   worktreePath,
+		}
+		if len(dependents) > 0 {
+			ev.Details["dependents"] = strings.Join(dependents, ", ")
+		}
+	}
+	m.recordEvent(ev)
+
+	// The local branch still tracks the old name, which is gone now
+	if renamePR {
+		if err := m.git.SetUpstream(branch, "origin"); err != nil {
+			return &renamed, fmt.Errorf("instance renamed, but %s still tracks the deleted origin/%s: %w\n\nTo fix:\n  1. Track the renamed branch: git branch --set-upstream-to=origin/%s %s", branch, inst.Branch, err, branch, branch)
+		}
+	}
+
+	return &renamed, nil
 }
 
 // sanitizeBranchName converts a branch name to a safe filesystem path.
@@ -443,21 +853,6 @@ func isProcessAlive(pid int) bool {
 	return err == nil
 }
 
-// buildOpencodeCommand constructs the command to launch opencode.
-func (m *Manager) buildOpencodeCommand() string {
-	parts := []string{m.config.OpenCode.Command}
-	parts = append(parts, m.config.OpenCode.Args...)
-
-	if m.config.OpenCode.Model != "" {
-		parts = append(parts, "--model", m.config.OpenCode.Model)
-	}
-	if m.config.OpenCode.Provider != "" {
-		parts = append(parts, "--provider", m.config.OpenCode.Provider)
-	}
-
-	return strings.Join(parts, " ")
-}
-
 // checkNestedWorktree checks if the current directory is inside a git worktree.
 // This prevents creating OCW instances inside worktrees, which would cause issues.
 func (m *Manager) checkNestedWorktree() error {
@@ -0,0 +1,69 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestRenameInstanceBranch(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "abc123", Name: "login", Branch: "feature", BaseBranch: "main", WorktreePath: worktree}))
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "def456", Name: "stacked", Branch: "stacked", BaseBranch: "feature"}))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "wip.txt"), []byte("wip\n"), 0644))
+
+	renamed, err := m.RenameInstance("abc123", RenameOpts{Branch: "feature/jwt"})
+	require.NoError(t, err)
+
+	moved := filepath.Join(repo, ".worktrees", "feature-jwt")
+	assert.Equal(t, "login", renamed.Name, "a name other than the branch is kept")
+	assert.Equal(t, "feature/jwt", renamed.Branch)
+	assert.Equal(t, moved, renamed.WorktreePath)
+
+	assert.True(t, m.git.BranchExists("feature/jwt"))
+	assert.False(t, m.git.BranchExists("feature"))
+	data, err := os.ReadFile(filepath.Join(moved, "wip.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "wip\n", string(data))
+
+	stacked, err := m.GetInstance("def456")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/jwt", stacked.BaseBranch)
+
+	_, err = m.RenameInstance("abc123", RenameOpts{Branch: "main"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `branch "main" already exists`)
+
+	_, err = m.RenameInstance("abc123", RenameOpts{Name: "login"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nothing to rename")
+}
+
+func TestRenameInstanceRollsBack(t *testing.T) {
+	repo, worktree := newTestRepo(t)
+	m := newTestManager(repo)
+
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "abc123", Name: "login", Branch: "feature", BaseBranch: "main", WorktreePath: worktree}))
+
+	// A locked worktree cannot be moved, after the branch was renamed
+	out, err := exec.Command("git", "-C", repo, "worktree", "lock", worktree).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	_, err = m.RenameInstance("abc123", RenameOpts{Branch: "feature/jwt"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to move worktree")
+
+	assert.True(t, m.git.BranchExists("feature"))
+	assert.False(t, m.git.BranchExists("feature/jwt"))
+	inst, err := m.GetInstance("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "feature", inst.Branch)
+	assert.Equal(t, worktree, inst.WorktreePath)
+}
@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// LabelChange describes edits to the tags and metadata of an instance.
+type LabelChange struct {
+	AddTags       []string
+	RemoveTags    []string
+	SetMetadata   map[string]string
+	UnsetMetadata []string
+}
+
+// Empty reports whether the change edits nothing.
+func (c LabelChange) Empty() bool {
+	return len(c.AddTags) == 0 && len(c.RemoveTags) == 0 &&
+		len(c.SetMetadata) == 0 && len(c.UnsetMetadata) == 0
+}
+
+// validateLabels checks tags and metadata keys before they are stored.
+func validateLabels(tags []string, metadata map[string]string) error {
+	for _, tag := range tags {
+		if err := state.ValidateTag(tag); err != nil {
+			return err
+		}
+	}
+	for key := range metadata {
+		if err := state.ValidateMetadataKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateLabels applies a LabelChange to an instance and returns the updated instance.
+// Removals are applied before additions, so a tag or key present in both ends up set.
+func (m *Manager) UpdateLabels(id string, change LabelChange) (*state.Instance, error) {
+	if err := validateLabels(change.AddTags, change.SetMetadata); err != nil {
+		return nil, err
+	}
+
+	var instance state.Instance
+	var before string
+	err := m.store.Transact(func(st *state.State) error {
+		for i := range st.Instances {
+			if st.Instances[i].ID != id {
+				continue
+			}
+
+			inst := &st.Instances[i]
+			before = inst.Labels()
+
+			inst.RemoveTags(change.RemoveTags...)
+			inst.AddTags(change.AddTags...)
+
+			for _, key := range change.UnsetMetadata {
+				delete(inst.Metadata, key)
+			}
+			for key, value := range change.SetMetadata {
+				if inst.Metadata == nil {
+					inst.Metadata = make(map[string]string)
+				}
+				inst.Metadata[key] = value
+			}
+			if len(inst.Metadata) == 0 {
+				inst.Metadata = nil
+			}
+
+			instance = *inst
+			return nil
+		}
+		return fmt.Errorf("instance %q not found", id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if after := instance.Labels(); after != before {
+		changed := newEvent(state.EventLabelsChanged, instance)
+		changed.From = before
+		changed.To = after
+		m.recordEvent(changed)
+	}
+
+	return &instance, nil
+}
@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestUpdateLabels(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{store: state.NewStore(dir), config: config.DefaultConfig(), repoRoot: dir}
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "inst1", Name: "feature", Tags: []string{"wip"}}))
+
+	inst, err := m.UpdateLabels("inst1", LabelChange{
+		AddTags:     []string{"urgent"},
+		SetMetadata: map[string]string{"team": "infra", "ticket": "OCW-12"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "wip"}, inst.Tags)
+	assert.Equal(t, "infra", inst.Metadata["team"])
+
+	inst, err = m.UpdateLabels("inst1", LabelChange{
+		RemoveTags:    []string{"wip", "urgent"},
+		UnsetMetadata: []string{"team", "ticket"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, inst.Tags)
+	assert.Nil(t, inst.Metadata)
+
+	_, err = m.UpdateLabels("inst1", LabelChange{AddTags: []string{"has space"}})
+	assert.Error(t, err)
+
+	_, err = m.UpdateLabels("missing", LabelChange{AddTags: []string{"urgent"}})
+	assert.Error(t, err)
+
+	events, err := m.History(state.EventFilter{Types: []state.EventType{state.EventLabelsChanged}})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(events))
+	assert.Equal(t, "wip", events[0].From)
+	assert.Equal(t, "urgent,wip,team=infra,ticket=OCW-12", events[0].To)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/tommyzliu/ocw/internal/config"
 	"github.com/tommyzliu/ocw/internal/git"
+	"github.com/tommyzliu/ocw/internal/registry"
 	"github.com/tommyzliu/ocw/internal/state"
 	"github.com/tommyzliu/ocw/internal/tmux"
 )
@@ -17,6 +18,8 @@ type Manager struct {
 	store    *state.Store
 	config   *config.Config
 	repoRoot string
+	// registry is the user-level list of ocw repositories; nil disables registration
+	registry *registry.Registry
 }
 
 // NewManager creates a new workspace manager.
@@ -45,12 +48,16 @@ func NewManager(repoRoot string, cfg *config.Config) (*Manager, error) {
 	// Initialize state store
 	stateStore := state.NewStore(repoRoot)
 
+	// The registry is best-effort: without a home directory repos are simply not registered
+	reg, _ := registry.Default()
+
 	return &Manager{
 		git:      gitClient,
 		tmux:     tmuxClient,
 		store:    stateStore,
 		config:   cfg,
 		repoRoot: repoRoot,
+		registry: reg,
 	}, nil
 }
 
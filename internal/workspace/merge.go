@@ -1,7 +1,9 @@
 package workspace
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
 
@@ -68,10 +70,16 @@ func (m *Manager) PushBranch(instanceID string) error {
 		return fmt.Errorf("remote 'origin' not found\n\nAvailable remotes: %s\n\nTo fix:\n  1. Add origin remote: git remote add origin <repository-url>\n  2. Or rename existing remote: git remote rename %s origin", strings.Join(remotes, ", "), remotes[0])
 	}
 
+	if err := m.runHooks(HookPrePush, *instance); err != nil {
+		return err
+	}
+
 	if err := m.git.Push("origin", instance.Branch); err != nil {
 		return fmt.Errorf("failed to push branch %q to origin: %w\n\nTo fix:\n  1. Ensure you have push access to the repository\n  2. Check your authentication: git config --list | grep credential\n  3. Try manual push: git push origin %s", instance.Branch, err, instance.Branch)
 	}
 
+	_ = m.runHooks(HookPostPush, *instance)
+
 	return nil
 }
 
@@ -98,6 +106,14 @@ func (m *Manager) CreatePR(instanceID, title, body string) (string, error) {
 		return "", fmt.Errorf("instance not found: %s", instanceID)
 	}
 
+	if !state.CanTransition(instance.Status, state.StatusPROpen) || instance.Status == state.StatusPROpen {
+		return "", fmt.Errorf("cannot create a pull request for instance %s: status is %s", instanceID, instance.Status)
+	}
+
+	if err := m.runHooks(HookPreMerge, *instance); err != nil {
+		return "", err
+	}
+
 	// Detect which PR tool is available
 	tool, err := m.DetectPRTool()
 	if err != nil {
@@ -118,17 +134,114 @@ func (m *Manager) CreatePR(instanceID, title, body string) (string, error) {
 		return "", err
 	}
 
+	created := newEvent(state.EventPRCreated, *instance)
+	created.Details = map[string]string{"pr_url": prURL, "tool": tool}
+	m.recordEvent(created)
+
 	// Update instance with PR URL and status
-	if err := m.store.UpdateInstance(instanceID, func(inst *state.Instance) {
+	if _, err := m.transition(instanceID, state.StatusPROpen, "pull request created", func(inst *state.Instance) {
 		inst.PRUrl = prURL
-		inst.Status = "merged"
 	}); err != nil {
 		return prURL, fmt.Errorf("PR created at %s but failed to update state: %w", prURL, err)
 	}
 
+	instance.Status = state.StatusPROpen
+	instance.PRUrl = prURL
+	_ = m.runHooks(HookPostMerge, *instance)
+
 	return prURL, nil
 }
 
+// RefreshPRStatus asks gh or glab whether the pull request of a pr-open instance
+// was merged or closed, and moves the instance to merged or abandoned accordingly.
+// Instances in any other status are returned unchanged.
+func (m *Manager) RefreshPRStatus(instanceID string) (state.Status, error) {
+	instance, err := m.GetInstance(instanceID)
+	if err != nil {
+		return "", err
+	}
+
+	if instance.Status != state.StatusPROpen || instance.PRUrl == "" {
+		return instance.Status, nil
+	}
+
+	tool, err := m.DetectPRTool()
+	if err != nil {
+		return instance.Status, err
+	}
+
+	var prState string
+	switch tool {
+	case "gh":
+		prState, err = m.runPRStateQuery("gh", "pr", "view", instance.PRUrl, "--json", "state", "--jq", ".state")
+	case "glab":
+		prState, err = m.runPRStateQuery("glab", "mr", "view", instance.Branch, "--output", "json")
+		if err == nil {
+			prState = parseGitLabMRState(prState)
+		}
+	default:
+		return instance.Status, fmt.Errorf("unsupported PR tool: %s", tool)
+	}
+	if err != nil {
+		return instance.Status, err
+	}
+
+	var next state.Status
+	switch strings.ToLower(prState) {
+	case "merged":
+		next = state.StatusMerged
+	case "closed":
+		next = state.StatusAbandoned
+	default:
+		return instance.Status, nil
+	}
+
+	if _, err := m.transition(instanceID, next, fmt.Sprintf("pull request %s", strings.ToLower(prState)), nil); err != nil {
+		return instance.Status, fmt.Errorf("failed to update instance status: %w", err)
+	}
+
+	return next, nil
+}
+
+// renameRemoteBranch renames a branch on GitHub, which retargets the pull
+// requests whose head it is
+func (m *Manager) renameRemoteBranch(branch, newBranch string) error {
+	cmd := exec.Command("gh", "api", "--method", "POST",
+		"repos/{owner}/{repo}/branches/"+url.PathEscape(branch)+"/rename",
+		"-f", "new_name="+newBranch)
+	cmd.Dir = m.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to rename branch %s on GitHub: %w\nOutput: %s\n\nTo fix:\n  1. Check that you may rename branches of the repository: gh auth status\n  2. Or rename only the instance: ocw rename <id> <name>", branch, err, string(output))
+	}
+	return nil
+}
+
+// runPRStateQuery runs a gh or glab query in the repository root and returns its trimmed output.
+func (m *Manager) runPRStateQuery(tool string, args ...string) (string, error) {
+	cmd := exec.Command(tool, args...)
+	cmd.Dir = m.repoRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed: %w\nOutput: %s", tool, strings.Join(args[:2], " "), err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// parseGitLabMRState extracts the state field from glab's JSON merge request output.
+func parseGitLabMRState(output string) string {
+	var mr struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(output), &mr); err != nil {
+		return ""
+	}
+	return mr.State
+}
+
 // createGitHubPR creates a GitHub pull request using the gh CLI.
 func (m *Manager) createGitHubPR(branch, base, title, body string) (string, error) {
 	args := []string{
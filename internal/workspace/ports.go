@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// reservePorts allocates ports for inst and registers inst as a placeholder
+// in "creating" status holding them. Both happen in one transaction, so
+// concurrent creates are never handed the same ports even though the agents
+// bind them much later. The placeholder is replaced once the instance is set
+// up, or removed with releasePorts if creating it fails. Nothing is reserved
+// if port allocation is disabled.
+func (m *Manager) reservePorts(inst state.Instance) ([]int, error) {
+	if m.config.Ports.PerInstance <= 0 {
+		return nil, nil
+	}
+
+	var ports []int
+	err := m.store.Transact(func(st *state.State) error {
+		var err error
+		if ports, err = m.allocatePorts(st.Instances); err != nil {
+			return err
+		}
+		inst.Ports = ports
+		if inst.CreatedAt.IsZero() {
+			inst.CreatedAt = time.Now()
+		}
+		st.Instances = append(st.Instances, inst)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ports, nil
+}
+
+// releasePorts removes the placeholder registered by reservePorts, if any
+func (m *Manager) releasePorts(id string, ports []int) {
+	if len(ports) > 0 {
+		_ = m.store.RemoveInstance(id)
+	}
+}
+
+// allocatePorts picks ports.per_instance ports for a new instance from the
+// configured range. Ports held by the given instances of this repository or
+// by instances of any other registered repository and ports something
+// already listens on are skipped.
+func (m *Manager) allocatePorts(instances []state.Instance) ([]int, error) {
+	cfg := m.config.Ports
+	if cfg.PerInstance <= 0 {
+		return nil, nil
+	}
+
+	held := m.heldPorts(instances)
+
+	var ports []int
+	for port := cfg.Start; port <= cfg.End && len(ports) < cfg.PerInstance; port++ {
+		if !held[port] && portFree(port) {
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) < cfg.PerInstance {
+		return nil, fmt.Errorf("not enough free ports in %d-%d (%d needed per instance)\n\nTo fix:\n  1. Delete instances you no longer need: ocw delete <id>\n  2. Widen the range: ocw config set ports.end <port>\n  3. Or disable port allocation: ocw config set ports.per_instance 0", cfg.Start, cfg.End, cfg.PerInstance)
+	}
+	return ports, nil
+}
+
+// heldPorts returns the ports allocated to the given instances of this
+// repository and to the instances of the other registered ones.
+// Repositories whose state cannot be read are left out.
+func (m *Manager) heldPorts(instances []state.Instance) map[int]bool {
+	held := map[int]bool{}
+	hold := func(instances []state.Instance) {
+		for _, inst := range instances {
+			for _, port := range inst.Ports {
+				held[port] = true
+			}
+		}
+	}
+
+	hold(instances)
+	if m.registry != nil {
+		if entries, err := m.registry.List(); err == nil {
+			for _, entry := range entries {
+				if filepath.Clean(entry.Path) == filepath.Clean(m.repoRoot) {
+					continue
+				}
+				if st, err := state.NewStore(entry.Path).Load(); err == nil {
+					hold(st.Instances)
+				}
+			}
+		}
+	}
+	return held
+}
+
+// portFree reports whether nothing listens on a TCP port
+func portFree(port int) bool {
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+// instanceEnv describes an instance to the programs in its panes as OCW_*
+// variables, followed by the instance's own environment
+func instanceEnv(inst state.Instance) []string {
+	env := []string{
+		"OCW_INSTANCE_ID=" + inst.ID,
+		"OCW_BRANCH=" + inst.Branch,
+		"OCW_WORKTREE=" + inst.WorktreePath,
+	}
+	env = append(env, portVars(inst)...)
+	return append(env, envPairs(inst.Env)...)
+}
+
+// portVars returns OCW_PORT, the instance's first port, and OCW_PORTS, all of
+// its ports separated by spaces. Both are empty without allocated ports.
+func portVars(inst state.Instance) []string {
+	ports := make([]string, len(inst.Ports))
+	for i, port := range inst.Ports {
+		ports[i] = strconv.Itoa(port)
+	}
+	first := ""
+	if len(ports) > 0 {
+		first = ports[0]
+	}
+	return []string{"OCW_PORT=" + first, "OCW_PORTS=" + strings.Join(ports, " ")}
+}
@@ -0,0 +1,91 @@
+package workspace
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestAllocatePorts(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{store: state.NewStore(dir), config: config.DefaultConfig(), repoRoot: dir}
+
+	// A port something listens on
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer ln.Close()
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	// A port held by another instance
+	instances := []state.Instance{{ID: "a", Ports: []int{busy + 1}, Status: state.StatusRunning}}
+
+	m.config.Ports = config.PortsConfig{Start: busy, End: busy + 2, PerInstance: 1}
+	ports, err := m.allocatePorts(instances)
+	require.NoError(t, err)
+	assert.Equal(t, []int{busy + 2}, ports)
+
+	m.config.Ports.PerInstance = 2
+	_, err = m.allocatePorts(instances)
+	assert.ErrorContains(t, err, "not enough free ports")
+
+	m.config.Ports.PerInstance = 0
+	ports, err = m.allocatePorts(instances)
+	require.NoError(t, err)
+	assert.Nil(t, ports, "allocation is disabled")
+}
+
+func TestReservePortsHoldsPortsUntilReleased(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{store: state.NewStore(dir), config: config.DefaultConfig(), repoRoot: dir}
+
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	start := ln.Addr().(*net.TCPAddr).Port + 1
+	ln.Close()
+	m.config.Ports = config.PortsConfig{Start: start, End: start + 2, PerInstance: 1}
+
+	// Two creates reserve before either instance is set up
+	first, err := m.reservePorts(state.Instance{ID: "a", Status: state.StatusCreating})
+	require.NoError(t, err)
+	second, err := m.reservePorts(state.Instance{ID: "b", Status: state.StatusCreating})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	inst, err := m.GetInstance("a")
+	require.NoError(t, err)
+	assert.Equal(t, first, inst.Ports)
+	assert.True(t, reserving(*inst))
+
+	// A failed create gives its ports back
+	m.releasePorts("a", first)
+	_, err = m.GetInstance("a")
+	assert.Error(t, err)
+	third, err := m.reservePorts(state.Instance{ID: "c", Status: state.StatusCreating})
+	require.NoError(t, err)
+	assert.Equal(t, first, third)
+}
+
+func TestInstanceEnv(t *testing.T) {
+	inst := state.Instance{
+		ID:           "abc123",
+		Branch:       "feature/x",
+		WorktreePath: "/repo/.worktrees/feature-x",
+		Ports:        []int{3100, 3101},
+		Env:          map[string]string{"NODE_ENV": "development"},
+	}
+	assert.Equal(t, []string{
+		"OCW_INSTANCE_ID=abc123",
+		"OCW_BRANCH=feature/x",
+		"OCW_WORKTREE=/repo/.worktrees/feature-x",
+		"OCW_PORT=3100",
+		"OCW_PORTS=3100 3101",
+		"NODE_ENV=development",
+	}, instanceEnv(inst))
+
+	inst.Ports = nil
+	assert.Contains(t, instanceEnv(inst), "OCW_PORT=")
+}
@@ -1,8 +1,10 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/tommyzliu/ocw/internal/git"
 	"github.com/tommyzliu/ocw/internal/state"
@@ -14,6 +16,7 @@ type ReconcileResult struct {
 	PrunedWorktrees   bool
 	InstancesFixed    int
 	InstancesRemoved  int
+	ActivityUpdated   int // instances moved between running and idle by their agent's idle_pattern
 	OrphanedWorktrees []string
 	Errors            []error
 }
@@ -25,8 +28,8 @@ type ReconcileResult struct {
 // 1. Run git worktree repair (Metis guardrail)
 // 2. Run git worktree prune (Metis guardrail)
 // 3. Load state and git worktrees
-// 4. For each instance, check: worktree exists, tmux window exists, PID alive, pane status
-// 5. Reconcile discrepancies: remove invalid instances, mark failed ones as "error"
+// 4. For each instance, check: worktree exists, tmux window exists, PID alive, pane status, agent activity
+// 5. Reconcile discrepancies: remove invalid instances, mark failed ones as "error" or "exited", update running/idle
 // 6. Detect orphaned worktrees (exist but not in state)
 // 7. Update state with reconciled data
 func (m *Manager) Reconcile() (*ReconcileResult, error) {
@@ -90,31 +93,34 @@ func (m *Manager) Reconcile() (*ReconcileResult, error) {
 
 	// Step 4 & 5: Check each instance and reconcile discrepancies
 	instancesToRemove := make([]string, 0)
-	instancesToUpdate := make(map[string]func(*state.Instance))
+	instancesToUpdate := make(map[string]state.Status)
+	instancesByID := make(map[string]state.Instance)
+	removeReasons := make(map[string]string)
+	updateReasons := make(map[string]string)
 
 	for i := range currentState.Instances {
 		inst := &currentState.Instances[i]
+		instancesByID[inst.ID] = *inst
+
+		// A create in progress, possibly in another process, holds its
+		// ports before its worktree and window exist
+		if reserving(*inst) {
+			continue
+		}
 
 		// Check 1: Does worktree path exist?
 		_, worktreeExists := worktreeMap[inst.WorktreePath]
 		if !worktreeExists {
 			// Worktree missing but state exists → remove from state
 			instancesToRemove = append(instancesToRemove, inst.ID)
-			result.InstancesRemoved++
-
-			// Try to kill tmux window if it exists
-			if sessionExists && windowMap[inst.TmuxWindow] {
-				if err := m.tmux.KillWindow(inst.TmuxWindow); err != nil {
-					result.Errors = append(result.Errors, fmt.Errorf("failed to cleanup window for removed instance %s: %w", inst.ID, err))
-				}
-			}
+			removeReasons[inst.ID] = fmt.Sprintf("worktree %s no longer exists", inst.WorktreePath)
 			continue
 		}
 
 		// Check 2: Does tmux window exist?
 		windowExists := sessionExists && windowMap[inst.TmuxWindow]
 
-		// Check 3: Is opencode PID alive?
+		// Check 3: Is the agent's PID alive?
 		pidAlive := false
 		if inst.PID > 0 {
 			pidAlive = isProcessAlive(inst.PID)
@@ -122,6 +128,7 @@ func (m *Manager) Reconcile() (*ReconcileResult, error) {
 
 		// Check 4: Is pane dead? (require both window exists and session exists)
 		paneDead := false
+		paneExitStatus := 0
 		if windowExists {
 			panes, err := m.tmux.ListPanes(inst.TmuxWindow)
 			if err != nil {
@@ -131,6 +138,7 @@ func (m *Manager) Reconcile() (*ReconcileResult, error) {
 				for _, pane := range panes {
 					if pane.ID == inst.PrimaryPane {
 						paneDead = pane.Dead
+						paneExitStatus = pane.ExitStatus
 						break
 					}
 				}
@@ -138,44 +146,65 @@ func (m *Manager) Reconcile() (*ReconcileResult, error) {
 		}
 
 		// Reconcile based on findings
-		needsUpdate := false
 		newStatus := inst.Status
+		reason := ""
+		expectsProcess := inst.Status.Live() || inst.Status == state.StatusCreating
 
 		// Scenario 2: tmux crashed (session doesn't exist)
 		if !sessionExists {
-			if inst.Status == "running" || inst.Status == "paused" {
-				newStatus = "error"
-				needsUpdate = true
-				result.InstancesFixed++
+			if expectsProcess {
+				newStatus = state.StatusError
+				reason = fmt.Sprintf("tmux session %s not found", sessionName)
 			}
 		} else if !windowExists {
 			// Window missing but session exists
-			if inst.Status == "running" || inst.Status == "paused" {
-				newStatus = "error"
-				needsUpdate = true
-				result.InstancesFixed++
+			if expectsProcess {
+				newStatus = state.StatusError
+				reason = fmt.Sprintf("tmux window %s not found", inst.TmuxWindow)
 			}
 		}
 
-		// Scenario 3: Opencode crashed (PID dead but state shows running)
-		if inst.Status == "running" && !pidAlive {
-			newStatus = "error"
-			needsUpdate = true
-			result.InstancesFixed++
+		// A profile removed from the config since leaves the defaults
+		agent, _ := m.instanceAgent(*inst)
+		working := inst.Status == state.StatusRunning || inst.Status == state.StatusIdle
+
+		// Scenario 3: The agent crashed (PID dead but state shows running)
+		if (inst.Status == state.StatusRunning || inst.Status == state.StatusIdle) && !pidAlive {
+			newStatus = state.StatusError
+			reason = fmt.Sprintf("process %d is not running", inst.PID)
 		}
 
-		// Scenario 3b: Pane is dead (remain-on-exit captured it)
-		if paneDead && inst.Status == "running" {
-			newStatus = "error"
-			needsUpdate = true
-			result.InstancesFixed++
+		// Scenario 3b: Pane is dead (remain-on-exit captured it); the
+		// profile's on_exit tells a finished agent from a failed one
+		if paneDead && working {
+			newStatus, reason = exitStatus(agent, paneExitStatus)
+			reason = fmt.Sprintf("primary pane %s: %s", inst.PrimaryPane, reason)
+		}
+
+		// Scenario 4: The agent is alive; its idle_pattern tells whether it
+		// is working or waiting for input
+		activity := false
+		if newStatus == inst.Status && working && windowExists && !paneDead {
+			if content, err := m.tmux.CapturePaneText(inst.PrimaryPane); err == nil {
+				if status, ok := activityStatus(agent, content); ok && status != inst.Status {
+					newStatus = status
+					reason = "agent is working"
+					if status == state.StatusIdle {
+						reason = "agent is waiting for input"
+					}
+					activity = true
+				}
+			}
 		}
 
 		// Apply updates
-		if needsUpdate {
-			instID := inst.ID
-			instancesToUpdate[instID] = func(i *state.Instance) {
-				i.Status = newStatus
+		if newStatus != inst.Status {
+			updateReasons[inst.ID] = reason
+			instancesToUpdate[inst.ID] = newStatus
+			if activity {
+				result.ActivityUpdated++
+			} else {
+				result.InstancesFixed++
 			}
 		}
 	}
@@ -203,45 +232,114 @@ func (m *Manager) Reconcile() (*ReconcileResult, error) {
 		result.OrphanedWorktrees = append(result.OrphanedWorktrees, wt.Path)
 	}
 
-	// Step 7: Apply state updates
-	// First remove instances
+	// Step 7: Apply state updates in a single transaction.
+	// Instances that changed status or worktree since the snapshot above were
+	// touched by another writer in the meantime and are left alone.
+	removeSet := make(map[string]bool)
 	for _, id := range instancesToRemove {
-		if err := m.store.RemoveInstance(id); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to remove instance %s: %w", id, err))
+		removeSet[id] = true
+	}
+
+	var removed []state.Instance
+	var updated []state.Instance
+	err = m.store.Transact(func(st *state.State) error {
+		removed = nil
+		updated = nil
+
+		kept := make([]state.Instance, 0, len(st.Instances))
+		for _, inst := range st.Instances {
+			before := instancesByID[inst.ID]
+			if removeSet[inst.ID] && inst.WorktreePath == before.WorktreePath && inst.CreatedAt.Equal(before.CreatedAt) {
+				removed = append(removed, inst)
+				continue
+			}
+
+			if newStatus, ok := instancesToUpdate[inst.ID]; ok && inst.Status == before.Status {
+				if err := inst.Transition(newStatus, "reconcile: "+updateReasons[inst.ID]); err == nil {
+					updated = append(updated, inst)
+				}
+			}
+			kept = append(kept, inst)
 		}
+
+		st.Instances = kept
+		return nil
+	})
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("failed to apply reconciliation: %w", err))
+		return result, nil
 	}
 
-	// Then update remaining instances
-	for id, updateFn := range instancesToUpdate {
-		if err := m.store.UpdateInstance(id, updateFn); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to update instance %s: %w", id, err))
+	result.InstancesRemoved = len(removed)
+	for _, inst := range removed {
+		// Try to kill tmux window if it exists
+		if sessionExists && windowMap[inst.TmuxWindow] {
+			if err := m.tmux.KillWindow(inst.TmuxWindow); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("failed to cleanup window for removed instance %s: %w", inst.ID, err))
+			}
 		}
+
+		ev := newEvent(state.EventRemovedByReconcile, inst)
+		ev.From = string(inst.Status)
+		ev.Reason = removeReasons[inst.ID]
+		m.recordEvent(ev)
+	}
+	for _, inst := range updated {
+		before := instancesByID[inst.ID]
+		m.recordStatusChange(inst, before.Status, inst.Status, "reconcile: "+updateReasons[inst.ID])
 	}
 
 	return result, nil
 }
 
+// reservationTimeout is how long a placeholder registered by reservePorts may
+// wait for its window before reconcile treats the create as abandoned
+const reservationTimeout = 10 * time.Minute
+
+// reserving reports whether inst is a placeholder of a create in progress
+func reserving(inst state.Instance) bool {
+	return inst.Status == state.StatusCreating && inst.TmuxWindow == "" && time.Since(inst.CreatedAt) < reservationTimeout
+}
+
 // RecoverFromCrash attempts to recover after a complete tmux crash.
 // This handles Scenario 2: All tmux sessions lost, processes may be reparented to PID 1.
 //
+// If state.json itself is corrupt, the newest valid snapshot is passed to offer and
+// restored if offer returns true. A nil offer never restores.
+//
 // Returns true if recovery was successful, false otherwise.
-func (m *Manager) RecoverFromCrash() (bool, error) {
+func (m *Manager) RecoverFromCrash(offer func(state.Snapshot) bool) (bool, error) {
+	restored := false
+
 	// Load state
 	currentState, err := m.store.Load()
 	if err != nil {
-		return false, fmt.Errorf("failed to load state: %w", err)
+		var versionErr *state.SchemaVersionError
+		if errors.As(err, &versionErr) {
+			return false, err
+		}
+
+		if err := m.restoreLatestSnapshot(offer, err); err != nil {
+			return false, err
+		}
+		restored = true
+
+		currentState, err = m.store.Load()
+		if err != nil {
+			return false, fmt.Errorf("failed to load restored state: %w", err)
+		}
 	}
 
 	// Check if we have any instances
 	if len(currentState.Instances) == 0 {
-		return false, nil
+		return restored, nil
 	}
 
 	// Check if tmux session exists
 	sessionName := m.SessionName()
 	if m.tmux.HasSession(sessionName) {
 		// Session exists, no crash recovery needed
-		return false, nil
+		return restored, nil
 	}
 
 	// Try to recreate session
@@ -250,23 +348,67 @@ func (m *Manager) RecoverFromCrash() (bool, error) {
 		return false, fmt.Errorf("failed to recreate session: %w", err)
 	}
 
-	// Mark all instances as error (they need manual intervention)
-	for _, inst := range currentState.Instances {
-		if err := m.store.UpdateInstance(inst.ID, func(i *state.Instance) {
-			i.Status = "error"
-		}); err != nil {
-			return false, fmt.Errorf("failed to mark instance %s as error: %w", inst.ID, err)
+	// Mark all instances that had a process as error (they need manual intervention)
+	const reason = "crash recovery: tmux session was lost"
+	var marked []state.Instance
+	err = m.store.Transact(func(st *state.State) error {
+		marked = nil
+		for i := range st.Instances {
+			inst := &st.Instances[i]
+			if !inst.Status.Live() && inst.Status != state.StatusCreating {
+				continue
+			}
+			before := *inst
+			if err := inst.Transition(state.StatusError, reason); err == nil {
+				marked = append(marked, before)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark instances as error: %w", err)
+	}
+
+	for _, inst := range marked {
+		m.recordStatusChange(inst, inst.Status, state.StatusError, reason)
 	}
 
 	return true, nil
 }
 
+// restoreLatestSnapshot rolls state.json back to the newest valid snapshot if offer accepts it.
+// loadErr is the error that made the current state unreadable.
+func (m *Manager) restoreLatestSnapshot(offer func(state.Snapshot) bool, loadErr error) error {
+	snap, err := m.store.LatestValidSnapshot()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w (and failed to list snapshots: %v)", loadErr, err)
+	}
+	if snap == nil {
+		return fmt.Errorf("failed to load state: %w\n\nNo valid snapshot is available in .ocw/snapshots/.", loadErr)
+	}
+
+	if offer == nil || !offer(*snap) {
+		return fmt.Errorf("failed to load state: %w\n\nTo fix:\n  1. Inspect the newest snapshot: ocw state diff %s\n  2. Restore it: ocw state rollback %s", loadErr, snap.Name, snap.Name)
+	}
+
+	if _, err := m.store.Rollback(snap.Name); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", snap.Name, err)
+	}
+
+	return nil
+}
+
 // ValidateState checks if the state file is valid and not corrupted.
 // Returns true if state is valid, false if corrupted.
 func (m *Manager) ValidateState() (bool, error) {
 	_, err := m.store.Load()
 	if err != nil {
+		// State written by a newer ocw is intact, just unreadable by this binary
+		var versionErr *state.SchemaVersionError
+		if errors.As(err, &versionErr) {
+			return true, err
+		}
+
 		// Check if it's a JSON parse error (corruption)
 		if _, ok := err.(*os.PathError); !ok {
 			// Likely a JSON unmarshaling error = corruption
@@ -0,0 +1,21 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkFile clones src to dst copy-on-write with clonefile(2), which APFS
+// supports
+func reflinkFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	// clonefile refuses to replace an existing file
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
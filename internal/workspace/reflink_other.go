@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package workspace
+
+import "errors"
+
+// reflinkFile is not supported on this platform; files are copied instead
+func reflinkFile(src, dst string) error {
+	return errors.New("reflinks are not supported on this platform")
+}
@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/tommyzliu/ocw/internal/registry"
+	"github.com/tommyzliu/ocw/internal/state"
+	"github.com/tommyzliu/ocw/internal/tmux"
+)
+
+// RepoStatus is a registered repository together with its instances.
+type RepoStatus struct {
+	Repo           registry.Entry
+	Instances      []state.Instance
+	SessionRunning bool
+	Err            error // set if the repository's state could not be loaded
+}
+
+// ListRepos prunes repositories that no longer exist from the registry and
+// returns the remaining ones with their instances and tmux session status.
+func ListRepos(reg *registry.Registry) ([]RepoStatus, error) {
+	if _, err := reg.Prune(); err != nil {
+		return nil, fmt.Errorf("failed to prune registry: %w", err)
+	}
+
+	entries, err := reg.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	tmuxClient := tmux.NewTmux()
+	tmuxInstalled := tmuxClient.IsInstalled()
+
+	repos := make([]RepoStatus, 0, len(entries))
+	for _, entry := range entries {
+		repo := RepoStatus{Repo: entry}
+
+		st, err := state.NewStore(entry.Path).Load()
+		if err != nil {
+			repo.Err = err
+		} else {
+			repo.Instances = st.Instances
+		}
+
+		if tmuxInstalled && entry.Session != "" {
+			repo.SessionRunning = tmuxClient.HasSession(entry.Session)
+		}
+
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
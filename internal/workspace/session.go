@@ -4,34 +4,55 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/registry"
 )
 
 // SessionName returns the tmux session name for this repository.
 // Format: <prefix>-<reponame>
 // Example: "ocw-myproject"
 func (m *Manager) SessionName() string {
-	repoName := extractRepoName(m.repoRoot)
-	return fmt.Sprintf("%s-%s", m.config.Tmux.SessionPrefix, repoName)
+	return sessionName(m.repoRoot, m.config)
+}
+
+// sessionName returns the tmux session name of the repository at repoRoot
+func sessionName(repoRoot string, cfg *config.Config) string {
+	repoName := extractRepoName(repoRoot)
+	return fmt.Sprintf("%s-%s", cfg.Tmux.SessionPrefix, repoName)
 }
 
-// EnsureSession creates the OCW tmux session if it doesn't already exist.
+// EnsureSession creates the OCW tmux session if it doesn't already exist
+// and records the repository in the user-level registry.
 // Returns the session name.
 func (m *Manager) EnsureSession() (string, error) {
 	sessionName := m.SessionName()
 
 	// Check if session already exists
-	if m.tmux.HasSession(sessionName) {
-		return sessionName, nil
+	if !m.tmux.HasSession(sessionName) {
+		// Create new session in the repository root
+		if err := m.tmux.NewSession(sessionName, m.repoRoot); err != nil {
+			return "", fmt.Errorf("failed to create tmux session %q: %w", sessionName, err)
+		}
 	}
 
-	// Create new session in the repository root
-	if err := m.tmux.NewSession(sessionName, m.repoRoot); err != nil {
-		return "", fmt.Errorf("failed to create tmux session %q: %w", sessionName, err)
+	// Registration is best-effort and must never block working in this repo
+	if m.registry != nil {
+		_ = m.registry.Register(m.repoRoot, extractRepoName(m.repoRoot), sessionName)
 	}
 
 	return sessionName, nil
 }
 
+// RegisterRepo records the repository at repoRoot in the user-level registry.
+func RegisterRepo(repoRoot string, cfg *config.Config) error {
+	reg, err := registry.Default()
+	if err != nil {
+		return err
+	}
+	return reg.Register(repoRoot, extractRepoName(repoRoot), sessionName(repoRoot, cfg))
+}
+
 // SessionExists checks if the OCW tmux session exists.
 func (m *Manager) SessionExists() bool {
 	return m.tmux.HasSession(m.SessionName())
@@ -53,7 +53,7 @@ func (m *Manager) CreateSubTerminal(instanceID, label string) (string, error) {
 	target := inst.TmuxWindow
 
 	// Split the window to create new pane
-	newPaneID, err := m.tmux.SplitWindow(target, inst.WorktreePath, split, percentage)
+	newPaneID, err := m.tmux.SplitWindow(target, inst.WorktreePath, split, percentage, instanceEnv(*inst)...)
 	if err != nil {
 		return "", fmt.Errorf("failed to split window: %w", err)
 	}
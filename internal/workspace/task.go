@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+// maxPRTitleLength keeps titles derived from a task readable in PR lists
+const maxPRTitleLength = 72
+
+// SetTask replaces the task description of an instance.
+func (m *Manager) SetTask(id, task string) (*state.Instance, error) {
+	task = strings.TrimSpace(task)
+
+	var instance state.Instance
+	var changed bool
+	err := m.store.Transact(func(st *state.State) error {
+		for i := range st.Instances {
+			if st.Instances[i].ID == id {
+				changed = st.Instances[i].Task != task
+				st.Instances[i].Task = task
+				instance = st.Instances[i]
+				return nil
+			}
+		}
+		return fmt.Errorf("instance %q not found", id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		ev := newEvent(state.EventTaskChanged, instance)
+		ev.To = TaskSummary(task)
+		m.recordEvent(ev)
+	}
+
+	return &instance, nil
+}
+
+// AddNote appends a note to the notes log of an instance.
+func (m *Manager) AddNote(id, text string) (*state.Instance, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("note cannot be empty")
+	}
+
+	var instance state.Instance
+	err := m.store.Transact(func(st *state.State) error {
+		for i := range st.Instances {
+			if st.Instances[i].ID == id {
+				st.Instances[i].Notes = append(st.Instances[i].Notes, state.Note{Time: time.Now(), Text: text})
+				instance = st.Instances[i]
+				return nil
+			}
+		}
+		return fmt.Errorf("instance %q not found", id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ev := newEvent(state.EventNoteAdded, instance)
+	ev.Details = map[string]string{"note": text}
+	m.recordEvent(ev)
+
+	return &instance, nil
+}
+
+// TaskSummary returns the first non-empty line of a task, without markdown heading marks.
+func TaskSummary(task string) string {
+	for _, line := range strings.Split(task, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// PRTitleFromTask derives a pull request title from the instance's task.
+// Returns "" if the instance has no task.
+func PRTitleFromTask(inst state.Instance) string {
+	title := TaskSummary(inst.Task)
+	if runes := []rune(title); len(runes) > maxPRTitleLength {
+		title = strings.TrimSpace(string(runes[:maxPRTitleLength-3])) + "..."
+	}
+	return title
+}
+
+// PRBodyFromTask renders the instance's task and notes as a pull request description.
+// Returns "" if the instance has neither.
+func PRBodyFromTask(inst state.Instance) string {
+	task := strings.TrimSpace(inst.Task)
+	if task == "" && len(inst.Notes) == 0 {
+		return ""
+	}
+
+	var body strings.Builder
+	if task != "" {
+		body.WriteString("## Task\n\n")
+		body.WriteString(task)
+		body.WriteString("\n")
+	}
+
+	if len(inst.Notes) > 0 {
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString("## Notes\n\n")
+		for _, note := range inst.Notes {
+			body.WriteString(fmt.Sprintf("- %s\n", strings.ReplaceAll(note.Text, "\n", "\n  ")))
+		}
+	}
+
+	return body.String()
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+	"github.com/tommyzliu/ocw/internal/state"
+)
+
+func TestSetTaskAndAddNote(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{store: state.NewStore(dir), config: config.DefaultConfig(), repoRoot: dir}
+	require.NoError(t, m.store.AddInstance(state.Instance{ID: "inst1", Name: "feature"}))
+
+	inst, err := m.SetTask("inst1", "  Add rate limiting\n\nUse the middleware package.\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Add rate limiting\n\nUse the middleware package.", inst.Task)
+
+	_, err = m.AddNote("inst1", "first approach was too slow")
+	require.NoError(t, err)
+	inst, err = m.AddNote("inst1", "switched to a token bucket")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(inst.Notes))
+	assert.Equal(t, "switched to a token bucket", inst.Notes[1].Text)
+	assert.False(t, inst.Notes[1].Time.IsZero())
+
+	_, err = m.AddNote("inst1", "   ")
+	assert.Error(t, err)
+	_, err = m.AddNote("missing", "note")
+	assert.Error(t, err)
+
+	loaded, err := m.GetInstance("inst1")
+	require.NoError(t, err)
+	assert.Equal(t, inst.Task, loaded.Task)
+	assert.Equal(t, 2, len(loaded.Notes))
+
+	events, err := m.History(state.EventFilter{Types: []state.EventType{state.EventTaskChanged, state.EventNoteAdded}})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, "Add rate limiting", events[0].To)
+}
+
+func TestTaskSummary(t *testing.T) {
+	assert.Equal(t, "Add rate limiting", TaskSummary("\n\n## Add rate limiting\nDetails"))
+	assert.Equal(t, "", TaskSummary("  \n "))
+}
+
+func TestPRFromTask(t *testing.T) {
+	inst := state.Instance{
+		Task: "# Add rate limiting\n\nUse the middleware package.",
+		Notes: []state.Note{
+			{Time: time.Now(), Text: "token bucket"},
+			{Time: time.Now(), Text: "two\nlines"},
+		},
+	}
+
+	assert.Equal(t, "Add rate limiting", PRTitleFromTask(inst))
+	assert.Equal(t, "## Task\n\n# Add rate limiting\n\nUse the middleware package.\n\n## Notes\n\n- token bucket\n- two\n  lines\n", PRBodyFromTask(inst))
+
+	long := state.Instance{Task: strings.Repeat("word ", 30)}
+	assert.Equal(t, maxPRTitleLength, len(PRTitleFromTask(long)))
+	assert.True(t, strings.HasSuffix(PRTitleFromTask(long), "..."))
+
+	// Truncated by characters, not bytes
+	accented := state.Instance{Task: strings.Repeat("é", 40) + strings.Repeat("日本", 20)}
+	title := PRTitleFromTask(accented)
+	assert.True(t, utf8.ValidString(title))
+	assert.Equal(t, maxPRTitleLength, utf8.RuneCountInString(title))
+	assert.Equal(t, strings.Repeat("é", 40)+strings.Repeat("日本", 14)+"日...", title)
+
+	notesOnly := state.Instance{Notes: []state.Note{{Text: "only a note"}}}
+	assert.Equal(t, "", PRTitleFromTask(notesOnly))
+	assert.Equal(t, "## Notes\n\n- only a note\n", PRBodyFromTask(notesOnly))
+
+	assert.Equal(t, "", PRBodyFromTask(state.Instance{}))
+}
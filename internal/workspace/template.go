@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tommyzliu/ocw/internal/config"
+)
+
+// applyTemplate fills the options left empty by the caller from a template.
+// The template's variables are resolved from opts.Vars, with the branch given
+// by the caller as the built-in {name}, and expand its branch and name patterns.
+func applyTemplate(opts CreateOpts, tmpl config.Template) (CreateOpts, error) {
+	given := make(map[string]string, len(opts.Vars)+1)
+	for name, value := range opts.Vars {
+		given[name] = value
+	}
+	if opts.Branch != "" && given[config.NameVar] == "" {
+		given[config.NameVar] = opts.Branch
+	}
+	vars, err := tmpl.ResolveVars(given)
+	if err != nil {
+		return opts, fmt.Errorf("%w\n\nTo fix:\n  1. Pass variables with --var name=value\n  2. Check the template's [[vars]] in the config: ocw config show", err)
+	}
+	opts.Vars = vars
+
+	if opts.Name == "" {
+		opts.Name = vars[config.NameVar]
+		if tmpl.NamePattern != "" {
+			opts.Name = config.Expand(tmpl.NamePattern, vars)
+		}
+	}
+	if tmpl.BranchPattern != "" {
+		opts.Branch = config.Expand(tmpl.BranchPattern, vars)
+	}
+	if opts.BaseBranch == "" {
+		opts.BaseBranch = tmpl.BaseBranch
+	}
+	if opts.InitCommand == "" {
+		opts.InitCommand = tmpl.InitCommand
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = config.Expand(tmpl.Prompt, vars)
+	}
+	opts.Tags = append(append([]string{}, tmpl.Tags...), opts.Tags...)
+
+	env := make(map[string]string, len(tmpl.Env)+len(opts.Env))
+	for key, value := range tmpl.Env {
+		env[key] = value
+	}
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+	if len(env) > 0 {
+		opts.Env = env
+	}
+
+	return opts, nil
+}
+
+// envPairs renders an environment map as sorted VAR=value pairs
+func envPairs(env map[string]string) []string {
+	pairs := make([]string, 0, len(env))
+	for key, value := range env {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// copyTemplateFiles copies files and directories matching patterns from the
+// main checkout into a new worktree, keeping their paths relative to the
+// repository root. Patterns that match nothing are skipped, and so are files
+// already in the worktree if keepExisting is set.
+func copyTemplateFiles(repoRoot, worktreePath string, patterns []string, keepExisting bool) ([]string, error) {
+	var copied []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(repoRoot, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+
+		for _, src := range matches {
+			rel, err := filepath.Rel(repoRoot, src)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				return copied, fmt.Errorf("file %q is outside the repository", src)
+			}
+			if err := copyPath(src, filepath.Join(worktreePath, rel), keepExisting); err != nil {
+				return copied, fmt.Errorf("failed to copy %s: %w", rel, err)
+			}
+			copied = append(copied, rel)
+		}
+	}
+	return copied, nil
+}
+
+// copyPath copies a file, or a directory recursively, to dst. With
+// keepExisting, files that already exist at the destination are left alone.
+func copyPath(src, dst string, keepExisting bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if keepExisting {
+			if _, err := os.Lstat(target); err == nil {
+				return nil
+			}
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,111 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tommyzliu/ocw/internal/config"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	tmpl := config.Template{
+		BaseBranch:    "production",
+		InitCommand:   "make deps",
+		BranchPattern: "hotfix/{name}",
+		Env:           map[string]string{"STAGE": "hotfix", "DEBUG": "0"},
+		Tags:          []string{"hotfix"},
+	}
+
+	opts, err := applyTemplate(CreateOpts{
+		Branch: "login-timeout",
+		Tags:   []string{"urgent"},
+		Env:    map[string]string{"DEBUG": "1"},
+	}, tmpl)
+	require.NoError(t, err)
+
+	assert.Equal(t, "login-timeout", opts.Name)
+	assert.Equal(t, "hotfix/login-timeout", opts.Branch)
+	assert.Equal(t, "production", opts.BaseBranch)
+	assert.Equal(t, "make deps", opts.InitCommand)
+	assert.Equal(t, []string{"hotfix", "urgent"}, opts.Tags)
+	assert.Equal(t, map[string]string{"STAGE": "hotfix", "DEBUG": "1"}, opts.Env)
+
+	// Options given by the caller win over the template
+	opts, err = applyTemplate(CreateOpts{Name: "fix", Branch: "x", BaseBranch: "main", InitCommand: "true"}, tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "fix", opts.Name)
+	assert.Equal(t, "main", opts.BaseBranch)
+	assert.Equal(t, "true", opts.InitCommand)
+
+	// Without a pattern the branch is used as given
+	opts, err = applyTemplate(CreateOpts{Branch: "feature/x"}, config.Template{})
+	require.NoError(t, err)
+	assert.Equal(t, "feature/x", opts.Branch)
+	assert.Nil(t, opts.Env)
+
+	// A prompt given by the caller replaces the template's
+	opts, err = applyTemplate(CreateOpts{Branch: "x", Vars: map[string]string{"ticket": "OCW-1"}}, config.Template{Prompt: "Fix {ticket}", Vars: []config.Var{{Name: "ticket"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "Fix OCW-1", opts.Prompt)
+	opts, err = applyTemplate(CreateOpts{Branch: "x", Prompt: "Write tests"}, config.Template{Prompt: "Fix it"})
+	require.NoError(t, err)
+	assert.Equal(t, "Write tests", opts.Prompt)
+}
+
+func TestApplyTemplateVars(t *testing.T) {
+	tmpl := config.Template{
+		BranchPattern: "feature/{ticket}-{title|slug}",
+		NamePattern:   "{ticket}",
+		Vars: []config.Var{
+			{Name: "ticket", Required: true},
+			{Name: "title", Required: true},
+			{Name: "owner", Default: "platform"},
+		},
+	}
+
+	opts, err := applyTemplate(CreateOpts{Vars: map[string]string{"ticket": "OCW-123", "title": "Fix login"}}, tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/OCW-123-fix-login", opts.Branch)
+	assert.Equal(t, "OCW-123", opts.Name)
+	assert.Equal(t, map[string]string{"ticket": "OCW-123", "title": "Fix login", "owner": "platform"}, opts.Vars)
+
+	_, err = applyTemplate(CreateOpts{Vars: map[string]string{"ticket": "OCW-123"}}, tmpl)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing required variable(s): title")
+}
+
+func TestEnvPairs(t *testing.T) {
+	assert.Equal(t, []string{"A=1", "B=two words"}, envPairs(map[string]string{"B": "two words", "A": "1"}))
+	assert.Empty(t, envPairs(nil))
+}
+
+func TestCopyTemplateFiles(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".env"), []byte("SECRET=1\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "certs", "dev"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "certs", "dev", "cert.pem"), []byte("cert"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "config.local.yml"), []byte("a: 1"), 0644))
+
+	copied, err := copyTemplateFiles(repo, worktree, []string{".env", "certs", "*.local.yml", "missing.txt"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env", "certs", "config.local.yml"}, copied)
+
+	data, err := os.ReadFile(filepath.Join(worktree, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET=1\n", string(data))
+
+	info, err := os.Stat(filepath.Join(worktree, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = os.Stat(filepath.Join(worktree, "certs", "dev", "cert.pem"))
+	assert.NoError(t, err)
+
+	_, err = copyTemplateFiles(repo, worktree, []string{"../outside"}, false)
+	assert.NoError(t, err, "patterns matching nothing are skipped")
+}